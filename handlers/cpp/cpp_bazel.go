@@ -0,0 +1,373 @@
+package cpp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ort-recovery/utils"
+)
+
+// starlarkCall is one `name(...)` invocation found in a Bazel file, with
+// its keyword arguments decoded: Args holds scalar string values (e.g.
+// name = "zlib"), ListArgs holds string-list values (e.g. urls = [...]).
+// Positional arguments aren't needed by anything ParseBazelModule or
+// ParseBazelWorkspace look for, so they're dropped.
+type starlarkCall struct {
+	Func     string
+	Args     map[string]string
+	ListArgs map[string][]string
+}
+
+var starlarkCallNameRe = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+var starlarkQuotedStringRe = regexp.MustCompile(`"([^"\\]*(?:\\.[^"\\]*)*)"|'([^'\\]*(?:\\.[^'\\]*)*)'`)
+
+// extractStarlarkCalls scans text for invocations of any function in
+// funcNames and parses each one's argument list: balanced parens/brackets
+// and quoted strings are tracked so a comma inside a urls = [...] list or
+// a quoted path doesn't get mistaken for an argument separator.
+func extractStarlarkCalls(text string, funcNames map[string]bool) []starlarkCall {
+	var calls []starlarkCall
+	for _, m := range starlarkCallNameRe.FindAllStringSubmatchIndex(text, -1) {
+		fn := text[m[2]:m[3]]
+		if !funcNames[fn] {
+			continue
+		}
+		openIdx := m[1] - 1 // index of the '(' the regex matched up to
+		closeIdx := matchBalanced(text, openIdx)
+		if closeIdx < 0 {
+			continue
+		}
+		call := starlarkCall{Func: fn, Args: map[string]string{}, ListArgs: map[string][]string{}}
+		for _, arg := range splitTopLevel(text[openIdx+1 : closeIdx]) {
+			parseStarlarkArg(arg, &call)
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// matchBalanced returns the index of the closing bracket matching the
+// open bracket at openIdx, tracking nested (), [], {} and skipping over
+// quoted string contents so a bracket character inside a string literal
+// doesn't throw off the depth count.
+func matchBalanced(text string, openIdx int) int {
+	depth := 0
+	inString := false
+	var quote byte
+	for i := openIdx; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			if c == '\\' {
+				i++
+				continue
+			}
+			if c == quote {
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			inString = true
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits s on commas that aren't nested inside brackets,
+// parens, or a quoted string.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth := 0
+	inString := false
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			if c == '\\' {
+				i++
+				continue
+			}
+			if c == quote {
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			inString = true
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if strings.TrimSpace(s[start:]) != "" {
+		parts = append(parts, s[start:])
+	}
+	return parts
+}
+
+// parseStarlarkArg decodes one `key = value` keyword argument into call,
+// as either a scalar string (Args) or a string list (ListArgs). Positional
+// arguments (no "=") are ignored.
+func parseStarlarkArg(arg string, call *starlarkCall) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		return
+	}
+	eq := strings.Index(arg, "=")
+	if eq < 0 {
+		return
+	}
+	key := strings.TrimSpace(arg[:eq])
+	val := strings.TrimSpace(arg[eq+1:])
+	if strings.HasPrefix(val, "[") {
+		call.ListArgs[key] = extractQuotedStrings(val)
+		return
+	}
+	if s, ok := unquoteStarlark(val); ok {
+		call.Args[key] = s
+	}
+}
+
+// extractQuotedStrings returns the contents of every quoted string literal
+// in s, in order. It uses submatch indices (not FindAllStringSubmatch)
+// because Go's regexp can't otherwise distinguish "group didn't
+// participate" from "group matched an empty string" — both surface as "".
+func extractQuotedStrings(s string) []string {
+	var out []string
+	for _, idx := range starlarkQuotedStringRe.FindAllStringSubmatchIndex(s, -1) {
+		switch {
+		case idx[2] >= 0:
+			out = append(out, s[idx[2]:idx[3]])
+		case idx[4] >= 0:
+			out = append(out, s[idx[4]:idx[5]])
+		}
+	}
+	return out
+}
+
+func unquoteStarlark(s string) (string, bool) {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], true
+	}
+	return "", false
+}
+
+// urlVersionRe pulls a semver-ish version out of a release URL, e.g.
+// ".../v1.14.0.tar.gz" or ".../releases/download/1.3.2/foo.tar.gz".
+var urlVersionRe = regexp.MustCompile(`[/_-][vV]?(\d+\.\d+(?:\.\d+)?)(?:[./]|$)`)
+
+func versionFromURL(url string) string {
+	if m := urlVersionRe.FindStringSubmatch(url); len(m) == 2 {
+		return m[1]
+	}
+	return ""
+}
+
+// trailingVersionRe pulls a trailing semver-ish version out of a
+// strip_prefix like "grpc-1.54.0".
+var trailingVersionRe = regexp.MustCompile(`(\d+\.\d+(?:\.\d+)?)$`)
+
+func versionFromStripPrefix(prefix string) string {
+	if m := trailingVersionRe.FindStringSubmatch(prefix); len(m) == 2 {
+		return m[1]
+	}
+	return ""
+}
+
+// ParseBazelModule reads MODULE.bazel (Bzlmod) and recovers every
+// bazel_dep(name=..., version=...), applying single_version_override,
+// archive_override (version recovered from its urls), and git_override
+// (version falls back to the pinned commit) on top.
+func ParseBazelModule(projectDir string) ([]utils.Dependency, error) {
+	path := filepath.Join(projectDir, "MODULE.bazel")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []utils.Dependency{}, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	calls := extractStarlarkCalls(string(data), map[string]bool{
+		"bazel_dep":               true,
+		"single_version_override": true,
+		"archive_override":        true,
+		"git_override":            true,
+	})
+
+	deps := make(map[string]utils.Dependency)
+	var order []string
+	get := func(name string) utils.Dependency {
+		if dep, ok := deps[name]; ok {
+			return dep
+		}
+		order = append(order, name)
+		return utils.Dependency{GroupID: "bazel", ArtifactID: name, Scope: "compile", Key: name, Origin: "declared"}
+	}
+
+	// github tracks, per module name, the GitHub owner/repo an
+	// archive_override's urls resolved to, so the final PURL can be
+	// pkg:github/<owner>/<repo>@<tag> instead of the generic pkg:bazel form.
+	github := make(map[string][2]string)
+
+	for _, call := range calls {
+		if call.Func != "bazel_dep" {
+			continue
+		}
+		name := call.Args["name"]
+		if name == "" {
+			continue
+		}
+		dep := get(name)
+		dep.Version = call.Args["version"]
+		deps[name] = dep
+	}
+
+	for _, call := range calls {
+		name := call.Args["module_name"]
+		if name == "" {
+			continue
+		}
+		dep := get(name)
+		switch call.Func {
+		case "single_version_override":
+			if v := call.Args["version"]; v != "" {
+				dep.Version = v
+			}
+		case "archive_override":
+			for _, u := range call.ListArgs["urls"] {
+				if owner, repo := githubOwnerRepo(u); owner != "" {
+					github[name] = [2]string{owner, repo}
+				}
+				if v := versionFromURL(u); v != "" {
+					dep.Version = v
+					break
+				}
+			}
+		case "git_override":
+			if dep.Version == "" {
+				if commit := call.Args["commit"]; commit != "" {
+					dep.Version = commit
+				}
+			}
+		}
+		deps[name] = dep
+	}
+
+	result := make([]utils.Dependency, 0, len(order))
+	for _, name := range order {
+		dep := deps[name]
+		extras := map[string]string{}
+		if gh, ok := github[name]; ok {
+			extras["github_owner"], extras["github_repo"], extras["github_tag"] = gh[0], gh[1], dep.Version
+		}
+		dep.PURL = BuildCppPURL("bazel", name, dep.Version, extras)
+		dep.SPDXID = BuildCppSPDXID(dep.PURL)
+		result = append(result, dep)
+	}
+	return result, nil
+}
+
+// ParseBazelWorkspace reads the legacy WORKSPACE/WORKSPACE.bazel and
+// recovers http_archive (version from urls, falling back to
+// strip_prefix), git_repository (version from tag, falling back to
+// commit), and new_local_repository (recorded with no version — a local
+// path has none to recover) declarations.
+func ParseBazelWorkspace(projectDir string) ([]utils.Dependency, error) {
+	var text strings.Builder
+	for _, name := range []string{"WORKSPACE", "WORKSPACE.bazel"} {
+		data, err := os.ReadFile(filepath.Join(projectDir, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %v", name, err)
+		}
+		text.Write(data)
+		text.WriteByte('\n')
+	}
+	if text.Len() == 0 {
+		return []utils.Dependency{}, nil
+	}
+
+	calls := extractStarlarkCalls(text.String(), map[string]bool{
+		"http_archive":         true,
+		"git_repository":       true,
+		"new_local_repository": true,
+	})
+
+	deps := make([]utils.Dependency, 0, len(calls))
+	seen := make(map[string]struct{})
+	for _, call := range calls {
+		name := call.Args["name"]
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+
+		version := ""
+		githubOwner, githubRepo := "", ""
+		switch call.Func {
+		case "http_archive":
+			for _, u := range call.ListArgs["urls"] {
+				if owner, repo := githubOwnerRepo(u); owner != "" {
+					githubOwner, githubRepo = owner, repo
+				}
+				if v := versionFromURL(u); v != "" {
+					version = v
+					break
+				}
+			}
+			if version == "" {
+				version = versionFromStripPrefix(call.Args["strip_prefix"])
+			}
+		case "git_repository":
+			version = call.Args["tag"]
+			if version == "" {
+				version = call.Args["commit"]
+			}
+		}
+
+		extras := map[string]string{}
+		if githubOwner != "" {
+			extras["github_owner"], extras["github_repo"], extras["github_tag"] = githubOwner, githubRepo, version
+		}
+		purl := BuildCppPURL("bazel", name, version, extras)
+
+		seen[name] = struct{}{}
+		deps = append(deps, utils.Dependency{
+			GroupID:    "bazel",
+			ArtifactID: name,
+			Version:    version,
+			Scope:      "compile",
+			Key:        name,
+			Origin:     "declared",
+			PURL:       purl,
+			SPDXID:     BuildCppSPDXID(purl),
+		})
+	}
+	return deps, nil
+}
@@ -0,0 +1,142 @@
+package cpp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseBazelModule(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+module(name = "demo", version = "1.0")
+
+bazel_dep(name = "rules_cc", version = "0.0.9")
+bazel_dep(name = "zlib", version = "1.2.13")
+bazel_dep(name = "grpc", version = "1.54.0")
+bazel_dep(name = "unpinned_tool")
+
+single_version_override(module_name = "zlib", version = "1.3.0")
+
+archive_override(
+    module_name = "grpc",
+    urls = ["https://github.com/grpc/grpc/archive/v1.60.0.tar.gz"],
+)
+
+git_override(
+    module_name = "rules_cc",
+    remote = "https://github.com/bazelbuild/rules_cc.git",
+    commit = "abcdef1234567890",
+)
+
+git_override(
+    module_name = "unpinned_tool",
+    remote = "https://github.com/example/unpinned_tool.git",
+    commit = "fedcba0987654321",
+)
+`
+	if err := os.WriteFile(filepath.Join(dir, "MODULE.bazel"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	deps, err := ParseBazelModule(dir)
+	if err != nil {
+		t.Fatalf("ParseBazelModule: %v", err)
+	}
+	if len(deps) != 4 {
+		t.Fatalf("expected 4 deps, got %d: %+v", len(deps), deps)
+	}
+
+	byName := make(map[string]int, len(deps))
+	for i, d := range deps {
+		byName[d.ArtifactID] = i
+	}
+
+	unpinned := deps[byName["unpinned_tool"]]
+	if unpinned.Version != "fedcba0987654321" {
+		t.Errorf("unpinned_tool version = %q, want pinned commit (git_override fallback, no bazel_dep version declared)", unpinned.Version)
+	}
+
+	zlib := deps[byName["zlib"]]
+	if zlib.Version != "1.3.0" {
+		t.Errorf("zlib version = %q, want 1.3.0 (single_version_override should win)", zlib.Version)
+	}
+
+	grpc := deps[byName["grpc"]]
+	if grpc.Version != "1.60.0" {
+		t.Errorf("grpc version = %q, want 1.60.0 (from archive_override urls)", grpc.Version)
+	}
+	if grpc.PURL == "" {
+		t.Errorf("grpc PURL should be populated from the github archive url")
+	}
+
+	rulesCC := deps[byName["rules_cc"]]
+	if rulesCC.Version != "0.0.9" {
+		t.Errorf("rules_cc version = %q, want 0.0.9 (bazel_dep version wins; git_override is only a fallback when none is declared)", rulesCC.Version)
+	}
+}
+
+func TestParseBazelModuleMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	deps, err := ParseBazelModule(dir)
+	if err != nil {
+		t.Fatalf("ParseBazelModule on missing file should not error, got: %v", err)
+	}
+	if len(deps) != 0 {
+		t.Errorf("expected no deps, got %+v", deps)
+	}
+}
+
+func TestParseBazelWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+http_archive(
+    name = "com_google_absl",
+    urls = ["https://github.com/abseil/abseil-cpp/archive/20230802.1.tar.gz"],
+    strip_prefix = "abseil-cpp-20230802.1",
+)
+
+git_repository(
+    name = "boost",
+    remote = "https://github.com/boostorg/boost.git",
+    tag = "boost-1.83.0",
+)
+
+new_local_repository(
+    name = "local_lib",
+    path = "/opt/local_lib",
+    build_file = "//third_party:local_lib.BUILD",
+)
+`
+	if err := os.WriteFile(filepath.Join(dir, "WORKSPACE"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	deps, err := ParseBazelWorkspace(dir)
+	if err != nil {
+		t.Fatalf("ParseBazelWorkspace: %v", err)
+	}
+	if len(deps) != 3 {
+		t.Fatalf("expected 3 deps, got %d: %+v", len(deps), deps)
+	}
+
+	byName := make(map[string]int, len(deps))
+	for i, d := range deps {
+		byName[d.ArtifactID] = i
+	}
+
+	absl := deps[byName["com_google_absl"]]
+	if absl.Version != "20230802.1" {
+		t.Errorf("absl version = %q, want 20230802.1", absl.Version)
+	}
+
+	boost := deps[byName["boost"]]
+	if boost.Version != "boost-1.83.0" {
+		t.Errorf("boost version = %q, want tag boost-1.83.0", boost.Version)
+	}
+
+	local := deps[byName["local_lib"]]
+	if local.Version != "" {
+		t.Errorf("local_lib version = %q, want empty (no version to recover)", local.Version)
+	}
+}
@@ -0,0 +1,176 @@
+package cpp
+
+import (
+	"debug/elf"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ort-recovery/utils"
+)
+
+// binaryScanDirs are the output directories most C/C++ build systems place
+// linked artifacts in.
+var binaryScanDirs = []string{"build", "out", "bin", "lib"}
+
+// sonameRegex strips the "lib" prefix and shared-object suffix from a
+// library file name, e.g. "libssl.so.3" -> name="ssl" ext="so", leaving
+// the ".3" trailing version components to be recovered separately.
+var sonameRegex = regexp.MustCompile(`^lib(?P<name>.+?)\.(so|dylib|dll)(\.\d+)*$`)
+
+// sonameOverrides maps a stripped soname to the artifact id used elsewhere
+// in the ecosystem (Conan/vcpkg package names) when the two differ, e.g.
+// "ssl"/"crypto" from libssl.so.3/libcrypto.so.3 both come from OpenSSL.
+var sonameOverrides = map[string]string{
+	"ssl":    "openssl",
+	"crypto": "openssl",
+	"z":      "zlib",
+	"ffi":    "libffi",
+}
+
+// systemLibs are present on virtually every ELF host and carry no
+// dependency information worth recovering.
+var systemLibs = map[string]bool{
+	"c":       true,
+	"m":       true,
+	"pthread": true,
+	"dl":      true,
+	"rt":      true,
+	"util":    true,
+	"resolv":  true,
+}
+
+// ScanBinaries walks the project's common build-output directories
+// (build/, out/, bin/, lib/) for ELF shared objects and executables and
+// extracts their runtime dependencies from the dynamic section:
+// DT_NEEDED, DT_SONAME, and DT_RPATH/DT_RUNPATH. It gives a ground-truth
+// runtime dependency set that complements the heuristic include scan and
+// catches transitive libraries missing from manifests.
+//
+// Mach-O and PE binaries found under the same directories are skipped:
+// only ELF's dynamic section is parsed here. Static libraries (.a) are
+// skipped outright since they carry no dynamic section.
+func ScanBinaries(projectDir string) ([]utils.Dependency, error) {
+	deps := make([]utils.Dependency, 0)
+	seen := make(map[string]struct{})
+
+	for _, dir := range binaryScanDirs {
+		root := filepath.Join(projectDir, dir)
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() || strings.HasSuffix(path, ".a") {
+				return nil
+			}
+
+			f, ferr := elf.Open(path)
+			if ferr != nil {
+				// Not an ELF file (Mach-O, PE, or not a binary at all) — skip.
+				return nil
+			}
+			defer f.Close()
+
+			addBinaryDep(&deps, seen, f, rpathOf(f))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return deps, nil
+}
+
+// rpathOf joins a binary's DT_RPATH and DT_RUNPATH entries (both may be
+// present; RUNPATH takes effect at load time but RPATH is still recorded
+// for provenance) into a single colon-separated string.
+func rpathOf(f *elf.File) string {
+	var paths []string
+	if rp, err := f.DynString(elf.DT_RPATH); err == nil {
+		paths = append(paths, rp...)
+	}
+	if rp, err := f.DynString(elf.DT_RUNPATH); err == nil {
+		paths = append(paths, rp...)
+	}
+	return strings.Join(paths, ":")
+}
+
+// addBinaryDep reads f's DT_NEEDED and DT_SONAME entries, resolves each to
+// an artifact id, and appends a "binary"-origin utils.Dependency to deps
+// for every one not already present in seen. System libraries (libc,
+// libm, libpthread, ld-linux, ...) are filtered out.
+func addBinaryDep(deps *[]utils.Dependency, seen map[string]struct{}, f *elf.File, rpath string) {
+	sonames := make([]string, 0, 1)
+	if sn, err := f.DynString(elf.DT_SONAME); err == nil && len(sn) > 0 {
+		sonames = append(sonames, sn[0])
+	}
+	if needed, err := f.DynString(elf.DT_NEEDED); err == nil {
+		sonames = append(sonames, needed...)
+	}
+
+	for _, soname := range sonames {
+		if isSystemLib(soname) {
+			continue
+		}
+		artifact, versionSuffix := artifactFromSoname(soname)
+		if artifact == "" {
+			continue
+		}
+		if _, ok := seen[artifact]; ok {
+			continue
+		}
+		seen[artifact] = struct{}{}
+		*deps = append(*deps, utils.Dependency{
+			GroupID:       "cpp",
+			ArtifactID:    artifact,
+			Version:       versionSuffix,
+			Scope:         "runtime",
+			Key:           artifact,
+			Origin:        "binary",
+			RuntimeSoname: soname,
+			Rpath:         rpath,
+		})
+	}
+}
+
+// isSystemLib reports whether soname is a library present on virtually
+// every ELF host (libc, libm, libpthread, the dynamic linker itself, ...).
+func isSystemLib(soname string) bool {
+	if strings.HasPrefix(soname, "ld-linux") {
+		return true
+	}
+	m := sonameRegex.FindStringSubmatch(soname)
+	if m == nil {
+		return false
+	}
+	return systemLibs[m[1]]
+}
+
+// artifactFromSoname strips the "lib" prefix and platform suffix from a
+// shared-library file name to produce a coarse artifact id, applying
+// sonameOverrides where the library name and package name differ (e.g.
+// "libssl.so.3" -> "openssl"). versionSuffix is whatever trails the
+// extension (the "3" in "libssl.so.3"), a best-effort version to fall
+// back on when no declared version is available.
+func artifactFromSoname(soname string) (artifact, versionSuffix string) {
+	m := sonameRegex.FindStringSubmatch(soname)
+	if m == nil {
+		return "", ""
+	}
+	rawName, ext := m[1], m[2]
+
+	prefix := "lib" + rawName + "." + ext
+	versionSuffix = strings.TrimPrefix(soname[len(prefix):], ".")
+
+	artifact = rawName
+	if override, ok := sonameOverrides[rawName]; ok {
+		artifact = override
+	}
+	return artifact, versionSuffix
+}
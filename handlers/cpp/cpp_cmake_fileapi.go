@@ -0,0 +1,346 @@
+package cpp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"ort-recovery/utils"
+)
+
+// pkgConfigInvocationRegex picks the module name out of a shelled-out
+// `pkg-config --cflags --libs foo` invocation embedded in a build command.
+var pkgConfigInvocationRegex = regexp.MustCompile(`pkg-config\s+(?:--[A-Za-z-]+\s+)*([A-Za-z0-9_\-]+)`)
+
+// ResolveCMakeDependencies returns CMake-derived dependencies, preferring
+// the structured CMake File API and compile_commands.json backends (see
+// ParseCMakeFileAPI, ParseCompileCommands) over the regex-based
+// ParseCMakeLists whenever either backend turns up something, or whenever
+// utils.PreferStructured forces it. It falls back to ParseCMakeLists only
+// when neither structured source is present and the caller hasn't forced
+// the structured path.
+func ResolveCMakeDependencies(projectDir string) ([]utils.Dependency, error) {
+	apiDeps, err := ParseCMakeFileAPI(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	ccDeps, err := ParseCompileCommands(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	structured := append(apiDeps, ccDeps...)
+	if len(structured) > 0 || utils.PreferStructured() {
+		return structured, nil
+	}
+
+	return ParseCMakeLists(filepath.Join(projectDir, "CMakeLists.txt"))
+}
+
+// ParseCMakeFileAPI reads a configured CMake File API reply
+// (build/.cmake/api/v1/reply/) and enumerates every target's external link
+// libraries (codemodel-v2) plus any packages resolved via find_package
+// that CMake recorded in its cache (cache-v2: "<Pkg>_FOUND"/"<Pkg>_VERSION"
+// entries). Returns an empty slice, not an error, when no reply directory
+// exists — the File API is opt-in and most projects won't have one.
+func ParseCMakeFileAPI(projectDir string) ([]utils.Dependency, error) {
+	replyDir := filepath.Join(projectDir, "build", ".cmake", "api", "v1", "reply")
+	if _, err := os.Stat(replyDir); err != nil {
+		return []utils.Dependency{}, nil
+	}
+
+	indexPath, err := latestCMakeIndex(replyDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %v", replyDir, err)
+	}
+	if indexPath == "" {
+		return []utils.Dependency{}, nil
+	}
+
+	var index struct {
+		Reply json.RawMessage `json:"reply"`
+	}
+	if err := readJSONFile(indexPath, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", indexPath, err)
+	}
+
+	deps := make([]utils.Dependency, 0)
+	seen := make(map[string]struct{})
+
+	if jsonFile, ok := findCMakeReplyFile(index.Reply, "codemodel-v2"); ok {
+		codemodelDeps, err := parseCodemodel(filepath.Join(replyDir, jsonFile), replyDir, seen)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, codemodelDeps...)
+	}
+	if jsonFile, ok := findCMakeReplyFile(index.Reply, "cache-v2"); ok {
+		cacheDeps, err := parseCacheV2(filepath.Join(replyDir, jsonFile), seen)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, cacheDeps...)
+	}
+
+	return deps, nil
+}
+
+// latestCMakeIndex returns the most recent index-*.json in replyDir (CMake
+// writes one per configure, named with an embedded timestamp that sorts
+// lexicographically), or "" if none exist.
+func latestCMakeIndex(replyDir string) (string, error) {
+	entries, err := os.ReadDir(replyDir)
+	if err != nil {
+		return "", err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), "index-") && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) == 0 {
+		return "", nil
+	}
+	sort.Strings(names)
+	return filepath.Join(replyDir, names[len(names)-1]), nil
+}
+
+// findCMakeReplyFile looks up the jsonFile recorded for a given reply kind
+// (e.g. "codemodel-v2"), checking both the stateless-query form (the kind
+// as a direct key of reply) and the client-state-query form (the kind
+// nested under a "client-<name>" key).
+func findCMakeReplyFile(reply json.RawMessage, kind string) (string, bool) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(reply, &obj); err != nil {
+		return "", false
+	}
+	if raw, ok := obj[kind]; ok {
+		if f, ok := jsonFileOf(raw); ok {
+			return f, true
+		}
+	}
+	for key, raw := range obj {
+		if !strings.HasPrefix(key, "client-") {
+			continue
+		}
+		if f, ok := findCMakeReplyFile(raw, kind); ok {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+func jsonFileOf(raw json.RawMessage) (string, bool) {
+	var entry struct {
+		JSONFile string `json:"jsonFile"`
+	}
+	if json.Unmarshal(raw, &entry) != nil || entry.JSONFile == "" {
+		return "", false
+	}
+	return entry.JSONFile, true
+}
+
+// parseCodemodel reads a codemodel-v2 reply, follows each target's own
+// jsonFile, and extracts external libraries from link.commandFragments
+// entries tagged role "libraries" — this is what CMake actually passed to
+// the linker, so it can't mistake a target name for a dependency the way
+// the target_link_libraries regex can.
+func parseCodemodel(codemodelPath, replyDir string, seen map[string]struct{}) ([]utils.Dependency, error) {
+	var model struct {
+		Configurations []struct {
+			Targets []struct {
+				JSONFile string `json:"jsonFile"`
+			} `json:"targets"`
+		} `json:"configurations"`
+	}
+	if err := readJSONFile(codemodelPath, &model); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", codemodelPath, err)
+	}
+
+	deps := make([]utils.Dependency, 0)
+	for _, cfg := range model.Configurations {
+		for _, t := range cfg.Targets {
+			var target struct {
+				Link *struct {
+					CommandFragments []struct {
+						Fragment string `json:"fragment"`
+						Role     string `json:"role"`
+					} `json:"commandFragments"`
+				} `json:"link"`
+			}
+			targetPath := filepath.Join(replyDir, t.JSONFile)
+			if err := readJSONFile(targetPath, &target); err != nil || target.Link == nil {
+				continue
+			}
+			for _, frag := range target.Link.CommandFragments {
+				if frag.Role != "libraries" {
+					continue
+				}
+				for _, name := range libraryNamesFromFragment(frag.Fragment) {
+					if _, ok := seen[name]; ok {
+						continue
+					}
+					seen[name] = struct{}{}
+					deps = append(deps, utils.Dependency{
+						GroupID:    "cmake",
+						ArtifactID: name,
+						Scope:      "compile",
+						Key:        name,
+						Origin:     "declared",
+					})
+				}
+			}
+		}
+	}
+	return deps, nil
+}
+
+// libraryNamesFromFragment extracts artifact names out of one link.txt
+// fragment: "-lfoo" style flags and bare library paths/file names
+// ("/usr/lib/libssl.so.3", "libfoo.a").
+func libraryNamesFromFragment(fragment string) []string {
+	var names []string
+	for _, tok := range strings.Fields(fragment) {
+		switch {
+		case strings.HasPrefix(tok, "-l") && len(tok) > 2:
+			names = append(names, tok[2:])
+		case strings.HasSuffix(tok, ".a"):
+			base := strings.TrimSuffix(filepath.Base(tok), ".a")
+			names = append(names, strings.TrimPrefix(base, "lib"))
+		case strings.Contains(tok, "/") || strings.HasSuffix(tok, ".so") || strings.HasSuffix(tok, ".dylib"):
+			if artifact, _ := artifactFromSoname(filepath.Base(tok)); artifact != "" {
+				names = append(names, artifact)
+			}
+		}
+	}
+	return names
+}
+
+// parseCacheV2 reads a cache-v2 reply and recovers every package
+// find_package resolved, by pairing each "<Pkg>_FOUND" entry (true) with
+// its sibling "<Pkg>_VERSION" entry, when present. This is the only way to
+// get a real resolved version out of CMake rather than the range implied
+// by a find_package() call in CMakeLists.txt.
+func parseCacheV2(cachePath string, seen map[string]struct{}) ([]utils.Dependency, error) {
+	var cache struct {
+		Entries []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"entries"`
+	}
+	if err := readJSONFile(cachePath, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", cachePath, err)
+	}
+
+	found := make(map[string]bool)
+	versions := make(map[string]string)
+	for _, e := range cache.Entries {
+		switch {
+		case strings.HasSuffix(e.Name, "_FOUND"):
+			pkg := strings.TrimSuffix(e.Name, "_FOUND")
+			found[pkg] = e.Value == "TRUE" || e.Value == "1" || e.Value == "YES"
+		case strings.HasSuffix(e.Name, "_VERSION"):
+			versions[strings.TrimSuffix(e.Name, "_VERSION")] = e.Value
+		}
+	}
+
+	var pkgs []string
+	for pkg, ok := range found {
+		if ok {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	sort.Strings(pkgs)
+
+	deps := make([]utils.Dependency, 0)
+	for _, pkg := range pkgs {
+		if _, ok := seen[pkg]; ok {
+			continue
+		}
+		seen[pkg] = struct{}{}
+		deps = append(deps, utils.Dependency{
+			GroupID:    "cmake",
+			ArtifactID: pkg,
+			Version:    versions[pkg],
+			Scope:      "compile",
+			Key:        pkg,
+			Origin:     "declared",
+		})
+	}
+	return deps, nil
+}
+
+// compileCommand is one entry of compile_commands.json.
+type compileCommand struct {
+	Command   string   `json:"command"`
+	Arguments []string `json:"arguments"`
+}
+
+// ParseCompileCommands reads compile_commands.json and extracts "-l<lib>"
+// link flags, "-isystem <path>" external include directories, and
+// pkg-config invocations embedded in each entry's command/arguments.
+// Returns an empty slice, not an error, when the file doesn't exist.
+func ParseCompileCommands(projectDir string) ([]utils.Dependency, error) {
+	path := filepath.Join(projectDir, "compile_commands.json")
+	if _, err := os.Stat(path); err != nil {
+		return []utils.Dependency{}, nil
+	}
+
+	var commands []compileCommand
+	if err := readJSONFile(path, &commands); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+
+	seen := make(map[string]struct{})
+	deps := make([]utils.Dependency, 0)
+	addDep := func(artifact string) {
+		if artifact == "" {
+			return
+		}
+		if _, ok := seen[artifact]; ok {
+			return
+		}
+		seen[artifact] = struct{}{}
+		deps = append(deps, utils.Dependency{
+			GroupID:    "cmake",
+			ArtifactID: artifact,
+			Scope:      "compile",
+			Key:        artifact,
+			Origin:     "scanned",
+		})
+	}
+
+	for _, cc := range commands {
+		tokens := cc.Arguments
+		if len(tokens) == 0 && cc.Command != "" {
+			tokens = strings.Fields(cc.Command)
+		}
+		for i, tok := range tokens {
+			switch {
+			case strings.HasPrefix(tok, "-l") && len(tok) > 2:
+				addDep(tok[2:])
+			case tok == "-isystem" && i+1 < len(tokens):
+				addDep(NormalizeIncludeToArtifact(filepath.Base(tokens[i+1])))
+			}
+		}
+		for _, m := range pkgConfigInvocationRegex.FindAllStringSubmatch(cc.Command, -1) {
+			addDep(m[1])
+		}
+	}
+
+	return deps, nil
+}
+
+// readJSONFile reads path and unmarshals its contents into v.
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -4,18 +4,27 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
 	"ort-recovery/utils"
+	"ort-recovery/utils/policy"
+	"ort-recovery/utils/sbom"
 )
 
 // ---------------------------
 // C++ Handler (extended)
 // ---------------------------
-type CppHandler struct{}
+type CppHandler struct {
+	// ScanOptions configures the preprocessor front-end CollectCppIncludes
+	// uses to decide which #include directives are reachable for a given
+	// build. The zero value scans as the host platform with no extra
+	// defines (see effectiveDefines).
+	ScanOptions ScanOptions
+}
 
 func (h *CppHandler) Name() string {
 	return "C++"
@@ -57,39 +66,60 @@ func (h *CppHandler) Detect(projectDir string) bool {
 }
 
 // Scan parses declared deps from many manifests, scans includes, uses Syft, fills missing
-func (h *CppHandler) Scan(projectDir string) ([]utils.Dependency, error) {
+func (h *CppHandler) Scan(projectDir string) ([]utils.Dependency, utils.ScanReport, error) {
+	scanReport := utils.NewScanReport()
+	scanLogger := utils.NewScanLogger(projectDir).With(utils.LogKeyHandler, h.Name())
 	// 1. Parse all known manifests (each parser returns empty list if file missing)
 	vcpkgDeps, err := ParseVcpkgJSON(filepath.Join(projectDir, "vcpkg.json"))
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
 	}
 	conanTxtDeps, err := ParseConanFile(filepath.Join(projectDir, "conanfile.txt"))
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
 	}
 	conanPyDeps, err := ParseConanPy(filepath.Join(projectDir, "conanfile.py"))
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
 	}
-	cmakeDeps, err := ParseCMakeLists(filepath.Join(projectDir, "CMakeLists.txt"))
+	cmakeDeps, err := ResolveCMakeDependencies(projectDir)
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
 	}
 	makeDeps, err := ParseMakefile(filepath.Join(projectDir, "Makefile"))
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
 	}
 	mesonDeps, err := ParseMesonBuild(filepath.Join(projectDir, "meson.build"))
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
 	}
-	bazelDeps, err := ParseBazelBUILD(filepath.Join(projectDir, "BUILD"))
+	bazelModuleDeps, err := ParseBazelModule(projectDir)
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
+	}
+	bazelWorkspaceDeps, err := ParseBazelWorkspace(projectDir)
+	if err != nil {
+		return nil, scanReport, err
+	}
+	knownBazelRepos := make(map[string]bool, len(bazelModuleDeps)+len(bazelWorkspaceDeps))
+	for _, d := range bazelModuleDeps {
+		knownBazelRepos[d.ArtifactID] = true
+	}
+	for _, d := range bazelWorkspaceDeps {
+		knownBazelRepos[d.ArtifactID] = true
+	}
+	bazelUsageDeps, err := ParseBazelBUILD(filepath.Join(projectDir, "BUILD"), knownBazelRepos)
+	if err != nil {
+		return nil, scanReport, err
 	}
+	bazelDeps := make([]utils.Dependency, 0, len(bazelModuleDeps)+len(bazelWorkspaceDeps)+len(bazelUsageDeps))
+	bazelDeps = append(bazelDeps, bazelModuleDeps...)
+	bazelDeps = append(bazelDeps, bazelWorkspaceDeps...)
+	bazelDeps = append(bazelDeps, bazelUsageDeps...)
 	configureDeps, err := ParseConfigureAC(filepath.Join(projectDir, "configure.ac"))
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
 	}
 
 	// Merge declared deps
@@ -108,10 +138,86 @@ func (h *CppHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 		declaredMap[d.ArtifactID] = d
 	}
 
-	// 2. Scan source files for includes (wider extension coverage)
-	includes, err := CollectCppIncludes(projectDir)
+	// 1b. Parse Conan/vcpkg lockfiles for pinned, ground-truth versions and
+	// apply them onto whatever the manifest parsers already found (they
+	// outrank Syft below since a lockfile records an exact resolution, not
+	// just what happened to be installed).
+	conanLockDeps, err := ParseConanLock(filepath.Join(projectDir, "conan.lock"))
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
+	}
+	vcpkgLockDeps, err := ParseVcpkgLock(filepath.Join(projectDir, "vcpkg.json"))
+	if err != nil {
+		return nil, scanReport, err
+	}
+	lockDeps := make([]utils.Dependency, 0, len(conanLockDeps)+len(vcpkgLockDeps))
+	lockDeps = append(lockDeps, conanLockDeps...)
+	lockDeps = append(lockDeps, vcpkgLockDeps...)
+	lockMap := make(map[string]utils.Dependency)
+	for _, d := range lockDeps {
+		lockMap[d.ArtifactID] = d
+	}
+	for i, d := range declaredDeps {
+		lock, ok := lockMap[d.ArtifactID]
+		if !ok {
+			continue
+		}
+		if declaredDeps[i].Version == "" {
+			declaredDeps[i].Version = lock.Version
+		}
+		declaredDeps[i].Pinned = true
+	}
+
+	// 2. Scan source files for includes (wider extension coverage),
+	// resolving each to a canonical artifact (and registry-specific names,
+	// where the project or $ORT_RECOVERY_HOME ships overrides) via HeaderMap.
+	headerMap, err := LoadHeaderMap(projectDir)
+	if err != nil {
+		return nil, scanReport, err
+	}
+	includes, err := CollectCppIncludes(projectDir, headerMap, h.ScanOptions)
+	if err != nil {
+		return nil, scanReport, err
+	}
+
+	// 2b. Fill in PURLs that need context ParseVcpkgJSON/ParseCMakeLists
+	// don't have at parse time: a vcpkg dep's baseline (only known once the
+	// lockfile's parsed) and a CMake find_package result's registry name
+	// (looked up in the same HeaderMap CollectCppIncludes uses, by artifact
+	// name rather than include path).
+	vcpkgBaseline := lockMap["vcpkg-baseline"].Version
+	for i, d := range declaredDeps {
+		switch d.GroupID {
+		case "vcpkg":
+			if vcpkgBaseline == "" {
+				continue
+			}
+			purl := BuildCppPURL("vcpkg", d.ArtifactID, d.Version, map[string]string{"vcpkg_baseline": vcpkgBaseline})
+			declaredDeps[i].PURL = purl
+			declaredDeps[i].SPDXID = BuildCppSPDXID(purl)
+		case "cmake":
+			groupID, name := "cmake", d.ArtifactID
+			if entry, ok := headerMap.ResolveByArtifact(d.ArtifactID); ok && !entry.Ignore {
+				if entry.PURL != "" {
+					declaredDeps[i].VcpkgName = entry.VcpkgName
+					declaredDeps[i].ConanName = entry.ConanName
+					declaredDeps[i].PURL = entry.PURL
+					declaredDeps[i].SPDXID = BuildCppSPDXID(entry.PURL)
+					continue
+				}
+				switch {
+				case entry.VcpkgName != "":
+					groupID, name = "vcpkg", entry.VcpkgName
+				case entry.ConanName != "":
+					groupID, name = "conan", entry.ConanName
+				}
+				declaredDeps[i].VcpkgName = entry.VcpkgName
+				declaredDeps[i].ConanName = entry.ConanName
+			}
+			purl := BuildCppPURL(groupID, name, d.Version, nil)
+			declaredDeps[i].PURL = purl
+			declaredDeps[i].SPDXID = BuildCppSPDXID(purl)
+		}
 	}
 
 	// 3. Parse Syft output
@@ -146,8 +252,7 @@ func (h *CppHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 
 	// Add missing includes
 	for _, inc := range includes {
-		// Normalize include to artifact id (basic heuristic: take package part before any header path)
-		artifact := NormalizeIncludeToArtifact(inc)
+		artifact := inc.Artifact
 		if artifact == "" {
 			continue
 		}
@@ -155,35 +260,99 @@ func (h *CppHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 			continue
 		}
 		version := ""
-		if v, ok := syftMap[artifact]; ok && v != "" {
+		pinned := false
+		if lock, ok := lockMap[artifact]; ok && lock.Version != "" {
+			version = lock.Version
+			pinned = true
+		} else if v, ok := syftMap[artifact]; ok && v != "" {
 			version = v
 		} else {
 			// follow workflow: leave version empty so ORT marks as unknown (avoid "latest" unless necessary)
 			version = ""
 		}
+		purl := inc.PURL
+		if purl == "" {
+			groupID, name := "cpp", artifact
+			switch {
+			case inc.VcpkgName != "":
+				groupID, name = "vcpkg", inc.VcpkgName
+			case inc.ConanName != "":
+				groupID, name = "conan", inc.ConanName
+			}
+			purl = BuildCppPURL(groupID, name, version, nil)
+		}
 		dep := utils.Dependency{
 			GroupID:    "cpp",
 			ArtifactID: artifact,
 			Version:    version,
 			Scope:      "compile",
 			Key:        artifact,
+			VcpkgName:  inc.VcpkgName,
+			ConanName:  inc.ConanName,
+			PURL:       purl,
+			SPDXID:     BuildCppSPDXID(purl),
+			Pinned:     pinned,
 		}
 		final = append(final, dep)
 		seen[artifact] = struct{}{}
 
-		if version == "" {
+		if pinned {
+			_ = utils.AppendLog(projectDir, "[CppHandler] Added missing dependency: %s %s (pinned by lockfile)", artifact, version)
+		} else if version == "" {
 			_ = utils.AppendLog(projectDir, "[CppHandler] Added missing dependency: %s (version unknown — will be marked unknown by ORT)", artifact)
 		} else {
 			_ = utils.AppendLog(projectDir, "[CppHandler] Added missing dependency: %s %s (from Syft)", artifact, version)
 		}
 	}
 
+	// Add anything a lockfile resolved that no manifest or include declared
+	// at all (e.g. a transitive conan.lock requirement), so the pin survives
+	// even when nothing else referenced it directly.
+	for _, dep := range lockDeps {
+		if _, ok := seen[dep.ArtifactID]; ok {
+			continue
+		}
+		final = append(final, dep)
+		seen[dep.ArtifactID] = struct{}{}
+		_ = utils.AppendLog(projectDir, "[CppHandler] Added dependency from lockfile: %s %s", dep.ArtifactID, dep.Version)
+	}
+
 	// If no manifest files but includes exist → new vcpkg.json (fallback)
 	if len(declaredDeps) == 0 && len(includes) > 0 {
 		_ = utils.AppendLog(projectDir, "[CppHandler] No C++ manifests found; will create fallback vcpkg.json with %d dependencies.", len(includes))
 	}
 
-	return final, nil
+	// 5. Extract runtime dependencies from any built ELF binaries, filling in
+	// transitive libraries that never made it into a manifest or an include.
+	binaryDeps, err := ScanBinaries(projectDir)
+	if err != nil {
+		return nil, scanReport, err
+	}
+	for _, dep := range binaryDeps {
+		if _, ok := seen[dep.ArtifactID]; ok {
+			continue
+		}
+		final = append(final, dep)
+		seen[dep.ArtifactID] = struct{}{}
+		_ = utils.AppendLog(projectDir, "[CppHandler] Added runtime dependency: %s (from %s)", dep.ArtifactID, dep.RuntimeSoname)
+	}
+
+	scanReport.RecordPhase("declared", len(declaredDeps))
+	scanLogger.Info("parsed manifests", utils.LogKeyPhase, "declared", "deps", len(declaredDeps))
+	scanReport.RecordPhase("scanned", len(includes))
+	scanLogger.Info("scanned includes", utils.LogKeyPhase, "scanned", "deps", len(includes))
+	scanReport.RecordPhase("binary", len(binaryDeps))
+	scanLogger.Info("resolved binary deps", utils.LogKeyPhase, "binary", "deps", len(binaryDeps))
+	scanReport.RecordPhase("lockfile", len(lockDeps))
+	scanLogger.Info("parsed lockfile", utils.LogKeyPhase, "lockfile", "deps", len(lockDeps))
+
+	if err := policy.Apply(h.Name(), "cpp", projectDir, final); err != nil {
+		return nil, scanReport, err
+	}
+
+	scanReport.RecordEcosystem("cpp", len(final))
+	scanLogger.Info("scan complete", utils.LogKeyPhase, "ecosystem", utils.LogKeySource, "cpp", "deps", len(final))
+	return final, scanReport, nil
 }
 
 // GenerateRecoveryFile writes updated recovery manifests (backups included)
@@ -264,9 +433,18 @@ func fileExists(path string) bool {
 
 // VcpkgJSON represents vcpkg.json
 type VcpkgJSON struct {
-	Name         string   `json:"name,omitempty"`
-	Version      string   `json:"version,omitempty"`
-	Dependencies []string `json:"dependencies"`
+	Name            string          `json:"name,omitempty"`
+	Version         string          `json:"version,omitempty"`
+	BuiltinBaseline string          `json:"builtin-baseline,omitempty"`
+	Dependencies    []string        `json:"dependencies"`
+	Overrides       []VcpkgOverride `json:"overrides,omitempty"`
+}
+
+// VcpkgOverride pins a single port to an exact version, the way vcpkg.json
+// itself records a lockfile-resolved version.
+type VcpkgOverride struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
 }
 
 // ParseVcpkgJSON reads vcpkg.json and returns deps
@@ -287,12 +465,15 @@ func ParseVcpkgJSON(vcpkgPath string) ([]utils.Dependency, error) {
 
 	var deps []utils.Dependency
 	for _, name := range vcpkg.Dependencies {
+		purl := BuildCppPURL("vcpkg", name, "", nil)
 		deps = append(deps, utils.Dependency{
 			GroupID:    "vcpkg",
 			ArtifactID: name,
 			Version:    "", // leave empty so ORT marks unknown rather than defaulting to latest
 			Scope:      "compile",
 			Key:        name,
+			PURL:       purl,
+			SPDXID:     BuildCppSPDXID(purl),
 		})
 	}
 	return deps, nil
@@ -323,18 +504,16 @@ func ParseConanFile(conanPath string) ([]utils.Dependency, error) {
 			inReq = false
 		}
 		if inReq && line != "" && !strings.HasPrefix(line, "#") {
-			parts := strings.Split(line, "/")
-			name := parts[0]
-			version := ""
-			if len(parts) > 1 {
-				version = parts[1]
-			}
+			name, version, user, channel := parseConanCoordinate(line)
+			purl := BuildCppPURL("conan", name, version, map[string]string{"user": user, "channel": channel})
 			deps = append(deps, utils.Dependency{
 				GroupID:    "conan",
 				ArtifactID: name,
 				Version:    version,
 				Scope:      "compile",
 				Key:        name,
+				PURL:       purl,
+				SPDXID:     BuildCppSPDXID(purl),
 			})
 		}
 	}
@@ -359,18 +538,16 @@ func ParseConanPy(conanPyPath string) ([]utils.Dependency, error) {
 	matches := reSingle.FindAllSubmatch(data, -1)
 	deps := make([]utils.Dependency, 0)
 	for _, m := range matches {
-		parts := strings.Split(string(m[1]), "/")
-		name := parts[0]
-		version := ""
-		if len(parts) > 1 {
-			version = parts[1]
-		}
+		name, version, user, channel := parseConanCoordinate(string(m[1]))
+		purl := BuildCppPURL("conan", name, version, map[string]string{"user": user, "channel": channel})
 		deps = append(deps, utils.Dependency{
 			GroupID:    "conan",
 			ArtifactID: name,
 			Version:    version,
 			Scope:      "compile",
 			Key:        name,
+			PURL:       purl,
+			SPDXID:     BuildCppSPDXID(purl),
 		})
 	}
 	// list style
@@ -380,18 +557,16 @@ func ParseConanPy(conanPyPath string) ([]utils.Dependency, error) {
 		// split by commas and strip quotes
 		items := regexp.MustCompile(`['"]([^'"]+)['"]`).FindAllStringSubmatch(content, -1)
 		for _, it := range items {
-			parts := strings.Split(it[1], "/")
-			name := parts[0]
-			version := ""
-			if len(parts) > 1 {
-				version = parts[1]
-			}
+			name, version, user, channel := parseConanCoordinate(it[1])
+			purl := BuildCppPURL("conan", name, version, map[string]string{"user": user, "channel": channel})
 			deps = append(deps, utils.Dependency{
 				GroupID:    "conan",
 				ArtifactID: name,
 				Version:    version,
 				Scope:      "compile",
 				Key:        name,
+				PURL:       purl,
+				SPDXID:     BuildCppSPDXID(purl),
 			})
 		}
 	}
@@ -473,22 +648,28 @@ func ParseMakefile(makePath string) ([]utils.Dependency, error) {
 	for scanner.Scan() {
 		line := scanner.Text()
 		if m := rePkg.FindStringSubmatch(line); len(m) == 2 {
+			purl := BuildCppPURL("pkgconfig", m[1], "", nil)
 			deps = append(deps, utils.Dependency{
 				GroupID:    "make",
 				ArtifactID: m[1],
 				Version:    "",
 				Scope:      "compile",
 				Key:        m[1],
+				PURL:       purl,
+				SPDXID:     BuildCppSPDXID(purl),
 			})
 		}
 		if ms := reLib.FindAllStringSubmatch(line, -1); len(ms) > 0 {
 			for _, mm := range ms {
+				purl := BuildCppPURL("make", mm[1], "", nil)
 				deps = append(deps, utils.Dependency{
 					GroupID:    "make",
 					ArtifactID: mm[1],
 					Version:    "",
 					Scope:      "compile",
 					Key:        mm[1],
+					PURL:       purl,
+					SPDXID:     BuildCppSPDXID(purl),
 				})
 			}
 		}
@@ -512,19 +693,29 @@ func ParseMesonBuild(mesonPath string) ([]utils.Dependency, error) {
 	matches := reDep.FindAllStringSubmatch(string(data), -1)
 	deps := make([]utils.Dependency, 0)
 	for _, m := range matches {
+		purl := BuildCppPURL("meson", m[1], "", nil)
 		deps = append(deps, utils.Dependency{
 			GroupID:    "meson",
 			ArtifactID: m[1],
 			Version:    "",
 			Scope:      "compile",
 			Key:        m[1],
+			PURL:       purl,
+			SPDXID:     BuildCppSPDXID(purl),
 		})
 	}
 	return deps, nil
 }
 
-// ParseBazelBUILD extracts simple deps in strings (heuristic)
-func ParseBazelBUILD(buildPath string) ([]utils.Dependency, error) {
+// ParseBazelBUILD scans buildPath for deps = [...] list literals and
+// records a dependency for every "@repo" (or "@repo//pkg:target") label
+// whose repo is in known — the set of repos ParseBazelModule and
+// ParseBazelWorkspace already resolved a version for. This replaces the
+// old any-quoted-string-containing-"@"-or-"//" heuristic, which mistook
+// in-package target labels for dependencies and never carried a version;
+// the version lives on the module/workspace entry already in
+// declaredDeps, so this just confirms BUILD actually references it.
+func ParseBazelBUILD(buildPath string, known map[string]bool) ([]utils.Dependency, error) {
 	if _, err := os.Stat(buildPath); os.IsNotExist(err) {
 		return []utils.Dependency{}, nil
 	}
@@ -532,24 +723,28 @@ func ParseBazelBUILD(buildPath string) ([]utils.Dependency, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read %s: %v", buildPath, err)
 	}
-	// Very simple heuristic: find strings that look like @repo//pkg:target or "libname"
-	reStr := regexp.MustCompile(`["']([A-Za-z0-9@\-/\._:]+)["']`)
-	matches := reStr.FindAllStringSubmatch(string(data), -1)
+
+	depsListRe := regexp.MustCompile(`deps\s*=\s*\[([^\]]*)\]`)
+	labelRe := regexp.MustCompile(`@([A-Za-z0-9_.\-]+)`)
+
 	deps := make([]utils.Dependency, 0)
-	for _, m := range matches {
-		raw := m[1]
-		// ignore labels that are clearly local (start with ":" or contain "//:")
-		if strings.HasPrefix(raw, ":") {
-			continue
-		}
-		// include external-looking entries
-		if strings.Contains(raw, "@") || strings.Contains(raw, "//") {
+	seen := make(map[string]struct{})
+	for _, list := range depsListRe.FindAllStringSubmatch(string(data), -1) {
+		for _, lm := range labelRe.FindAllStringSubmatch(list[1], -1) {
+			repo := lm[1]
+			if !known[repo] {
+				continue
+			}
+			if _, ok := seen[repo]; ok {
+				continue
+			}
+			seen[repo] = struct{}{}
 			deps = append(deps, utils.Dependency{
 				GroupID:    "bazel",
-				ArtifactID: raw,
-				Version:    "",
+				ArtifactID: repo,
 				Scope:      "compile",
-				Key:        raw,
+				Key:        repo,
+				Origin:     "scanned",
 			})
 		}
 	}
@@ -571,34 +766,67 @@ func ParseConfigureAC(confPath string) ([]utils.Dependency, error) {
 	reAC := regexp.MustCompile(`(?i)AC_CHECK_LIB\(\s*([A-Za-z0-9\-_]+)`)
 	matches := reAC.FindAllStringSubmatch(text, -1)
 	for _, m := range matches {
+		purl := BuildCppPURL("autotools", m[1], "", nil)
 		deps = append(deps, utils.Dependency{
 			GroupID:    "autotools",
 			ArtifactID: m[1],
 			Version:    "",
 			Scope:      "compile",
 			Key:        m[1],
+			PURL:       purl,
+			SPDXID:     BuildCppSPDXID(purl),
 		})
 	}
 
 	rePKG := regexp.MustCompile(`(?i)PKG_CHECK_MODULES\(\s*[^,]+,\s*([A-Za-z0-9\-_]+)`)
 	pkgMatches := rePKG.FindAllStringSubmatch(text, -1)
 	for _, m := range pkgMatches {
+		purl := BuildCppPURL("autotools", m[1], "", nil)
 		deps = append(deps, utils.Dependency{
 			GroupID:    "autotools",
 			ArtifactID: m[1],
 			Version:    "",
 			Scope:      "compile",
 			Key:        m[1],
+			PURL:       purl,
+			SPDXID:     BuildCppSPDXID(purl),
 		})
 	}
 
 	return deps, nil
 }
 
-// CollectCppIncludes scans .c/.cpp/.cc/.cxx/.h/.hpp files for includes
-func CollectCppIncludes(projectDir string) ([]string, error) {
-	includeRegex := regexp.MustCompile(`^#include\s*["<]([^">]+)[">]`)
-	includes := make(map[string]struct{})
+// ResolvedInclude is an include path CollectCppIncludes has already
+// resolved to a canonical artifact via HeaderMap, carrying whatever
+// registry-specific names the matched entry recorded (empty when nothing
+// in the map matched and NormalizeIncludeToArtifact's bare heuristic was
+// used instead).
+type ResolvedInclude struct {
+	Artifact  string
+	VcpkgName string
+	ConanName string
+	PURL      string
+}
+
+var includeRegex = regexp.MustCompile(`^#include\s*["<]([^">]+)[">]`)
+var includeMacroRegex = regexp.MustCompile(`^#include\s+([A-Za-z_][A-Za-z0-9_]*)\s*$`)
+
+// CollectCppIncludes scans .c/.cpp/.cc/.cxx/.h/.hpp files for includes,
+// resolving each external one through hm (see HeaderMap.Resolve) so known
+// libraries come back with their vcpkg/Conan/purl names attached instead
+// of just NormalizeIncludeToArtifact's first-path-segment guess. Includes
+// matching an Ignore entry (system/kernel headers) are dropped entirely.
+//
+// Each file is run through a small preprocessor front-end (see
+// cpp_preprocessor.go) that tracks #if/#ifdef/#ifndef/#elif/#else/#endif
+// nesting against opts' macro environment, so an #include guarded by e.g.
+// #ifdef _WIN32 isn't reported on a Linux scan. #include MACRO forms are
+// resolved by substituting a #define'd macro that expands to a quoted or
+// angle-bracketed path. Includes skipped by an inactive branch are logged
+// with the guarding condition.
+func CollectCppIncludes(projectDir string, hm *HeaderMap, opts ScanOptions) ([]ResolvedInclude, error) {
+	defines := effectiveDefines(opts)
+	includes := make(map[string]ResolvedInclude)
 
 	err := filepath.Walk(projectDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -622,21 +850,42 @@ func CollectCppIncludes(projectDir string) ([]string, error) {
 		}
 		defer file.Close()
 
+		rel, err := filepath.Rel(projectDir, path)
+		if err != nil {
+			rel = path
+		}
+
+		pp := newPreprocessor(defines)
 		scanner := bufio.NewScanner(file)
 		for scanner.Scan() {
 			line := strings.TrimSpace(scanner.Text())
-			if strings.HasPrefix(line, "#include") {
-				m := includeRegex.FindStringSubmatch(line)
-				if len(m) == 2 {
-					inc := m[1]
-					// Only external includes (with "/") — preserves original behavior but collects more header names
-					if strings.Contains(inc, "/") {
-						// normalize (remove leading path segments like "boost/..." -> "boost")
-						artifact := NormalizeIncludeToArtifact(inc)
-						if artifact != "" {
-							includes[artifact] = struct{}{}
-						}
-					}
+			if !strings.HasPrefix(line, "#include") {
+				pp.handleLine(line)
+				continue
+			}
+
+			inc := ""
+			if m := includeRegex.FindStringSubmatch(line); len(m) == 2 {
+				inc = m[1]
+			} else if m := includeMacroRegex.FindStringSubmatch(line); len(m) == 2 {
+				if resolved, ok := resolveIncludeMacro(m[1], pp.defines); ok {
+					inc = resolved
+				}
+			}
+			if inc == "" {
+				continue
+			}
+
+			if !pp.active() {
+				_ = utils.AppendLog(projectDir, "[CppHandler] Skipped #include %q in %s (guarded by %s)", inc, rel, pp.guard())
+				continue
+			}
+
+			// Only external includes (with "/") — preserves original behavior but collects more header names
+			if strings.Contains(inc, "/") {
+				resolved, skip := resolveInclude(inc, hm)
+				if !skip && resolved.Artifact != "" {
+					includes[resolved.Artifact] = resolved
 				}
 			}
 		}
@@ -647,13 +896,37 @@ func CollectCppIncludes(projectDir string) ([]string, error) {
 		return nil, err
 	}
 
-	var list []string
-	for k := range includes {
-		list = append(list, k)
+	list := make([]ResolvedInclude, 0, len(includes))
+	for _, r := range includes {
+		list = append(list, r)
 	}
 	return list, nil
 }
 
+// resolveInclude looks inc up in hm, falling back to
+// NormalizeIncludeToArtifact's bare heuristic when nothing matches.
+// skip is true for entries the map marks Ignore (system/kernel headers).
+func resolveInclude(inc string, hm *HeaderMap) (resolved ResolvedInclude, skip bool) {
+	if hm != nil {
+		if entry, ok := hm.Resolve(inc); ok {
+			if entry.Ignore {
+				return ResolvedInclude{}, true
+			}
+			artifact := entry.Artifact
+			if artifact == "" {
+				artifact = NormalizeIncludeToArtifact(inc)
+			}
+			return ResolvedInclude{
+				Artifact:  artifact,
+				VcpkgName: entry.VcpkgName,
+				ConanName: entry.ConanName,
+				PURL:      entry.PURL,
+			}, false
+		}
+	}
+	return ResolvedInclude{Artifact: NormalizeIncludeToArtifact(inc)}, false
+}
+
 // NormalizeIncludeToArtifact converts include path to a coarse artifact id
 // e.g. "boost/algorithm/string.hpp" -> "boost", "fmt/format.h" -> "fmt"
 func NormalizeIncludeToArtifact(include string) string {
@@ -680,8 +953,18 @@ func WriteVcpkgJSON(vcpkgPath string, deps []utils.Dependency) error {
 	var vcpkg VcpkgJSON
 	seen := make(map[string]struct{})
 	for _, d := range deps {
-		// prefer artifact id if present
-		name := d.ArtifactID
+		// The synthetic "vcpkg-baseline" pseudo-dep (see ParseVcpkgLock)
+		// records the resolved registry baseline, not a real port.
+		if d.ArtifactID == "vcpkg-baseline" {
+			vcpkg.BuiltinBaseline = d.Version
+			continue
+		}
+
+		// prefer the vcpkg port name (set by HeaderMap) over the bare artifact id
+		name := d.VcpkgName
+		if name == "" {
+			name = d.ArtifactID
+		}
 		if name == "" {
 			continue
 		}
@@ -690,6 +973,10 @@ func WriteVcpkgJSON(vcpkgPath string, deps []utils.Dependency) error {
 		}
 		vcpkg.Dependencies = append(vcpkg.Dependencies, name)
 		seen[name] = struct{}{}
+
+		if d.Pinned && d.Version != "" {
+			vcpkg.Overrides = append(vcpkg.Overrides, VcpkgOverride{Name: name, Version: d.Version})
+		}
 	}
 
 	// Provide a minimal name/version so vcpkg.json is well-formed and more likely to be recognized.
@@ -714,7 +1001,16 @@ func WriteConanFile(conanPath string, deps []utils.Dependency) error {
 	seen := make(map[string]struct{})
 	lines := []string{"[requires]"}
 	for _, d := range deps {
-		name := d.ArtifactID
+		// Not a real package; see WriteVcpkgJSON.
+		if d.ArtifactID == "vcpkg-baseline" {
+			continue
+		}
+
+		// prefer the Conan reference name (set by HeaderMap) over the bare artifact id
+		name := d.ConanName
+		if name == "" {
+			name = d.ArtifactID
+		}
 		if name == "" {
 			continue
 		}
@@ -734,3 +1030,15 @@ func WriteConanFile(conanPath string, deps []utils.Dependency) error {
 	content := strings.Join(lines, "\n") + "\n"
 	return os.WriteFile(conanPath, []byte(content), 0644)
 }
+
+// EmitSBOM writes deps to w as an SBOM in the given format ("cyclonedx" or
+// "spdx"); see utils/sbom.
+func (h *CppHandler) EmitSBOM(deps []utils.Dependency, w io.Writer, format string) error {
+	return sbom.Emit(deps, w, format)
+}
+
+// ExportSBOM writes deps to outPath as an SBOM in the given format
+// ("cyclonedx" or "spdx"); see utils/sbom.ExportToFile.
+func (h *CppHandler) ExportSBOM(deps []utils.Dependency, format, outPath, projectDir string) error {
+	return sbom.ExportToFile(deps, format, outPath, projectDir)
+}
@@ -0,0 +1,146 @@
+package cpp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HeaderMapFileName is the conventional override file name, read from
+// either the project root or $ORT_RECOVERY_HOME.
+const HeaderMapFileName = "cpp-header-map.yaml"
+
+// HeaderMapEntry maps includes matching Pattern (a regex tested against
+// the include path, e.g. "^boost/") to the canonical artifact id plus
+// whatever registry-specific names the package uses, so recovery files
+// can emit the right name per ecosystem (e.g. "boost-asio" in vcpkg vs
+// "boost/1.83.0" in Conan) instead of NormalizeIncludeToArtifact's bare
+// first-path-segment guess. Ignore marks patterns (system headers like
+// <sys/socket.h>) that aren't a third-party dependency at all.
+type HeaderMapEntry struct {
+	Pattern   string `yaml:"pattern"`
+	Artifact  string `yaml:"artifact"`
+	VcpkgName string `yaml:"vcpkgName"`
+	ConanName string `yaml:"conanName"`
+	PURL      string `yaml:"purl"`
+	Ignore    bool   `yaml:"ignore"`
+
+	re *regexp.Regexp
+}
+
+// HeaderMap resolves include paths to HeaderMapEntry records. User
+// overrides (see LoadHeaderMap) are checked before defaultHeaderMap, so a
+// project can redefine or blacklist any built-in entry.
+type HeaderMap struct {
+	entries []HeaderMapEntry
+}
+
+// LoadHeaderMap builds a HeaderMap from the built-in table plus an
+// optional cpp-header-map.yaml, checked first at projectDir and then at
+// $ORT_RECOVERY_HOME. A missing override file is not an error — the
+// defaults cover the common libraries on their own.
+func LoadHeaderMap(projectDir string) (*HeaderMap, error) {
+	entries := append([]HeaderMapEntry{}, defaultHeaderMap...)
+
+	overridePath := filepath.Join(projectDir, HeaderMapFileName)
+	if _, err := os.Stat(overridePath); os.IsNotExist(err) {
+		overridePath = ""
+		if home := os.Getenv("ORT_RECOVERY_HOME"); home != "" {
+			candidate := filepath.Join(home, HeaderMapFileName)
+			if _, err := os.Stat(candidate); err == nil {
+				overridePath = candidate
+			}
+		}
+	}
+
+	if overridePath != "" {
+		userEntries, err := readHeaderMapFile(overridePath)
+		if err != nil {
+			return nil, err
+		}
+		// User entries take precedence: Resolve returns the first match.
+		entries = append(userEntries, entries...)
+	}
+
+	for i := range entries {
+		re, err := regexp.Compile(entries[i].Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid header map pattern %q: %v", entries[i].Pattern, err)
+		}
+		entries[i].re = re
+	}
+
+	return &HeaderMap{entries: entries}, nil
+}
+
+func readHeaderMapFile(path string) ([]HeaderMapEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	var entries []HeaderMapEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return entries, nil
+}
+
+// Resolve returns the first entry whose Pattern matches include, or
+// ok=false if nothing in the map (defaults or overrides) applies.
+func (hm *HeaderMap) Resolve(include string) (HeaderMapEntry, bool) {
+	for _, e := range hm.entries {
+		if e.re != nil && e.re.MatchString(include) {
+			return e, true
+		}
+	}
+	return HeaderMapEntry{}, false
+}
+
+// ResolveByArtifact returns the first entry whose Artifact matches name
+// case-insensitively, for callers that already have a canonical-ish
+// package name (e.g. a CMake find_package(Pkg) argument) rather than an
+// include path to match against Pattern.
+func (hm *HeaderMap) ResolveByArtifact(name string) (HeaderMapEntry, bool) {
+	lower := strings.ToLower(name)
+	for _, e := range hm.entries {
+		if e.Artifact != "" && strings.ToLower(e.Artifact) == lower {
+			return e, true
+		}
+	}
+	return HeaderMapEntry{}, false
+}
+
+// defaultHeaderMap covers the libraries whose include layout doesn't map
+// cleanly onto NormalizeIncludeToArtifact's first-path-segment heuristic,
+// plus the kernel/libc header directories that should never be treated as
+// a dependency.
+var defaultHeaderMap = []HeaderMapEntry{
+	{Pattern: `^boost/`, Artifact: "boost", VcpkgName: "boost", ConanName: "boost", PURL: "pkg:github/boostorg/boost"},
+	{Pattern: `^Qt[A-Za-z0-9]*/`, Artifact: "qtbase", VcpkgName: "qtbase", ConanName: "qt", PURL: "pkg:generic/qt"},
+	{Pattern: `^Eigen/`, Artifact: "eigen3", VcpkgName: "eigen3", ConanName: "eigen", PURL: "pkg:github/eigenteam/eigen-git-mirror"},
+	{Pattern: `^opencv2/`, Artifact: "opencv", VcpkgName: "opencv", ConanName: "opencv", PURL: "pkg:github/opencv/opencv"},
+	{Pattern: `^Poco/`, Artifact: "poco", VcpkgName: "poco", ConanName: "poco", PURL: "pkg:github/pocoproject/poco"},
+	{Pattern: `^zlib\.h$`, Artifact: "zlib", VcpkgName: "zlib", ConanName: "zlib", PURL: "pkg:github/madler/zlib"},
+	{Pattern: `^openssl/`, Artifact: "openssl", VcpkgName: "openssl", ConanName: "openssl", PURL: "pkg:github/openssl/openssl"},
+	{Pattern: `^fmt/`, Artifact: "fmt", VcpkgName: "fmt", ConanName: "fmt", PURL: "pkg:github/fmtlib/fmt"},
+	{Pattern: `^spdlog/`, Artifact: "spdlog", VcpkgName: "spdlog", ConanName: "spdlog", PURL: "pkg:github/gabime/spdlog"},
+	{Pattern: `^absl/`, Artifact: "abseil", VcpkgName: "abseil", ConanName: "abseil", PURL: "pkg:github/abseil/abseil-cpp"},
+	{Pattern: `^google/protobuf/`, Artifact: "protobuf", VcpkgName: "protobuf", ConanName: "protobuf", PURL: "pkg:github/protocolbuffers/protobuf"},
+	{Pattern: `^grpc(pp)?/`, Artifact: "grpc", VcpkgName: "grpc", ConanName: "grpc", PURL: "pkg:github/grpc/grpc"},
+	{Pattern: `^gtest/`, Artifact: "gtest", VcpkgName: "gtest", ConanName: "gtest", PURL: "pkg:github/google/googletest"},
+	{Pattern: `^[Cc]atch2/`, Artifact: "catch2", VcpkgName: "catch2", ConanName: "catch2", PURL: "pkg:github/catchorg/Catch2"},
+	{Pattern: `^nlohmann/json\.hpp$`, Artifact: "nlohmann-json", VcpkgName: "nlohmann-json", ConanName: "nlohmann_json", PURL: "pkg:github/nlohmann/json"},
+	{Pattern: `^rapidjson/`, Artifact: "rapidjson", VcpkgName: "rapidjson", ConanName: "rapidjson", PURL: "pkg:github/Tencent/rapidjson"},
+	{Pattern: `^curl/`, Artifact: "curl", VcpkgName: "curl", ConanName: "libcurl", PURL: "pkg:github/curl/curl"},
+	{Pattern: `^SDL2?/`, Artifact: "sdl2", VcpkgName: "sdl2", ConanName: "sdl", PURL: "pkg:github/libsdl-org/SDL"},
+	{Pattern: `^GLFW/`, Artifact: "glfw3", VcpkgName: "glfw3", ConanName: "glfw", PURL: "pkg:github/glfw/glfw"},
+	{Pattern: `^glad/`, Artifact: "glad", VcpkgName: "glad", ConanName: "glad", PURL: "pkg:github/Dav1dde/glad"},
+	{Pattern: `^glm/`, Artifact: "glm", VcpkgName: "glm", ConanName: "glm", PURL: "pkg:github/g-truc/glm"},
+
+	// System/kernel headers: never a recoverable third-party dependency.
+	{Pattern: `^(sys|linux|bits|asm|asm-generic|arpa|netinet)/`, Ignore: true},
+}
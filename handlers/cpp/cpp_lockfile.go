@@ -0,0 +1,249 @@
+package cpp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"ort-recovery/utils"
+)
+
+// conanLockV2 is Conan 2's conan.lock shape: a flat list of refs per
+// requirement kind, each like "zlib/1.3#<revision>%<timestamp>".
+type conanLockV2 struct {
+	Requires       []string `json:"requires"`
+	BuildRequires  []string `json:"build_requires"`
+	PythonRequires []string `json:"python_requires"`
+}
+
+// conanLockV1 is the older graph_lock shape: a map of arbitrary node ids
+// to graph nodes, each carrying its own "ref".
+type conanLockV1 struct {
+	GraphLock struct {
+		Nodes map[string]struct {
+			Ref string `json:"ref"`
+		} `json:"nodes"`
+	} `json:"graph_lock"`
+}
+
+// ParseConanLock reads a Conan lockfile (conan.lock) and recovers the
+// exact name/version/revision Conan resolved for every requirement,
+// handling both the v2 format (top-level "requires"/"build_requires"/
+// "python_requires" string arrays) and the older v1 "graph_lock.nodes"
+// format. A missing file is not an error — lockfiles are optional.
+func ParseConanLock(path string) ([]utils.Dependency, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []utils.Dependency{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %v", path, err)
+	}
+
+	deps := make([]utils.Dependency, 0)
+
+	if _, ok := probe["graph_lock"]; ok {
+		var lock conanLockV1
+		if err := json.Unmarshal(data, &lock); err != nil {
+			return nil, fmt.Errorf("invalid graph_lock in %s: %v", path, err)
+		}
+		var ids []string
+		for id := range lock.GraphLock.Nodes {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			if dep, ok := conanDepFromRef(lock.GraphLock.Nodes[id].Ref, "compile"); ok {
+				deps = append(deps, dep)
+			}
+		}
+		return deps, nil
+	}
+
+	var lock conanLockV2
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %v", path, err)
+	}
+	for _, ref := range lock.Requires {
+		if dep, ok := conanDepFromRef(ref, "compile"); ok {
+			deps = append(deps, dep)
+		}
+	}
+	for _, ref := range lock.BuildRequires {
+		if dep, ok := conanDepFromRef(ref, "build"); ok {
+			deps = append(deps, dep)
+		}
+	}
+	for _, ref := range lock.PythonRequires {
+		if dep, ok := conanDepFromRef(ref, "build"); ok {
+			deps = append(deps, dep)
+		}
+	}
+	return deps, nil
+}
+
+// conanDepFromRef parses one Conan ref ("zlib/1.3#<revision>%<timestamp>")
+// into a pinned Dependency. The revision and timestamp suffixes (after
+// "#" and "%") are recovery noise, not part of the version, so they're cut
+// off before splitting name/version on "/".
+func conanDepFromRef(ref, scope string) (utils.Dependency, bool) {
+	core := ref
+	if i := strings.IndexAny(ref, "#%"); i != -1 {
+		core = ref[:i]
+	}
+	name, version, user, channel := parseConanCoordinate(core)
+	if name == "" {
+		return utils.Dependency{}, false
+	}
+	purl := BuildCppPURL("conan", name, version, map[string]string{"user": user, "channel": channel})
+	return utils.Dependency{
+		GroupID:    "conan",
+		ArtifactID: name,
+		Version:    version,
+		Scope:      scope,
+		Key:        name,
+		Origin:     "lockfile",
+		Pinned:     true,
+		PURL:       purl,
+		SPDXID:     BuildCppSPDXID(purl),
+	}, true
+}
+
+// ParseVcpkgLock recovers pinned versions for a vcpkg-based project from
+// path (the project's vcpkg.json): its "builtin-baseline" and any
+// "overrides": [{"name", "version"}] entries, plus the default registry
+// baseline from a sibling vcpkg-configuration.json and, when present, the
+// per-package baseline commit recorded in a sibling vcpkg-lock.json (used
+// as a last-resort version stamp for an override that pins a port without
+// giving it a semantic version).
+func ParseVcpkgLock(path string) ([]utils.Dependency, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []utils.Dependency{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	var manifest struct {
+		BuiltinBaseline string `json:"builtin-baseline"`
+		Overrides       []struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"overrides"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %v", path, err)
+	}
+
+	dir := filepath.Dir(path)
+
+	stamps, err := readVcpkgLockStamps(filepath.Join(dir, "vcpkg-lock.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	baseline := manifest.BuiltinBaseline
+	if baseline == "" {
+		baseline, err = readVcpkgConfigBaseline(filepath.Join(dir, "vcpkg-configuration.json"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	baselineExtras := map[string]string{"vcpkg_baseline": baseline}
+
+	deps := make([]utils.Dependency, 0, len(manifest.Overrides)+1)
+	for _, ov := range manifest.Overrides {
+		if ov.Name == "" {
+			continue
+		}
+		version := ov.Version
+		if version == "" {
+			version = stamps[ov.Name]
+		}
+		purl := BuildCppPURL("vcpkg", ov.Name, version, baselineExtras)
+		deps = append(deps, utils.Dependency{
+			GroupID:    "vcpkg",
+			ArtifactID: ov.Name,
+			Version:    version,
+			Scope:      "compile",
+			Key:        ov.Name,
+			Origin:     "lockfile",
+			Pinned:     true,
+			PURL:       purl,
+			SPDXID:     BuildCppSPDXID(purl),
+		})
+	}
+
+	if baseline != "" {
+		// Not a real port, but recorded so a recovery file can preserve the
+		// baseline commit the original lock was resolved against.
+		purl := BuildCppPURL("vcpkg", "vcpkg-baseline", baseline, nil)
+		deps = append(deps, utils.Dependency{
+			GroupID:    "vcpkg",
+			ArtifactID: "vcpkg-baseline",
+			Version:    baseline,
+			Scope:      "build",
+			Key:        "vcpkg-baseline",
+			Origin:     "lockfile",
+			Pinned:     true,
+			PURL:       purl,
+			SPDXID:     BuildCppSPDXID(purl),
+		})
+	}
+
+	return deps, nil
+}
+
+// readVcpkgConfigBaseline reads the default registry's baseline commit
+// from a vcpkg-configuration.json. A missing file yields "", nil.
+func readVcpkgConfigBaseline(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	var cfg struct {
+		DefaultRegistry struct {
+			Baseline string `json:"baseline"`
+		} `json:"default-registry"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return "", fmt.Errorf("invalid JSON in %s: %v", path, err)
+	}
+	return cfg.DefaultRegistry.Baseline, nil
+}
+
+// readVcpkgLockStamps reads a vcpkg-lock.json, mapping each port name to
+// the baseline commit it was resolved against, e.g.
+// {"zlib": {"baseline": "a1b2c3..."}}. A missing file yields an empty map.
+func readVcpkgLockStamps(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	var raw map[string]struct {
+		Baseline string `json:"baseline"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %v", path, err)
+	}
+	stamps := make(map[string]string, len(raw))
+	for name, entry := range raw {
+		stamps[name] = entry.Baseline
+	}
+	return stamps, nil
+}
@@ -0,0 +1,301 @@
+package cpp
+
+import (
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ScanOptions configures the preprocessor front-end CollectCppIncludes
+// uses to decide which #include directives are actually reachable for a
+// given build, instead of grepping every #include regardless of
+// surrounding #if/#ifdef/#ifndef guards.
+type ScanOptions struct {
+	// Defines seeds the macro environment #if/#ifdef/#ifndef/#elif
+	// expressions are evaluated against (and #include MACRO forms are
+	// substituted from). A value of "0" is falsy in a bare #if FOO check;
+	// any other value (including "") counts as truthy/defined. Entries
+	// here override DefaultDefines for the same Target.
+	Defines map[string]string
+
+	// Target selects which platform guard macros DefaultDefines seeds
+	// ("linux", "windows", "darwin", ...). Empty means the host platform
+	// (runtime.GOOS).
+	Target string
+}
+
+// DefaultDefines returns the handful of platform guard macros C/C++ code
+// actually branches on for platform detection (not a real compiler's full
+// predefined macro set — this is a heuristic front-end, not a compiler).
+func DefaultDefines(target string) map[string]string {
+	switch target {
+	case "windows":
+		return map[string]string{"_WIN32": "1", "_WIN64": "1"}
+	case "darwin":
+		return map[string]string{"__APPLE__": "1", "__MACH__": "1", "__unix__": "1"}
+	case "linux":
+		return map[string]string{"__linux__": "1", "__unix__": "1", "linux": "1"}
+	default:
+		return map[string]string{}
+	}
+}
+
+// effectiveDefines merges opts.Defines over DefaultDefines(opts.Target),
+// defaulting Target to the host platform when unset.
+func effectiveDefines(opts ScanOptions) map[string]string {
+	target := opts.Target
+	if target == "" {
+		target = runtime.GOOS
+	}
+	merged := DefaultDefines(target)
+	for k, v := range opts.Defines {
+		merged[k] = v
+	}
+	return merged
+}
+
+// condState tracks one nesting level of #if/#ifdef/#ifndef. active is
+// whether the current branch at this level is live, already ANDed with
+// whether the enclosing level was live; taken is whether any branch at
+// this level has been active yet, so a later #elif/#else doesn't also
+// fire; parentLive is the enclosing level's liveness, needed because a
+// later #elif/#else at this level must stay dead if the parent is dead
+// regardless of its own condition. desc is the directive text, used to
+// describe why an include was skipped.
+type condState struct {
+	active     bool
+	taken      bool
+	parentLive bool
+	desc       string
+}
+
+// preprocessor evaluates #if/#ifdef/#ifndef/#elif/#else/#endif nesting
+// and tracks #define/#undef, line by line, for one source file.
+type preprocessor struct {
+	defines map[string]string
+	stack   []condState
+}
+
+func newPreprocessor(defines map[string]string) *preprocessor {
+	d := make(map[string]string, len(defines))
+	for k, v := range defines {
+		d[k] = v
+	}
+	return &preprocessor{defines: d}
+}
+
+// active reports whether the current line is in a live branch.
+func (p *preprocessor) active() bool {
+	if len(p.stack) == 0 {
+		return true
+	}
+	return p.stack[len(p.stack)-1].active
+}
+
+// guard describes the innermost conditional currently suppressing lines,
+// for logging, or "" if the file is fully active right now.
+func (p *preprocessor) guard() string {
+	if p.active() || len(p.stack) == 0 {
+		return ""
+	}
+	return p.stack[len(p.stack)-1].desc
+}
+
+// handleLine processes one line: directives update conditional/macro
+// state, anything else is left untouched (the caller decides what to do
+// with it based on p.active()).
+func (p *preprocessor) handleLine(line string) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "#") {
+		return
+	}
+	directive := strings.TrimSpace(strings.TrimPrefix(trimmed, "#"))
+	word, rest := splitDirective(directive)
+
+	switch word {
+	case "if":
+		parentLive := p.active()
+		cond := parentLive && p.eval(rest)
+		p.stack = append(p.stack, condState{active: cond, taken: cond, parentLive: parentLive, desc: "#if " + rest})
+	case "ifdef":
+		parentLive := p.active()
+		name := strings.TrimSpace(rest)
+		cond := parentLive && p.isDefined(name)
+		p.stack = append(p.stack, condState{active: cond, taken: cond, parentLive: parentLive, desc: "#ifdef " + name})
+	case "ifndef":
+		parentLive := p.active()
+		name := strings.TrimSpace(rest)
+		cond := parentLive && !p.isDefined(name)
+		p.stack = append(p.stack, condState{active: cond, taken: cond, parentLive: parentLive, desc: "#ifndef " + name})
+	case "elif":
+		if len(p.stack) == 0 {
+			return
+		}
+		top := &p.stack[len(p.stack)-1]
+		if top.taken || !top.parentLive {
+			top.active = false
+			return
+		}
+		top.active = p.eval(rest)
+		top.taken = top.taken || top.active
+		top.desc = "#elif " + rest
+	case "else":
+		if len(p.stack) == 0 {
+			return
+		}
+		top := &p.stack[len(p.stack)-1]
+		if top.taken || !top.parentLive {
+			top.active = false
+			return
+		}
+		top.active = true
+		top.taken = true
+		top.desc = "#else (of " + top.desc + ")"
+	case "endif":
+		if len(p.stack) > 0 {
+			p.stack = p.stack[:len(p.stack)-1]
+		}
+	case "define":
+		if !p.active() {
+			return
+		}
+		name, value := splitDirective(rest)
+		p.defines[name] = value
+	case "undef":
+		if !p.active() {
+			return
+		}
+		delete(p.defines, strings.TrimSpace(rest))
+	}
+}
+
+func (p *preprocessor) isDefined(name string) bool {
+	_, ok := p.defines[name]
+	return ok
+}
+
+// splitDirective splits "ifdef _WIN32" into ("ifdef", "_WIN32"), or
+// "endif" into ("endif", "").
+func splitDirective(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
+
+var exprTokenRe = regexp.MustCompile(`\(|\)|\|\||&&|!|[A-Za-z_][A-Za-z0-9_]*|[0-9]+`)
+
+// eval evaluates a #if/#elif constant expression. It supports the subset
+// that real-world platform guards actually use — defined(X)/defined X,
+// !, &&, ||, parens, bare macro truthiness, and integer literals — not
+// full C constant-expression arithmetic (no +, -, ==, <, etc.); this is a
+// heuristic front-end inspired by source-to-source C tools, not a
+// compiler, and that's a deliberate scope cut.
+func (p *preprocessor) eval(expr string) bool {
+	ep := &exprParser{tokens: exprTokenRe.FindAllString(expr, -1), defines: p.defines}
+	return ep.parseOr()
+}
+
+type exprParser struct {
+	tokens  []string
+	pos     int
+	defines map[string]string
+}
+
+func (ep *exprParser) peek() string {
+	if ep.pos >= len(ep.tokens) {
+		return ""
+	}
+	return ep.tokens[ep.pos]
+}
+
+func (ep *exprParser) next() string {
+	t := ep.peek()
+	ep.pos++
+	return t
+}
+
+func (ep *exprParser) parseOr() bool {
+	v := ep.parseAnd()
+	for ep.peek() == "||" {
+		ep.next()
+		rhs := ep.parseAnd()
+		v = v || rhs
+	}
+	return v
+}
+
+func (ep *exprParser) parseAnd() bool {
+	v := ep.parseNot()
+	for ep.peek() == "&&" {
+		ep.next()
+		rhs := ep.parseNot()
+		v = v && rhs
+	}
+	return v
+}
+
+func (ep *exprParser) parseNot() bool {
+	if ep.peek() == "!" {
+		ep.next()
+		return !ep.parseNot()
+	}
+	return ep.parsePrimary()
+}
+
+func (ep *exprParser) parsePrimary() bool {
+	tok := ep.next()
+	switch tok {
+	case "":
+		return false
+	case "(":
+		v := ep.parseOr()
+		if ep.peek() == ")" {
+			ep.next()
+		}
+		return v
+	case "defined":
+		paren := false
+		if ep.peek() == "(" {
+			ep.next()
+			paren = true
+		}
+		name := ep.next()
+		if paren && ep.peek() == ")" {
+			ep.next()
+		}
+		_, ok := ep.defines[name]
+		return ok
+	default:
+		if n, err := strconv.Atoi(tok); err == nil {
+			return n != 0
+		}
+		val, ok := ep.defines[tok]
+		if !ok {
+			return false
+		}
+		return val != "0"
+	}
+}
+
+// resolveIncludeMacro looks up name in defines and, if its value looks
+// like a quoted/angle-bracketed include ("foo/bar.h" or <foo/bar.h>,
+// the usual shape of `#define CONFIG_HEADER "foo/bar.h"`), returns the
+// bare path inside. Used to resolve `#include MACRO` forms.
+func resolveIncludeMacro(name string, defines map[string]string) (string, bool) {
+	val, ok := defines[name]
+	if !ok {
+		return "", false
+	}
+	val = strings.TrimSpace(val)
+	if len(val) >= 2 {
+		if (val[0] == '"' && val[len(val)-1] == '"') || (val[0] == '<' && val[len(val)-1] == '>') {
+			return val[1 : len(val)-1], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,103 @@
+package cpp
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// BuildCppPURL builds a best-effort Package URL for a C++ dependency,
+// switching format by groupID (the "vcpkg"/"conan"/"bazel"/"make"/...
+// convention ParseVcpkgJSON/ParseConanFile/ParseBazelModule/etc. already
+// tag deps with). extras carries format-specific query parameters:
+// "vcpkg_baseline" for vcpkg, "user"/"channel" for Conan, "github_owner"/
+// "github_repo"/"github_tag" to emit a pkg:github PURL instead of
+// pkg:bazel, and "download_url" for the generic fallback. Unrecognized
+// groupIDs (cmake, meson, autotools, ...) fall through to the generic
+// case, same as purlForDependency in utils/sbom does for other languages.
+func BuildCppPURL(groupID, artifact, version string, extras map[string]string) string {
+	switch groupID {
+	case "vcpkg":
+		purl := fmt.Sprintf("pkg:vcpkg/%s@%s", artifact, version)
+		if baseline := extras["vcpkg_baseline"]; baseline != "" {
+			purl += "?vcpkg_baseline=" + url.QueryEscape(baseline)
+		}
+		return purl
+	case "conan":
+		purl := fmt.Sprintf("pkg:conan/%s@%s", artifact, version)
+		var q []string
+		if u := extras["user"]; u != "" {
+			q = append(q, "user="+url.QueryEscape(u))
+		}
+		if c := extras["channel"]; c != "" {
+			q = append(q, "channel="+url.QueryEscape(c))
+		}
+		if len(q) > 0 {
+			purl += "?" + strings.Join(q, "&")
+		}
+		return purl
+	case "bazel":
+		if owner, repo := extras["github_owner"], extras["github_repo"]; owner != "" && repo != "" {
+			tag := extras["github_tag"]
+			if tag == "" {
+				tag = version
+			}
+			return fmt.Sprintf("pkg:github/%s/%s@%s", owner, repo, tag)
+		}
+		return fmt.Sprintf("pkg:bazel/%s@%s", artifact, version)
+	case "make":
+		return fmt.Sprintf("pkg:generic/lib%s", artifact)
+	default:
+		if dl := extras["download_url"]; dl != "" {
+			return fmt.Sprintf("pkg:generic/%s@%s?download_url=%s", artifact, version, url.QueryEscape(dl))
+		}
+		return fmt.Sprintf("pkg:generic/%s@%s", artifact, version)
+	}
+}
+
+// BuildCppSPDXID derives a stable SPDX element id from purl, so the same
+// dependency gets the same id across scans without sanitizing an
+// arbitrary package name into the SPDXID character set itself.
+func BuildCppSPDXID(purl string) string {
+	sum := sha1.Sum([]byte(purl))
+	return "SPDXRef-cpp-" + hex.EncodeToString(sum[:])
+}
+
+// parseConanCoordinate splits a Conan requirement like
+// "zlib/1.3@user/channel" or plain "zlib/1.3" into its name/version/
+// user/channel parts; the "@user/channel" suffix is optional.
+func parseConanCoordinate(ref string) (name, version, user, channel string) {
+	coord, userChannel, hasUC := strings.Cut(ref, "@")
+	parts := strings.SplitN(coord, "/", 2)
+	name = parts[0]
+	if len(parts) > 1 {
+		version = parts[1]
+	}
+	if hasUC {
+		ucParts := strings.SplitN(userChannel, "/", 2)
+		user = ucParts[0]
+		if len(ucParts) > 1 {
+			channel = ucParts[1]
+		}
+	}
+	return name, version, user, channel
+}
+
+// githubArchiveRe recognizes a GitHub archive/release download URL, e.g.
+// "https://github.com/owner/repo/archive/refs/tags/v1.2.3.tar.gz" or
+// "https://github.com/owner/repo/releases/download/v1.2.3/repo.tar.gz",
+// capturing the owner and repo.
+var githubArchiveRe = regexp.MustCompile(`github\.com/([A-Za-z0-9_.\-]+)/([A-Za-z0-9_.\-]+)/(?:archive|releases)/`)
+
+// githubOwnerRepo extracts the owner/repo from a GitHub archive/release
+// URL, or ("", "") if url isn't one.
+func githubOwnerRepo(rawURL string) (owner, repo string) {
+	m := githubArchiveRe.FindStringSubmatch(rawURL)
+	if m == nil {
+		return "", ""
+	}
+	return m[1], strings.TrimSuffix(m[2], ".git")
+}
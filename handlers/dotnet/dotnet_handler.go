@@ -4,18 +4,52 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
+	"ort-recovery/handlers/dotnet/nugetindex"
+	"ort-recovery/handlers/dotnet/nugetresolver"
 	"ort-recovery/utils"
+	"ort-recovery/utils/policy"
+	"ort-recovery/utils/sbom"
 )
 
 // ---------------------------
 // .NET Handler
 // ---------------------------
-type DotNetHandler struct{}
+type DotNetHandler struct {
+	// NuGetIndex resolves a full namespace to its real NuGet package ID
+	// via the NuGet v3 service index. Defaults to &nugetindex.Resolver{}
+	// (nuget.org) when nil.
+	NuGetIndex *nugetindex.Resolver
+	// NuGetResolver resolves a "latest"/empty version sentinel to the
+	// real highest NuGet version. Defaults to &nugetresolver.Resolver{}
+	// (nuget.org) when nil.
+	NuGetResolver *nugetresolver.Resolver
+}
+
+// nuGetIndex returns h.NuGetIndex, defaulting to a plain nuget.org
+// resolver when the caller didn't configure one.
+func (h *DotNetHandler) nuGetIndex() *nugetindex.Resolver {
+	if h.NuGetIndex != nil {
+		return h.NuGetIndex
+	}
+	return &nugetindex.Resolver{}
+}
+
+// nuGetResolver returns h.NuGetResolver, defaulting to a plain nuget.org
+// resolver when the caller didn't configure one.
+func (h *DotNetHandler) nuGetResolver() *nugetresolver.Resolver {
+	if h.NuGetResolver != nil {
+		return h.NuGetResolver
+	}
+	return &nugetresolver.Resolver{}
+}
 
 func (h *DotNetHandler) Name() string {
 	return ".NET"
@@ -40,16 +74,82 @@ func (h *DotNetHandler) Detect(projectDir string) bool {
 }
 
 // Scan parses manifests, scans source files, resolves missing deps via Syft
-func (h *DotNetHandler) Scan(projectDir string) ([]utils.Dependency, error) {
+func (h *DotNetHandler) Scan(projectDir string) ([]utils.Dependency, utils.ScanReport, error) {
+	scanReport := utils.NewScanReport()
+	scanLogger := utils.NewScanLogger(projectDir).With(utils.LogKeyHandler, h.Name())
 	var declaredDeps []utils.Dependency
 
-	// 1. Parse manifests
-	if deps, _ := findAndParseCSProj(projectDir); len(deps) > 0 {
-		declaredDeps = append(declaredDeps, deps...)
+	// 1. Discover every csproj/vbproj in the solution: start from the
+	// projects a .sln lists (or a recursive walk when no .sln exists),
+	// then follow <ProjectReference> edges transitively so a project
+	// missing from the solution file is still picked up. PackageReferences
+	// are merged across every project found this way, deduplicated by
+	// package ID with the higher version winning conflicts.
+	seedProjects, err := findSolutionProjects(projectDir)
+	if err != nil {
+		return nil, scanReport, err
 	}
-	if deps, _ := findAndParseVBProj(projectDir); len(deps) > 0 {
-		declaredDeps = append(declaredDeps, deps...)
+	if len(seedProjects) == 0 {
+		seedProjects, err = findProjectsByWalk(projectDir)
+		if err != nil {
+			return nil, scanReport, err
+		}
+	}
+
+	declaredVersions := make(map[string]string)
+	projectGraph := make(map[string][]string)
+	visited := make(map[string]struct{})
+	queue := append([]string{}, seedProjects...)
+	for _, p := range queue {
+		visited[p] = struct{}{}
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		pdeps, refs, err := parseCSProjWithRefs(p)
+		if err != nil {
+			_ = utils.AppendLog(projectDir, "[DotNetHandler] Warning: failed to parse %s: %v", p, err)
+			continue
+		}
+		projectGraph[p] = refs
+
+		for _, d := range pdeps {
+			existing, ok := declaredVersions[d.ArtifactID]
+			if !ok {
+				declaredVersions[d.ArtifactID] = d.Version
+				declaredDeps = append(declaredDeps, d)
+				continue
+			}
+			if d.Version == existing {
+				continue
+			}
+			if compareNuGetVersions(d.Version, existing) > 0 {
+				for i := range declaredDeps {
+					if declaredDeps[i].ArtifactID == d.ArtifactID {
+						declaredDeps[i] = d
+						break
+					}
+				}
+				declaredVersions[d.ArtifactID] = d.Version
+				_ = utils.AppendLog(projectDir, "[DotNetHandler] Version conflict for %s: %s (from %s) overrides %s", d.ArtifactID, d.Version, filepath.Base(p), existing)
+			} else {
+				_ = utils.AppendLog(projectDir, "[DotNetHandler] Version conflict for %s: keeping %s over %s (from %s)", d.ArtifactID, existing, d.Version, filepath.Base(p))
+			}
+		}
+
+		for _, r := range refs {
+			if _, ok := visited[r]; ok {
+				continue
+			}
+			visited[r] = struct{}{}
+			queue = append(queue, r)
+		}
 	}
+
+	// Legacy manifest formats (pre-SDK-style projects) are solution-root
+	// concepts rather than per-project, so they're still only read from
+	// projectDir itself.
 	if deps, _ := findAndParsePackagesConfig(projectDir); len(deps) > 0 {
 		declaredDeps = append(declaredDeps, deps...)
 	}
@@ -65,60 +165,102 @@ func (h *DotNetHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 		declaredMap[d.ArtifactID] = d
 	}
 
+	// 1b. packages.lock.json, when present, is authoritative: it pins the
+	// exact resolved version NuGet restored for every Direct and
+	// Transitive package, the same way `dotnet restore --locked-mode`
+	// treats it. A declared manifest entry missing from the lock is
+	// unpinned and will be re-resolved (and re-pinned) on next restore.
+	var lockDeps []utils.Dependency
+	lockPresent := false
+	if _, err := os.Stat(filepath.Join(projectDir, "packages.lock.json")); err == nil {
+		lockPresent = true
+		lockDeps, err = findAndParsePackagesLockJSON(projectDir)
+		if err != nil {
+			return nil, scanReport, err
+		}
+		lockSet := make(map[string]struct{}, len(lockDeps))
+		for _, d := range lockDeps {
+			lockSet[d.ArtifactID] = struct{}{}
+		}
+		for _, d := range declaredDeps {
+			if _, ok := lockSet[d.ArtifactID]; !ok {
+				_ = utils.AppendLog(projectDir, "[DotNetHandler] WARNING: %s is declared in the manifest but missing from packages.lock.json; it will be unpinned and re-resolved on next restore", d.ArtifactID)
+			}
+		}
+	}
+
 	// 2. Scan source files (.cs and .vb)
 	imports, err := CollectDotNetImports(projectDir)
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
 	}
 
-	// 3. Parse Syft output
-	syftPath := filepath.Join(projectDir, "syft.json")
-	data, err := os.ReadFile(syftPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read syft.json: %v", err)
-	}
-	syftDeps, err := utils.ParseSyftJSON(data, "dotnet")
-	if err != nil {
-		return nil, err
-	}
+	// 3. Parse Syft output, if a SourceAdapter produced one (see main.go
+	// "-source"); native ingestion leaves no syft.json, and that's fine.
 	syftMap := make(map[string]string)
-	for _, d := range syftDeps {
-		syftMap[d.ArtifactID] = d.Version
+	syftPath := filepath.Join(projectDir, "syft.json")
+	if data, err := os.ReadFile(syftPath); err == nil {
+		syftDeps, err := utils.ParseSyftJSON(data, "dotnet")
+		if err != nil {
+			return nil, scanReport, err
+		}
+		for _, d := range syftDeps {
+			syftMap[d.ArtifactID] = d.Version
+		}
 	}
 
 	// 4. Build final list
 	final := make([]utils.Dependency, 0)
 	seen := make(map[string]struct{})
 
-	// Keep declared
-	for _, d := range declaredDeps {
-		final = append(final, d)
-		seen[d.ArtifactID] = struct{}{}
+	if lockPresent {
+		// The lockfile's pinned versions replace the declared manifest
+		// entries outright; import/Syft reconciliation below only
+		// considers packages the lock doesn't already cover.
+		final = append(final, lockDeps...)
+		for _, d := range lockDeps {
+			seen[d.ArtifactID] = struct{}{}
+		}
+	} else {
+		for _, d := range declaredDeps {
+			final = append(final, d)
+			seen[d.ArtifactID] = struct{}{}
+		}
 	}
 
-	// Add missing imports
-	for _, imp := range imports {
-		if _, ok := seen[imp]; ok {
+	// Add missing imports, resolving each full namespace to its real
+	// NuGet package ID before falling back to the naive first-segment
+	// heuristic (e.g. "Newtonsoft.Json.Linq" -> "Newtonsoft").
+	for _, ns := range imports {
+		pkg, ok := h.nuGetIndex().Resolve(projectDir, ns)
+		if ok {
+			_ = utils.AppendLog(projectDir, "[DotNetHandler] Resolved namespace %s -> NuGet package %s", ns, pkg)
+		} else {
+			pkg = strings.Split(ns, ".")[0]
+			_ = utils.AppendLog(projectDir, "[DotNetHandler] No NuGet index match for %s; falling back to first-segment heuristic %s", ns, pkg)
+		}
+
+		if _, ok := seen[pkg]; ok {
 			continue
 		}
 		version := "latest"
-		if v, ok := syftMap[imp]; ok && v != "" {
+		if v, ok := syftMap[pkg]; ok && v != "" {
 			version = v
 		}
 		dep := utils.Dependency{
 			GroupID:    "nuget",
-			ArtifactID: imp,
+			ArtifactID: pkg,
 			Version:    version,
 			Scope:      "compile",
-			Key:        imp,
+			Key:        pkg,
 		}
 		final = append(final, dep)
-		seen[imp] = struct{}{}
+		seen[pkg] = struct{}{}
 
 		if version == "latest" {
-			_ = utils.AppendLog(projectDir, "[DotNetHandler] Added missing dependency: %s latest (no Syft version found)", imp)
+			_ = utils.AppendLog(projectDir, "[DotNetHandler] Added missing dependency: %s latest (no Syft version found)", pkg)
 		} else {
-			_ = utils.AppendLog(projectDir, "[DotNetHandler] Added missing dependency: %s %s (from Syft)", imp, version)
+			_ = utils.AppendLog(projectDir, "[DotNetHandler] Added missing dependency: %s %s (from Syft)", pkg, version)
 		}
 	}
 
@@ -127,23 +269,146 @@ func (h *DotNetHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 		_ = utils.AppendLog(projectDir, "[DotNetHandler] No manifest found, creating new Recovered.csproj with %d dependencies.", len(imports))
 	}
 
-	return final, nil
+	// 5. A dependency can still carry the "latest"/empty sentinel (an
+	// import Syft never saw, or a project.json entry with no version) at
+	// this point, which dotnet restore rejects outright. Resolve each
+	// against the NuGet registration API before it reaches GenerateRecoveryFile.
+	for i := range final {
+		if final[i].Version != "" && final[i].Version != "latest" {
+			continue
+		}
+		resolved, err := h.nuGetResolver().Resolve(projectDir, final[i].ArtifactID)
+		if err != nil {
+			_ = utils.AppendLog(projectDir, "[DotNetHandler] Could not resolve a real version for %s: %v", final[i].ArtifactID, err)
+			continue
+		}
+		_ = utils.AppendLog(projectDir, "[DotNetHandler] Resolved %s -> %s via NuGet registration API", final[i].ArtifactID, resolved)
+		final[i].Version = resolved
+	}
+
+	scanReport.RecordPhase("declared", len(declaredDeps))
+	scanReport.RecordPhase("scanned", len(imports))
+	scanLogger.Info("parsed manifests", utils.LogKeyPhase, "declared", "deps", len(declaredDeps))
+	scanLogger.Info("scanned imports", utils.LogKeyPhase, "scanned", "deps", len(imports))
+
+	if err := policy.Apply(h.Name(), "nuget", projectDir, final); err != nil {
+		return nil, scanReport, err
+	}
+
+	scanReport.RecordEcosystem("nuget", len(final))
+	scanLogger.Info("scan complete", utils.LogKeyPhase, "ecosystem", utils.LogKeySource, "nuget", "deps", len(final))
+	return final, scanReport, nil
 }
 
-// GenerateRecoveryFile writes updated manifest (backup included)
+// GenerateRecoveryFile rewrites every manifest discovered by Scan's
+// solution/ProjectReference walk in place (each individually backed up)
+// instead of picking a single top-level match. Central Package Management
+// projects (a pre-existing Directory.Packages.props) get PackageReference
+// entries without a Version attribute in each csproj/vbproj, with the
+// resolved versions centralized in a rewritten solution-level
+// Directory.Packages.props instead.
 func (h *DotNetHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir, backupDir string) error {
-	// Pick a manifest to rewrite
+	projects, err := findSolutionProjects(projectDir)
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		projects, err = findProjectsByWalk(projectDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	cpmPath := filepath.Join(projectDir, "Directory.Packages.props")
+	cpmEnabled, _ := detectCPM(projectDir)
+
+	if len(projects) == 0 {
+		return h.generateLegacyRecoveryFile(deps, projectDir, backupDir)
+	}
+
+	// A plain multi-project repo with no existing Directory.Packages.props
+	// is promoted into Central Package Management: versions are deduped
+	// across all its projects into one solution-level file instead of
+	// staying duplicated per csproj.
+	promoting := !cpmEnabled && len(projects) > 1
+	if promoting {
+		_ = utils.AppendLog(projectDir, "[DotNetHandler] Promoting %d-project repo to Central Package Management", len(projects))
+	}
+	writeCPM := cpmEnabled || promoting
+
+	for _, p := range projects {
+		var overrides map[string]string
+		if writeCPM {
+			overrides = versionOverridesFor(p, deps, projectDir)
+		}
+
+		if _, err := os.Stat(p); err == nil {
+			backupPath := filepath.Join(backupDir, filepath.Base(p)+".bak")
+			if err := utils.CopyFile(p, backupPath); err != nil {
+				return fmt.Errorf("failed to backup %s: %v", p, err)
+			}
+			_ = utils.AppendLog(projectDir, "[DotNetHandler] Backed up existing %s", filepath.Base(p))
+		}
+
+		var writeErr error
+		if writeCPM {
+			writeErr = WriteCSProjCPM(p, deps, overrides)
+		} else {
+			writeErr = WriteCSProj(p, deps)
+		}
+		if writeErr != nil {
+			return writeErr
+		}
+		_ = utils.AppendLog(projectDir, "[DotNetHandler] Wrote updated %s", filepath.Base(p))
+	}
+
+	if writeCPM {
+		if _, err := os.Stat(cpmPath); err == nil {
+			backupPath := filepath.Join(backupDir, "Directory.Packages.props.bak")
+			if err := utils.CopyFile(cpmPath, backupPath); err != nil {
+				return fmt.Errorf("failed to backup Directory.Packages.props: %v", err)
+			}
+			_ = utils.AppendLog(projectDir, "[DotNetHandler] Backed up existing Directory.Packages.props")
+		}
+		if err := WriteDirectoryPackagesProps(cpmPath, deps); err != nil {
+			return err
+		}
+		if promoting {
+			_ = utils.AppendLog(projectDir, "[DotNetHandler] Wrote new solution-level Directory.Packages.props (promoted to Central Package Management)")
+		} else {
+			_ = utils.AppendLog(projectDir, "[DotNetHandler] Wrote updated solution-level Directory.Packages.props (Central Package Management)")
+		}
+	}
+
+	// Projects using NuGet's lock mode pin exact versions in
+	// packages.lock.json alongside the csproj/vbproj; keep it in sync so
+	// `dotnet restore --locked-mode` doesn't reject the recovered manifests.
+	lockPath := filepath.Join(projectDir, "packages.lock.json")
+	if _, err := os.Stat(lockPath); err == nil {
+		backupPath := filepath.Join(backupDir, "packages.lock.json.bak")
+		if err := utils.CopyFile(lockPath, backupPath); err != nil {
+			return fmt.Errorf("failed to backup packages.lock.json: %v", err)
+		}
+		if err := WritePackagesLockJSON(lockPath, deps); err != nil {
+			return err
+		}
+		_ = utils.AppendLog(projectDir, "[DotNetHandler] Wrote updated packages.lock.json")
+	}
+	return nil
+}
+
+// generateLegacyRecoveryFile handles the pre-SDK-style manifests
+// (packages.config, project.json) and the no-manifest-at-all fallback,
+// none of which participate in solution/ProjectReference discovery.
+func (h *DotNetHandler) generateLegacyRecoveryFile(deps []utils.Dependency, projectDir, backupDir string) error {
 	var manifestPath string
 	var manifestType string
 	choices := []struct {
 		glob string
 		typ  string
 	}{
-		{"*.csproj", "csproj"},
-		{"*.vbproj", "vbproj"},
 		{"packages.config", "packages"},
 		{"project.json", "projectjson"},
-		{"Directory.Packages.props", "props"},
 	}
 	for _, c := range choices {
 		matches, _ := filepath.Glob(filepath.Join(projectDir, c.glob))
@@ -154,13 +419,11 @@ func (h *DotNetHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir
 		}
 	}
 	if manifestPath == "" {
-		// default fallback
 		manifestPath = filepath.Join(projectDir, "Recovered.csproj")
 		manifestType = "csproj"
 		_ = utils.AppendLog(projectDir, "[DotNetHandler] Creating new Recovered.csproj")
 	}
 
-	// Backup if exists
 	if _, err := os.Stat(manifestPath); err == nil {
 		backupPath := filepath.Join(backupDir, filepath.Base(manifestPath)+".bak")
 		if err := utils.CopyFile(manifestPath, backupPath); err != nil {
@@ -169,17 +432,14 @@ func (h *DotNetHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir
 		_ = utils.AppendLog(projectDir, "[DotNetHandler] Backed up existing %s", filepath.Base(manifestPath))
 	}
 
-	// Write updated manifest
 	var err error
 	switch manifestType {
-	case "csproj", "vbproj":
+	case "csproj":
 		err = WriteCSProj(manifestPath, deps)
 	case "packages":
 		err = WritePackagesConfig(manifestPath, deps)
 	case "projectjson":
 		err = WriteProjectJSON(manifestPath, deps)
-	case "props":
-		err = WriteDirectoryPackagesProps(manifestPath, deps)
 	}
 	if err != nil {
 		return err
@@ -198,27 +458,25 @@ type CSProj struct {
 	ItemGroups []ItemGroup `xml:"ItemGroup"`
 }
 type ItemGroup struct {
-	Packages []Package `xml:"PackageReference"`
+	Packages    []Package          `xml:"PackageReference"`
+	ProjectRefs []ProjectReference `xml:"ProjectReference"`
 }
 type Package struct {
 	Include string `xml:"Include,attr"`
-	Version string `xml:"Version,attr"`
+	Version string `xml:"Version,attr,omitempty"`
+	// VersionOverride pins a project to something other than the
+	// centrally-managed version from Directory.Packages.props. Only set
+	// under Central Package Management, and only when a project actually
+	// disagrees with the central version.
+	VersionOverride string `xml:"VersionOverride,attr,omitempty"`
 }
 
-func findAndParseCSProj(projectDir string) ([]utils.Dependency, error) {
-	matches, _ := filepath.Glob(filepath.Join(projectDir, "*.csproj"))
-	if len(matches) == 0 {
-		return []utils.Dependency{}, nil
-	}
-	return ParseCSProj(matches[0])
-}
-func findAndParseVBProj(projectDir string) ([]utils.Dependency, error) {
-	matches, _ := filepath.Glob(filepath.Join(projectDir, "*.vbproj"))
-	if len(matches) == 0 {
-		return []utils.Dependency{}, nil
-	}
-	return ParseCSProj(matches[0]) // same format as csproj
+// ProjectReference is a <ProjectReference Include="../Other/Other.csproj" />
+// edge to another project in the solution.
+type ProjectReference struct {
+	Include string `xml:"Include,attr"`
 }
+
 func ParseCSProj(csprojPath string) ([]utils.Dependency, error) {
 	if _, err := os.Stat(csprojPath); os.IsNotExist(err) {
 		return []utils.Dependency{}, nil
@@ -263,6 +521,134 @@ func WriteCSProj(csprojPath string, deps []utils.Dependency) error {
 	return os.WriteFile(csprojPath, data, 0644)
 }
 
+// WriteCSProjCPM writes a csproj/vbproj PackageReference list without
+// Version attributes, for a project under Central Package Management
+// where versions live centrally in Directory.Packages.props instead.
+// overrides maps an ArtifactID to a VersionOverride for packages this
+// particular project pins away from the central version; pass nil when
+// every package should simply follow Directory.Packages.props.
+func WriteCSProjCPM(csprojPath string, deps []utils.Dependency, overrides map[string]string) error {
+	var itemGroup ItemGroup
+	for _, d := range deps {
+		itemGroup.Packages = append(itemGroup.Packages, Package{
+			Include:         d.ArtifactID,
+			VersionOverride: overrides[d.ArtifactID],
+		})
+	}
+	proj := CSProj{ItemGroups: []ItemGroup{itemGroup}}
+	data, err := xml.MarshalIndent(proj, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal XML: %v", err)
+	}
+	xmlHeader := []byte(xml.Header)
+	data = append(xmlHeader, data...)
+	return os.WriteFile(csprojPath, data, 0644)
+}
+
+// parseCSProjWithRefs parses a csproj/vbproj's PackageReferences (as
+// utils.Dependency, same as ParseCSProj) alongside its ProjectReferences,
+// resolved to absolute paths of the referenced project files so callers
+// can walk the reference graph.
+func parseCSProjWithRefs(csprojPath string) ([]utils.Dependency, []string, error) {
+	if _, err := os.Stat(csprojPath); os.IsNotExist(err) {
+		return []utils.Dependency{}, nil, nil
+	}
+	data, err := os.ReadFile(csprojPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %v", csprojPath, err)
+	}
+	var proj CSProj
+	if err := xml.Unmarshal(data, &proj); err != nil {
+		return nil, nil, fmt.Errorf("invalid XML in %s: %v", csprojPath, err)
+	}
+
+	projDir := filepath.Dir(csprojPath)
+	var deps []utils.Dependency
+	var refs []string
+	for _, ig := range proj.ItemGroups {
+		for _, p := range ig.Packages {
+			deps = append(deps, utils.Dependency{
+				GroupID:    "nuget",
+				ArtifactID: p.Include,
+				Version:    p.Version,
+				Scope:      "compile",
+				Key:        p.Include,
+			})
+		}
+		for _, r := range ig.ProjectRefs {
+			rel := filepath.FromSlash(strings.ReplaceAll(r.Include, `\`, "/"))
+			refs = append(refs, filepath.Clean(filepath.Join(projDir, rel)))
+		}
+	}
+	return deps, refs, nil
+}
+
+// compareNuGetVersions orders two NuGet version strings, comparing
+// numeric segments (the "1.2.3" part) before a "-" prerelease suffix, so
+// "2.0.0" > "1.9.9" and a release beats a prerelease of the same number
+// (e.g. "1.0.0" > "1.0.0-beta1"). It's a pragmatic ordering for picking
+// the winner of a PackageReference version conflict, not a full SemVer
+// precedence implementation.
+func compareNuGetVersions(a, b string) int {
+	aNum, aSuffix, _ := strings.Cut(a, "-")
+	bNum, bSuffix, _ := strings.Cut(b, "-")
+	aParts := strings.Split(aNum, ".")
+	bParts := strings.Split(bNum, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var av, bv int
+		if i < len(aParts) {
+			av, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bv, _ = strconv.Atoi(bParts[i])
+		}
+		if av != bv {
+			if av > bv {
+				return 1
+			}
+			return -1
+		}
+	}
+	if aSuffix == "" && bSuffix != "" {
+		return 1
+	}
+	if aSuffix != "" && bSuffix == "" {
+		return -1
+	}
+	return strings.Compare(aSuffix, bSuffix)
+}
+
+// versionOverridesFor compares csprojPath's own pre-rewrite PackageReference
+// versions against the centrally-resolved deps, returning a VersionOverride
+// for any package this project still pins to something different. Each
+// override is logged so a promotion (or an existing CPM repo) leaves an
+// audit trail of which projects diverge from the central version.
+func versionOverridesFor(csprojPath string, central []utils.Dependency, projectDir string) map[string]string {
+	existing, _, err := parseCSProjWithRefs(csprojPath)
+	if err != nil || len(existing) == 0 {
+		return nil
+	}
+	centralVersions := make(map[string]string, len(central))
+	for _, d := range central {
+		centralVersions[d.ArtifactID] = d.Version
+	}
+	overrides := make(map[string]string)
+	for _, d := range existing {
+		if d.Version == "" {
+			continue
+		}
+		if cv, ok := centralVersions[d.ArtifactID]; ok && cv != d.Version {
+			overrides[d.ArtifactID] = d.Version
+			_ = utils.AppendLog(projectDir, "[DotNetHandler] %s in %s keeps VersionOverride=%s (central version is %s)",
+				d.ArtifactID, filepath.Base(csprojPath), d.Version, cv)
+		}
+	}
+	if len(overrides) == 0 {
+		return nil
+	}
+	return overrides
+}
+
 // ====== packages.config ======
 type PackagesConfig struct {
 	XMLName xml.Name            `xml:"packages"`
@@ -362,8 +748,17 @@ func WriteProjectJSON(path string, deps []utils.Dependency) error {
 
 // ====== Directory.Packages.props ======
 type DirectoryPackagesProps struct {
-	XMLName xml.Name                   `xml:"Project"`
-	ItemGrp DirectoryPackagesItemGroup `xml:"ItemGroup"`
+	XMLName xml.Name                       `xml:"Project"`
+	PropGrp DirectoryPackagesPropertyGroup `xml:"PropertyGroup"`
+	ItemGrp DirectoryPackagesItemGroup     `xml:"ItemGroup"`
+}
+
+// DirectoryPackagesPropertyGroup holds the MSBuild properties that turn
+// Central Package Management on and configure it; both are plain element
+// text ("true"/"false"), not attributes.
+type DirectoryPackagesPropertyGroup struct {
+	ManagePackageVersionsCentrally         string `xml:"ManagePackageVersionsCentrally,omitempty"`
+	CentralPackageTransitivePinningEnabled string `xml:"CentralPackageTransitivePinningEnabled,omitempty"`
 }
 type DirectoryPackagesItemGroup struct {
 	Packages []DirectoryPackageVersion `xml:"PackageVersion"`
@@ -398,7 +793,37 @@ func findAndParseDirectoryPackagesProps(projectDir string) ([]utils.Dependency,
 	}
 	return deps, nil
 }
+
+// detectCPM reports whether projectDir's Directory.Packages.props (if any)
+// enables Central Package Management, and whether it also enables
+// transitive pinning. A Directory.Packages.props without an explicit
+// ManagePackageVersionsCentrally is treated as CPM-enabled, matching how
+// real-world repos almost always author the file; an explicit "false"
+// is honored.
+func detectCPM(projectDir string) (enabled bool, transitivePinning bool) {
+	data, err := os.ReadFile(filepath.Join(projectDir, "Directory.Packages.props"))
+	if err != nil {
+		return false, false
+	}
+	var props DirectoryPackagesProps
+	if err := xml.Unmarshal(data, &props); err != nil {
+		return true, false
+	}
+	prop := strings.TrimSpace(props.PropGrp.ManagePackageVersionsCentrally)
+	enabled = prop == "" || strings.EqualFold(prop, "true")
+	transitivePinning = strings.EqualFold(strings.TrimSpace(props.PropGrp.CentralPackageTransitivePinningEnabled), "true")
+	return enabled, transitivePinning
+}
+
 func WriteDirectoryPackagesProps(path string, deps []utils.Dependency) error {
+	transitivePinning := "true"
+	if data, err := os.ReadFile(path); err == nil {
+		var existing DirectoryPackagesProps
+		if err := xml.Unmarshal(data, &existing); err == nil && existing.PropGrp.CentralPackageTransitivePinningEnabled != "" {
+			transitivePinning = existing.PropGrp.CentralPackageTransitivePinningEnabled
+		}
+	}
+
 	var itemGroup DirectoryPackagesItemGroup
 	for _, d := range deps {
 		itemGroup.Packages = append(itemGroup.Packages, DirectoryPackageVersion{
@@ -406,7 +831,13 @@ func WriteDirectoryPackagesProps(path string, deps []utils.Dependency) error {
 			Version: d.Version,
 		})
 	}
-	props := DirectoryPackagesProps{ItemGrp: itemGroup}
+	props := DirectoryPackagesProps{
+		PropGrp: DirectoryPackagesPropertyGroup{
+			ManagePackageVersionsCentrally:         "true",
+			CentralPackageTransitivePinningEnabled: transitivePinning,
+		},
+		ItemGrp: itemGroup,
+	}
 	data, err := xml.MarshalIndent(props, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal Directory.Packages.props: %v", err)
@@ -416,7 +847,178 @@ func WriteDirectoryPackagesProps(path string, deps []utils.Dependency) error {
 	return os.WriteFile(path, data, 0644)
 }
 
+// ====== packages.lock.json ======
+// PackagesLockJSON models NuGet's lock file (schema version 1 or 2):
+// dependencies keyed by target framework moniker (e.g. "net8.0"), each
+// entry keyed by package ID.
+type PackagesLockJSON struct {
+	Version      int                               `json:"version"`
+	Dependencies map[string]map[string]LockPackage `json:"dependencies"`
+}
+
+// LockPackage is one pinned package entry under a TFM in packages.lock.json.
+type LockPackage struct {
+	Type        string `json:"type"` // "Direct" or "Transitive"
+	Requested   string `json:"requested,omitempty"`
+	Resolved    string `json:"resolved"`
+	ContentHash string `json:"contentHash,omitempty"`
+}
+
+// findAndParsePackagesLockJSON reads packages.lock.json and flattens every
+// Direct and Transitive package across all TFMs into Dependencies pinned
+// to their resolved version. A package appearing under multiple TFMs is
+// reported once, keeping the first resolved version encountered. Scope is
+// set to "compile" for Direct packages and "transitive" for Transitive
+// ones, so WritePackagesLockJSON can round-trip the distinction.
+func findAndParsePackagesLockJSON(projectDir string) ([]utils.Dependency, error) {
+	path := filepath.Join(projectDir, "packages.lock.json")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []utils.Dependency{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lock PackagesLockJSON
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("invalid JSON in packages.lock.json: %v", err)
+	}
+
+	seen := make(map[string]struct{})
+	var deps []utils.Dependency
+	for _, tfm := range lock.Dependencies {
+		for id, pkg := range tfm {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			scope := "compile"
+			if pkg.Type == "Transitive" {
+				scope = "transitive"
+			}
+			deps = append(deps, utils.Dependency{
+				GroupID:    "nuget",
+				ArtifactID: id,
+				Version:    pkg.Resolved,
+				Scope:      scope,
+				Key:        id,
+			})
+		}
+	}
+	return deps, nil
+}
+
+// WritePackagesLockJSON writes an updated packages.lock.json pinning deps
+// to their recovered versions, so `dotnet restore --locked-mode` succeeds
+// against the rewritten csproj. It reuses the TFM keys and schema version
+// of the existing lock file when present, defaulting to a single "net8.0"
+// TFM for a brand new one. ContentHash is left blank since recovery has no
+// package binary to hash against; `dotnet restore` backfills it.
+func WritePackagesLockJSON(lockPath string, deps []utils.Dependency) error {
+	version := 1
+	tfms := []string{"net8.0"}
+	if data, err := os.ReadFile(lockPath); err == nil {
+		var existing PackagesLockJSON
+		if err := json.Unmarshal(data, &existing); err == nil && len(existing.Dependencies) > 0 {
+			version = existing.Version
+			tfms = tfms[:0]
+			for tfm := range existing.Dependencies {
+				tfms = append(tfms, tfm)
+			}
+			sort.Strings(tfms)
+		}
+	}
+
+	lock := PackagesLockJSON{Version: version, Dependencies: map[string]map[string]LockPackage{}}
+	for _, tfm := range tfms {
+		pkgs := make(map[string]LockPackage, len(deps))
+		for _, d := range deps {
+			if d.ArtifactID == "" || d.Version == "" {
+				continue
+			}
+			typ := "Direct"
+			if d.Scope == "transitive" {
+				typ = "Transitive"
+			}
+			pkgs[d.ArtifactID] = LockPackage{Type: typ, Resolved: d.Version}
+		}
+		lock.Dependencies[tfm] = pkgs
+	}
+
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal packages.lock.json: %v", err)
+	}
+	return os.WriteFile(lockPath, data, 0644)
+}
+
+// ====== Solution / multi-project discovery ======
+
+// slnProjectLineRegex matches a .sln project declaration line, e.g.
+// `Project("{FAE04EC0-...}") = "Foo", "src\Foo\Foo.csproj", "{GUID2}"`.
+var slnProjectLineRegex = regexp.MustCompile(`^Project\("\{[0-9A-Fa-f-]+\}"\)\s*=\s*"[^"]*",\s*"([^"]+)",\s*"\{[0-9A-Fa-f-]+\}"`)
+
+// findSolutionProjects parses every *.sln in projectDir and returns the
+// absolute paths of the csproj/vbproj files it references. Returns an
+// empty slice (not an error) when no .sln is present, so callers fall
+// back to findProjectsByWalk.
+func findSolutionProjects(projectDir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(projectDir, "*.sln"))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	var projects []string
+	for _, slnPath := range matches {
+		data, err := os.ReadFile(slnPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", slnPath, err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			m := slnProjectLineRegex.FindStringSubmatch(strings.TrimSpace(line))
+			if m == nil {
+				continue
+			}
+			rel := m[1]
+			if !(strings.HasSuffix(rel, ".csproj") || strings.HasSuffix(rel, ".vbproj")) {
+				continue
+			}
+			abs := filepath.Clean(filepath.Join(projectDir, filepath.FromSlash(strings.ReplaceAll(rel, `\`, "/"))))
+			if _, ok := seen[abs]; ok {
+				continue
+			}
+			seen[abs] = struct{}{}
+			projects = append(projects, abs)
+		}
+	}
+	return projects, nil
+}
+
+// findProjectsByWalk recursively finds every csproj/vbproj under
+// projectDir, for repos with no solution file to enumerate projects from.
+func findProjectsByWalk(projectDir string) ([]string, error) {
+	var projects []string
+	err := filepath.WalkDir(projectDir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if strings.HasSuffix(p, ".csproj") || strings.HasSuffix(p, ".vbproj") {
+			projects = append(projects, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
 // ====== Collect Imports (.cs + .vb) ======
+// CollectDotNetImports returns every distinct full namespace referenced by
+// a "using X;" (C#) or "Imports X" (VB) statement, e.g. "Newtonsoft.Json.Linq".
+// Reducing a namespace to a NuGet package ID is the caller's job - see
+// DotNetHandler.Scan and nugetindex.Resolver.
 func CollectDotNetImports(projectDir string) ([]string, error) {
 	usingRegex := regexp.MustCompile(`^using\s+([\w\.]+);`)
 	importsRegex := regexp.MustCompile(`^Imports\s+([\w\.]+)`)
@@ -437,12 +1039,10 @@ func CollectDotNetImports(projectDir string) ([]string, error) {
 		for _, line := range lines {
 			line = strings.TrimSpace(line)
 			if m := usingRegex.FindStringSubmatch(line); len(m) == 2 {
-				ns := strings.Split(m[1], ".")[0]
-				imports[ns] = struct{}{}
+				imports[m[1]] = struct{}{}
 			}
 			if m := importsRegex.FindStringSubmatch(line); len(m) == 2 {
-				ns := strings.Split(m[1], ".")[0]
-				imports[ns] = struct{}{}
+				imports[m[1]] = struct{}{}
 			}
 		}
 		return nil
@@ -456,3 +1056,15 @@ func CollectDotNetImports(projectDir string) ([]string, error) {
 	}
 	return list, nil
 }
+
+// EmitSBOM writes deps to w as an SBOM in the given format ("cyclonedx" or
+// "spdx"); see utils/sbom.
+func (h *DotNetHandler) EmitSBOM(deps []utils.Dependency, w io.Writer, format string) error {
+	return sbom.Emit(deps, w, format)
+}
+
+// ExportSBOM writes deps to outPath as an SBOM in the given format
+// ("cyclonedx" or "spdx"); see utils/sbom.ExportToFile.
+func (h *DotNetHandler) ExportSBOM(deps []utils.Dependency, format, outPath, projectDir string) error {
+	return sbom.ExportToFile(deps, format, outPath, projectDir)
+}
@@ -0,0 +1,220 @@
+// Package nugetindex resolves a .NET namespace (e.g. "Newtonsoft.Json.Linq")
+// to the NuGet package ID that actually ships it, by querying the NuGet v3
+// service index for its SearchQueryService and searching progressively
+// shorter namespace prefixes. Results are cached per-project so repeat
+// scans work offline.
+package nugetindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// offlineMode disables every network call this package makes, for
+// deterministic CI runs. Set ORT_RECOVERY_OFFLINE=1 to enable it.
+var offlineMode = os.Getenv("ORT_RECOVERY_OFFLINE") == "1"
+
+// cacheDir and cacheFile locate the per-project namespace->package cache,
+// keeping resolver state alongside a project's recovery artifacts rather
+// than machine-wide.
+const cacheDir = ".ort-recovery"
+const cacheFile = "nuget-namespace-cache.json"
+
+// Resolver resolves namespaces against a NuGet v3 service index.
+type Resolver struct {
+	// ServiceIndexURL defaults to https://api.nuget.org/v3/index.json.
+	ServiceIndexURL string
+	// HTTPClient defaults to http.DefaultClient when nil.
+	HTTPClient *http.Client
+
+	// searchQueryServiceURL memoizes the SearchQueryService resource
+	// discovered from the service index, so repeated Resolve calls in
+	// the same scan only fetch the index once.
+	searchQueryServiceURL string
+}
+
+func (r *Resolver) serviceIndexURL() string {
+	if r.ServiceIndexURL != "" {
+		return r.ServiceIndexURL
+	}
+	return "https://api.nuget.org/v3/index.json"
+}
+
+func (r *Resolver) client() *http.Client {
+	if r.HTTPClient != nil {
+		return r.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+type serviceIndexResponse struct {
+	Resources []struct {
+		ID   string `json:"@id"`
+		Type string `json:"@type"`
+	} `json:"resources"`
+}
+
+// searchService discovers the SearchQueryService URL from the service
+// index, memoizing it on the Resolver for subsequent lookups.
+func (r *Resolver) searchService() (string, error) {
+	if r.searchQueryServiceURL != "" {
+		return r.searchQueryServiceURL, nil
+	}
+	var idx serviceIndexResponse
+	if err := getJSON(r.client(), r.serviceIndexURL(), &idx); err != nil {
+		return "", err
+	}
+	for _, res := range idx.Resources {
+		if strings.HasPrefix(res.Type, "SearchQueryService") {
+			r.searchQueryServiceURL = res.ID
+			return res.ID, nil
+		}
+	}
+	return "", fmt.Errorf("no SearchQueryService resource in %s", r.serviceIndexURL())
+}
+
+type searchResponse struct {
+	Data []struct {
+		ID             string   `json:"id"`
+		Title          string   `json:"title"`
+		Tags           []string `json:"tags"`
+		TotalDownloads int64    `json:"totalDownloads"`
+	} `json:"data"`
+}
+
+// bestMatch searches for prefix and returns the ID of the best-matching
+// package: one whose id equals prefix case-insensitively, or whose title
+// or tags contain prefix, breaking ties by total download count.
+func (r *Resolver) bestMatch(prefix string) (string, error) {
+	searchURL, err := r.searchService()
+	if err != nil {
+		return "", err
+	}
+	values := url.Values{"q": {prefix}, "take": {"20"}, "prerelease": {"false"}}
+	var resp searchResponse
+	if err := getJSON(r.client(), searchURL+"?"+values.Encode(), &resp); err != nil {
+		return "", err
+	}
+
+	lowerPrefix := strings.ToLower(prefix)
+	var bestID string
+	var bestDownloads int64 = -1
+	for _, pkg := range resp.Data {
+		matches := strings.EqualFold(pkg.ID, prefix)
+		if !matches && strings.Contains(strings.ToLower(pkg.Title), lowerPrefix) {
+			matches = true
+		}
+		if !matches {
+			for _, tag := range pkg.Tags {
+				if strings.Contains(strings.ToLower(tag), lowerPrefix) {
+					matches = true
+					break
+				}
+			}
+		}
+		if !matches {
+			continue
+		}
+		if pkg.TotalDownloads > bestDownloads {
+			bestDownloads = pkg.TotalDownloads
+			bestID = pkg.ID
+		}
+	}
+	return bestID, nil
+}
+
+// Resolve maps namespace to the NuGet package ID that ships it, walking
+// prefixes longest-first (e.g. "Newtonsoft.Json.Linq" -> "Newtonsoft.Json"
+// -> "Newtonsoft") until one resolves. It returns false when offline mode
+// is set or no prefix resolves to a package. Results, including misses,
+// are cached under projectDir keyed by the full namespace so later
+// "using" statements from the same namespace skip the network entirely.
+func (r *Resolver) Resolve(projectDir, namespace string) (string, bool) {
+	if offlineMode || namespace == "" {
+		return "", false
+	}
+
+	cache := loadCache(projectDir)
+	if id, ok := cache.Namespaces[namespace]; ok {
+		return id, id != ""
+	}
+
+	parts := strings.Split(namespace, ".")
+	for i := len(parts); i >= 1; i-- {
+		prefix := strings.Join(parts[:i], ".")
+		id, err := r.bestMatch(prefix)
+		if err != nil {
+			continue
+		}
+		if id != "" {
+			cache.Namespaces[namespace] = id
+			cache.save(projectDir)
+			return id, true
+		}
+	}
+
+	cache.Namespaces[namespace] = ""
+	cache.save(projectDir)
+	return "", false
+}
+
+// ---------------------------
+// On-disk namespace cache
+// ---------------------------
+
+type namespaceCache struct {
+	Namespaces map[string]string `json:"namespaces"`
+}
+
+func loadCache(projectDir string) *namespaceCache {
+	c := &namespaceCache{Namespaces: map[string]string{}}
+	data, err := os.ReadFile(filepath.Join(projectDir, cacheDir, cacheFile))
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return &namespaceCache{Namespaces: map[string]string{}}
+	}
+	if c.Namespaces == nil {
+		c.Namespaces = map[string]string{}
+	}
+	return c
+}
+
+func (c *namespaceCache) save(projectDir string) {
+	dir := filepath.Join(projectDir, cacheDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(dir, cacheFile), data, 0644)
+}
+
+// getJSON performs an HTTP GET and decodes a JSON response body into out.
+func getJSON(client *http.Client, requestURL string, out interface{}) error {
+	resp, err := client.Get(requestURL)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %v", requestURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", requestURL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response from %s: %v", requestURL, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %v", requestURL, err)
+	}
+	return nil
+}
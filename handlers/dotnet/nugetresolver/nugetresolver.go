@@ -0,0 +1,333 @@
+// Package nugetresolver replaces the "latest"/empty version sentinel
+// Scan writes when Syft has no match for a dependency, querying NuGet's
+// flatcontainer API (nuget.org by default, or any feed configured in a
+// project's nuget.config) for the real highest version instead.
+package nugetresolver
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// allowPrerelease gates falling back to the highest prerelease version
+// when a package has no stable release at all. Set
+// ORT_RECOVERY_ALLOW_PRERELEASE=1, or call SetAllowPrerelease to wire it
+// to a CLI flag (mirrors javahandler.SetOnline).
+var allowPrerelease = os.Getenv("ORT_RECOVERY_ALLOW_PRERELEASE") == "1"
+
+// SetAllowPrerelease overrides the process-wide prerelease flag.
+func SetAllowPrerelease(v bool) { allowPrerelease = v }
+
+const defaultFlatContainer = "https://api.nuget.org/v3-flatcontainer/"
+
+// cacheFile caches resolved versions machine-wide (unlike nugetindex's
+// per-project namespace cache) since "what's the latest version of X
+// today" doesn't depend on which project asked.
+const cacheFile = "nuget-versions.json"
+
+// Resolver resolves a NuGet package ID to its latest version.
+type Resolver struct {
+	// HTTPClient defaults to http.DefaultClient when nil.
+	HTTPClient *http.Client
+}
+
+func (r *Resolver) client() *http.Client {
+	if r.HTTPClient != nil {
+		return r.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// Source is one <packageSources><add> entry, with basic-auth credentials
+// merged in from a matching <packageSourceCredentials> block.
+type Source struct {
+	Name     string
+	URL      string
+	Username string
+	Password string
+}
+
+// Resolve returns the highest stable version of id (falling back to the
+// highest prerelease when allowPrerelease is set and no stable version
+// exists), querying projectDir's configured NuGet feeds, nuget.org last.
+// Results are cached in ~/.cache/ort-recovery/nuget-versions.json keyed
+// by "id@YYYY-MM-DD" so repeated runs on the same day are deterministic
+// and need no network access.
+func (r *Resolver) Resolve(projectDir, id string) (string, error) {
+	lowerID := strings.ToLower(id)
+	cache := loadCache()
+	key := lowerID + "@" + time.Now().Format("2006-01-02")
+	if v, ok := cache.Versions[key]; ok {
+		return v, nil
+	}
+
+	var lastErr error
+	for _, src := range loadSources(projectDir) {
+		versions, err := r.fetchVersions(src, lowerID)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if v, ok := bestVersion(versions); ok {
+			cache.Versions[key] = v
+			cache.save()
+			return v, nil
+		}
+	}
+	if lastErr != nil {
+		return "", fmt.Errorf("failed to resolve a version for %s: %v", id, lastErr)
+	}
+	return "", fmt.Errorf("no versions found for %s", id)
+}
+
+type flatContainerIndex struct {
+	Versions []string `json:"versions"`
+}
+
+type serviceIndexResponse struct {
+	Resources []struct {
+		ID   string `json:"@id"`
+		Type string `json:"@type"`
+	} `json:"resources"`
+}
+
+// fetchVersions lists id's published versions from src. src.URL may
+// already be a flatcontainer base (nuget.org's default), or a v3 service
+// index URL (the usual shape of a nuget.config source) from which the
+// PackageBaseAddress resource is discovered first.
+func (r *Resolver) fetchVersions(src Source, lowerID string) ([]string, error) {
+	base := src.URL
+	if strings.HasSuffix(strings.TrimSuffix(base, "/"), "index.json") {
+		var idx serviceIndexResponse
+		if err := r.getJSON(src, base, &idx); err != nil {
+			return nil, err
+		}
+		base = ""
+		for _, res := range idx.Resources {
+			if strings.HasPrefix(res.Type, "PackageBaseAddress") {
+				base = res.ID
+				break
+			}
+		}
+		if base == "" {
+			return nil, fmt.Errorf("no PackageBaseAddress resource in %s", src.URL)
+		}
+	}
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	var out flatContainerIndex
+	if err := r.getJSON(src, base+lowerID+"/index.json", &out); err != nil {
+		return nil, err
+	}
+	return out.Versions, nil
+}
+
+func (r *Resolver) getJSON(src Source, requestURL string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+	if src.Username != "" {
+		req.SetBasicAuth(src.Username, src.Password)
+	}
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %v", requestURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", requestURL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response from %s: %v", requestURL, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %v", requestURL, err)
+	}
+	return nil
+}
+
+// bestVersion picks the highest stable version in versions, or the
+// highest prerelease when allowPrerelease is set and none is stable.
+func bestVersion(versions []string) (string, bool) {
+	var stable, prerelease []string
+	for _, v := range versions {
+		if strings.Contains(v, "-") {
+			prerelease = append(prerelease, v)
+		} else {
+			stable = append(stable, v)
+		}
+	}
+	if len(stable) > 0 {
+		sort.Slice(stable, func(i, j int) bool { return compareVersions(stable[i], stable[j]) < 0 })
+		return stable[len(stable)-1], true
+	}
+	if allowPrerelease && len(prerelease) > 0 {
+		sort.Slice(prerelease, func(i, j int) bool { return compareVersions(prerelease[i], prerelease[j]) < 0 })
+		return prerelease[len(prerelease)-1], true
+	}
+	return "", false
+}
+
+// compareVersions orders two NuGet version strings the same pragmatic
+// way dotnet.compareNuGetVersions does (numeric segments before a "-"
+// prerelease suffix, release beating prerelease of the same number);
+// duplicated here since the two packages share no version-compare helper.
+func compareVersions(a, b string) int {
+	aNum, aSuffix, _ := strings.Cut(a, "-")
+	bNum, bSuffix, _ := strings.Cut(b, "-")
+	aParts := strings.Split(aNum, ".")
+	bParts := strings.Split(bNum, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var av, bv int
+		if i < len(aParts) {
+			av, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bv, _ = strconv.Atoi(bParts[i])
+		}
+		if av != bv {
+			if av > bv {
+				return 1
+			}
+			return -1
+		}
+	}
+	if aSuffix == "" && bSuffix != "" {
+		return 1
+	}
+	if aSuffix != "" && bSuffix == "" {
+		return -1
+	}
+	return strings.Compare(aSuffix, bSuffix)
+}
+
+// ---------------------------
+// nuget.config sources
+// ---------------------------
+
+type nugetConfigXML struct {
+	XMLName        xml.Name `xml:"configuration"`
+	PackageSources struct {
+		Add []struct {
+			Key   string `xml:"key,attr"`
+			Value string `xml:"value,attr"`
+		} `xml:"add"`
+	} `xml:"packageSources"`
+	PackageSourceCredentials struct {
+		Sources []credentialSource `xml:",any"`
+	} `xml:"packageSourceCredentials"`
+}
+
+// credentialSource is a <packageSourceCredentials> child element, whose
+// tag name is the source's key (e.g. <PrivateFeed>), not a fixed name.
+type credentialSource struct {
+	XMLName xml.Name
+	Add     []struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:"value,attr"`
+	} `xml:"add"`
+}
+
+// loadSources reads projectDir/nuget.config for <packageSources>,
+// attaching basic-auth credentials from <packageSourceCredentials>, and
+// always appends the public nuget.org flatcontainer feed last so a
+// project with only private feeds configured still falls back to it.
+func loadSources(projectDir string) []Source {
+	nugetOrg := Source{Name: "nuget.org", URL: defaultFlatContainer}
+
+	data, err := os.ReadFile(filepath.Join(projectDir, "nuget.config"))
+	if err != nil {
+		return []Source{nugetOrg}
+	}
+	var cfg nugetConfigXML
+	if err := xml.Unmarshal(data, &cfg); err != nil {
+		return []Source{nugetOrg}
+	}
+
+	creds := make(map[string]struct{ username, password string })
+	for _, block := range cfg.PackageSourceCredentials.Sources {
+		var username, password string
+		for _, a := range block.Add {
+			switch a.Key {
+			case "Username":
+				username = a.Value
+			case "ClearTextPassword", "Password":
+				password = a.Value
+			}
+		}
+		creds[block.XMLName.Local] = struct{ username, password string }{username, password}
+	}
+
+	sources := make([]Source, 0, len(cfg.PackageSources.Add)+1)
+	for _, add := range cfg.PackageSources.Add {
+		src := Source{Name: add.Key, URL: add.Value}
+		if cred, ok := creds[add.Key]; ok {
+			src.Username = cred.username
+			src.Password = cred.password
+		}
+		sources = append(sources, src)
+	}
+	return append(sources, nugetOrg)
+}
+
+// ---------------------------
+// On-disk version cache
+// ---------------------------
+
+type versionCache struct {
+	Versions map[string]string `json:"versions"`
+}
+
+func cachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ort-recovery", cacheFile), nil
+}
+
+func loadCache() *versionCache {
+	c := &versionCache{Versions: map[string]string{}}
+	path, err := cachePath()
+	if err != nil {
+		return c
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return &versionCache{Versions: map[string]string{}}
+	}
+	if c.Versions == nil {
+		c.Versions = map[string]string{}
+	}
+	return c
+}
+
+func (c *versionCache) save() {
+	path, err := cachePath()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0644)
+}
@@ -1,8 +1,8 @@
 package gohandler
 
 import (
-	"bufio"
 	"fmt"
+	"go/build"
 	"go/parser"
 	"go/token"
 	"os"
@@ -10,9 +10,43 @@ import (
 	"path/filepath"
 	"strings"
 
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
 	"ort-recovery/utils"
+	"ort-recovery/utils/policy"
 )
 
+// goBuildMatrix is the set of GOOS/GOARCH pairs a file is checked against
+// when deciding whether it's ever compiled. A file needs to match only one
+// combination to be scanned; this keeps recovery working for cross-platform
+// repos without requiring the host's own GOOS/GOARCH to line up.
+var goBuildMatrix = []struct{ GOOS, GOARCH string }{
+	{"linux", "amd64"},
+	{"linux", "arm64"},
+	{"darwin", "amd64"},
+	{"darwin", "arm64"},
+	{"windows", "amd64"},
+}
+
+// matchesAnyPlatform reports whether dir/name would be included in the
+// build for at least one entry of goBuildMatrix, honoring filename
+// suffixes (_linux.go, _amd64.go, ...), //go:build lines, and legacy
+// "// +build" comments via go/build's own constraint evaluation.
+func matchesAnyPlatform(dir, name string) bool {
+	for _, combo := range goBuildMatrix {
+		ctx := build.Default
+		ctx.GOOS = combo.GOOS
+		ctx.GOARCH = combo.GOARCH
+		ctx.UseAllFiles = false
+		if match, err := ctx.MatchFile(dir, name); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
 // ---------------------------
 // Go Handler
 // ---------------------------
@@ -60,7 +94,9 @@ func (h *GoHandler) Detect(projectDir string) bool {
 }
 
 // Scan parses go.mod, scans .go imports, and uses Syft only for metadata
-func (h *GoHandler) Scan(projectDir string) ([]utils.Dependency, error) {
+func (h *GoHandler) Scan(projectDir string) ([]utils.Dependency, utils.ScanReport, error) {
+	scanReport := utils.NewScanReport()
+	scanLogger := utils.NewScanLogger(projectDir).With(utils.LogKeyHandler, h.Name())
 	utils.AppendLog(projectDir, "[GoHandler][Scan] Start scan: %s", projectDir)
 
 	modPath := filepath.Join(projectDir, "go.mod")
@@ -73,7 +109,7 @@ func (h *GoHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 		parsedDeps, parsedModule, perr := ParseGoMod(modPath)
 		if perr != nil {
 			utils.AppendLog(projectDir, "[GoHandler][Scan] Error parsing go.mod: %v", perr)
-			return nil, perr
+			return nil, scanReport, perr
 		}
 		declaredDeps = parsedDeps
 		moduleName = parsedModule
@@ -82,6 +118,65 @@ func (h *GoHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 		utils.AppendLog(projectDir, "[GoHandler][Scan] go.mod not found; will initialize later if required")
 	}
 
+	// 1b. go.work multi-module workspaces resolve imports across every
+	// module they list, so merge each used module's go.mod requirements
+	// into the same declared set before scanning for missing imports.
+	workPath := filepath.Join(projectDir, "go.work")
+	if _, err := os.Stat(workPath); err == nil {
+		utils.AppendLog(projectDir, "[GoHandler][Scan] go.work exists, parsing...")
+		useDirs, werr := ParseGoWork(workPath)
+		if werr != nil {
+			utils.AppendLog(projectDir, "[GoHandler][Scan] Error parsing go.work: %v", werr)
+			return nil, scanReport, werr
+		}
+		declaredVersions := make(map[string]string, len(declaredDeps))
+		for _, d := range declaredDeps {
+			declaredVersions[d.ArtifactID] = d.Version
+		}
+		for _, dir := range useDirs {
+			useDeps, _, perr := ParseGoMod(filepath.Join(dir, "go.mod"))
+			if perr != nil {
+				utils.AppendLog(projectDir, "[GoHandler][Scan] Warning: failed to parse workspace module %s: %v", dir, perr)
+				continue
+			}
+			for _, d := range useDeps {
+				existing, ok := declaredVersions[d.ArtifactID]
+				if !ok {
+					declaredVersions[d.ArtifactID] = d.Version
+					declaredDeps = append(declaredDeps, d)
+					continue
+				}
+				if d.Version == existing || !semver.IsValid(d.Version) || !semver.IsValid(existing) {
+					continue
+				}
+				if semver.Compare(d.Version, existing) > 0 {
+					for i := range declaredDeps {
+						if declaredDeps[i].ArtifactID == d.ArtifactID {
+							declaredDeps[i] = d
+							break
+						}
+					}
+					declaredVersions[d.ArtifactID] = d.Version
+					utils.AppendLog(projectDir, "[GoHandler][Scan] Version conflict for %s: %s (from %s) overrides %s", d.ArtifactID, d.Version, filepath.Base(dir), existing)
+				} else {
+					utils.AppendLog(projectDir, "[GoHandler][Scan] Version conflict for %s: keeping %s over %s (from %s)", d.ArtifactID, existing, d.Version, filepath.Base(dir))
+				}
+			}
+		}
+		utils.AppendLog(projectDir, "[GoHandler][Scan] Merged workspace modules; declared deps now %d", len(declaredDeps))
+	}
+
+	// 1c. Collapse declared requirements to their true module roots. This
+	// is mostly a no-op (a go.mod can only ever require a real module
+	// root already), but it also normalizes major-version suffixes and
+	// catches a hand-edited go.mod that lists a package path by mistake.
+	for i := range declaredDeps {
+		if root := resolveModuleRootCached(projectDir, declaredDeps[i].ArtifactID); root != "" {
+			declaredDeps[i].ArtifactID = root
+			declaredDeps[i].Key = root
+		}
+	}
+
 	declaredMap := make(map[string]utils.Dependency)
 	for _, d := range declaredDeps {
 		declaredMap[sanitizeGoDep(d.ArtifactID)] = d
@@ -89,12 +184,19 @@ func (h *GoHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 
 	// 2. Collect imports from .go files (AST-based)
 	utils.AppendLog(projectDir, "[GoHandler][Scan] Collecting imports via AST...")
-	imports, err := CollectGoImports(projectDir, moduleName)
+	rawImports, err := CollectGoImports(projectDir, moduleName)
 	if err != nil {
 		utils.AppendLog(projectDir, "[GoHandler][Scan] Error collecting imports: %v", err)
-		return nil, err
+		return nil, scanReport, err
 	}
-	utils.AppendLog(projectDir, "[GoHandler][Scan] Collected %d unique imports", len(imports))
+	utils.AppendLog(projectDir, "[GoHandler][Scan] Collected %d unique imports", len(rawImports))
+
+	// Collapse each import path (e.g. k8s.io/client-go/kubernetes/typed/core/v1)
+	// down to the module root that actually owns it (k8s.io/client-go),
+	// via the "?go-get=1" remote import protocol, so go.mod never ends up
+	// with a non-module-root path that would make `go get` fail.
+	imports := collapseToModuleRoots(projectDir, rawImports)
+	utils.AppendLog(projectDir, "[GoHandler][Scan] Collapsed imports to %d module roots", len(imports))
 
 	// 3. Parse Syft output (metadata only)
 	syftPath := filepath.Join(projectDir, "syft.json")
@@ -166,8 +268,26 @@ func (h *GoHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 		utils.AppendLog(projectDir, "[GoHandler][Scan] No go.mod present and %d imports detected (will create go.mod during recovery)", len(imports))
 	}
 
+	// Best-effort enrich each already-pinned dependency with its go.sum
+	// content hash and direct requirements, so EmitSBOM can record
+	// CycloneDX "hashes"/"dependencies" without needing its own project
+	// directory. Entries still at "latest" are left alone; a later scan,
+	// once GenerateRecoveryFile has pinned them in go.mod, will pick them up.
+	final = enrichGoModGraph(projectDir, final)
+
 	utils.AppendLog(projectDir, "[GoHandler][Scan] Scan complete. Final deps: %d", len(final))
-	return final, nil
+	scanReport.RecordPhase("declared", len(declaredDeps))
+	scanReport.RecordPhase("scanned", len(imports))
+	scanLogger.Info("parsed manifest", utils.LogKeyFile, "go.mod", utils.LogKeyPhase, "declared", "deps", len(declaredDeps))
+	scanLogger.Info("scanned imports", utils.LogKeyPhase, "scanned", "deps", len(imports))
+
+	if err := policy.Apply(h.Name(), "go", projectDir, final); err != nil {
+		return nil, scanReport, err
+	}
+
+	scanReport.RecordEcosystem("go", len(final))
+	scanLogger.Info("scan complete", utils.LogKeyPhase, "ecosystem", utils.LogKeySource, "go", "deps", len(final))
+	return final, scanReport, nil
 }
 
 // GenerateRecoveryFile writes new go.mod + regenerates go.sum
@@ -209,16 +329,30 @@ func (h *GoHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir, ba
 		utils.AppendLog(projectDir, "[GoHandler][GenerateRecoveryFile] go.mod backup complete")
 	}
 
-	// Write go.mod (we will skip writing entries whose version is "latest" — they will be resolved with 'go get <pkg>@latest')
-	utils.AppendLog(projectDir, "[GoHandler][GenerateRecoveryFile] Writing go.mod (skipping entries with version 'latest')")
+	// Resolve "latest"/empty sentinels via the GOPROXY protocol, in
+	// parallel, instead of shelling out to `go get` per dependency.
+	utils.AppendLog(projectDir, "[GoHandler][GenerateRecoveryFile] Resolving 'latest' deps via GOPROXY")
+	deps = resolveLatestDeps(projectDir, deps)
+
+	// Fetch each pinned dependency's transitive requirements from the
+	// proxy to build a minimal-version-selection graph, and raise any
+	// dependency that's pinned lower than what the graph says it needs.
+	utils.AppendLog(projectDir, "[GoHandler][GenerateRecoveryFile] Building minimal-version-selection graph via GOPROXY")
+	graph := buildVersionGraph(projectDir, deps)
+	deps = applyVersionGraph(deps, graph)
+
+	// Write go.mod (still skipping any entry the proxy couldn't resolve —
+	// those fall back to `go get` below)
+	utils.AppendLog(projectDir, "[GoHandler][GenerateRecoveryFile] Writing go.mod (skipping unresolved entries still at 'latest')")
 	if err := WriteGoMod(modPath, moduleName, deps); err != nil {
 		utils.AppendLog(projectDir, "[GoHandler][GenerateRecoveryFile] WriteGoMod failed: %v", err)
 		return err
 	}
 	utils.AppendLog(projectDir, "[GoHandler][GenerateRecoveryFile] Wrote go.mod")
 
-	// Resolve "latest" dependencies explicitly with go get
-	utils.AppendLog(projectDir, "[GoHandler][GenerateRecoveryFile] Resolving 'latest' deps with go get")
+	// Fall back to `go get` only for entries the proxy left unresolved
+	// (typically GOPRIVATE modules, or a proxy miss).
+	utils.AppendLog(projectDir, "[GoHandler][GenerateRecoveryFile] Falling back to go get for any still-unresolved deps")
 	for _, d := range deps {
 		if d.Version == "latest" || d.Version == "" {
 			utils.AppendLog(projectDir, "[GoHandler][GenerateRecoveryFile] Running: go get %s@latest", d.ArtifactID)
@@ -246,104 +380,209 @@ func (h *GoHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir, ba
 	return nil
 }
 
+// Diagnose runs a read-only equivalent of `go mod tidy` in a scratch copy
+// of projectDir and reports how the on-disk go.mod drifts from what tidy
+// would write, without mutating anything under projectDir. This is the
+// dry-run/CI counterpart to GenerateRecoveryFile: run it first to see what
+// would change, then decide whether to actually rewrite go.mod.
+func (h *GoHandler) Diagnose(projectDir string) ([]utils.ModDiagnostic, error) {
+	utils.AppendLog(projectDir, "[GoHandler][Diagnose] Starting go.mod drift diagnosis for: %s", projectDir)
+
+	modPath := filepath.Join(projectDir, "go.mod")
+	origData, err := os.ReadFile(modPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %v", err)
+	}
+	origMod, err := modfile.Parse(modPath, origData, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid go.mod %s: %v", modPath, err)
+	}
+
+	scratchDir, err := os.MkdirTemp("", "ort-modtidy-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scratch dir: %v", err)
+	}
+	defer os.RemoveAll(scratchDir)
+
+	if err := copyProjectTree(projectDir, scratchDir); err != nil {
+		return nil, fmt.Errorf("failed to copy project into scratch dir: %v", err)
+	}
+
+	utils.AppendLog(projectDir, "[GoHandler][Diagnose] Running: go mod tidy (in scratch copy %s)", scratchDir)
+	cmd := exec.Command("go", "mod", "tidy")
+	cmd.Dir = scratchDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		utils.AppendLog(projectDir, "[GoHandler][Diagnose] go mod tidy failed in scratch copy: %v\nOutput:\n%s", err, string(out))
+		return nil, fmt.Errorf("go mod tidy failed: %v\nOutput:\n%s", err, string(out))
+	}
+
+	tidiedPath := filepath.Join(scratchDir, "go.mod")
+	tidiedData, err := os.ReadFile(tidiedPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tidied go.mod: %v", err)
+	}
+	tidiedMod, err := modfile.Parse(tidiedPath, tidiedData, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tidied go.mod: %v", err)
+	}
+
+	sites, err := collectImportSites(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map import sites: %v", err)
+	}
+
+	origReq := make(map[string]*modfile.Require, len(origMod.Require))
+	for _, r := range origMod.Require {
+		origReq[r.Mod.Path] = r
+	}
+	tidiedReq := make(map[string]*modfile.Require, len(tidiedMod.Require))
+	for _, r := range tidiedMod.Require {
+		tidiedReq[r.Mod.Path] = r
+	}
+
+	var diagnostics []utils.ModDiagnostic
+
+	for path, r := range tidiedReq {
+		pathSites := sites[path]
+		o, existed := origReq[path]
+		if !existed {
+			d := utils.ModDiagnostic{
+				Kind:       utils.MissingRequirement,
+				Path:       path,
+				Version:    r.Mod.Version,
+				ImportedBy: fileListFromSites(pathSites),
+			}
+			setDiagnosticSite(&d, pathSites)
+			diagnostics = append(diagnostics, d)
+			utils.AppendLog(projectDir, "[GoHandler][Diagnose] MissingRequirement: %s %s", path, r.Mod.Version)
+			continue
+		}
+		if o.Mod.Version != r.Mod.Version {
+			d := utils.ModDiagnostic{Kind: utils.WrongVersion, Path: path, Was: o.Mod.Version, Now: r.Mod.Version}
+			setDiagnosticSite(&d, pathSites)
+			diagnostics = append(diagnostics, d)
+			utils.AppendLog(projectDir, "[GoHandler][Diagnose] WrongVersion: %s %s -> %s", path, o.Mod.Version, r.Mod.Version)
+		}
+		if o.Indirect != r.Indirect {
+			d := utils.ModDiagnostic{Kind: utils.WrongDirectness, Path: path, Was: directnessLabel(o.Indirect), Now: directnessLabel(r.Indirect)}
+			setDiagnosticSite(&d, pathSites)
+			diagnostics = append(diagnostics, d)
+			utils.AppendLog(projectDir, "[GoHandler][Diagnose] WrongDirectness: %s %s -> %s", path, d.Was, d.Now)
+		}
+	}
+
+	for path := range origReq {
+		if _, ok := tidiedReq[path]; !ok {
+			diagnostics = append(diagnostics, utils.ModDiagnostic{Kind: utils.UnusedRequirement, Path: path})
+			utils.AppendLog(projectDir, "[GoHandler][Diagnose] UnusedRequirement: %s", path)
+		}
+	}
+
+	utils.AppendLog(projectDir, "[GoHandler][Diagnose] Diagnosis complete. %d diagnostics", len(diagnostics))
+	return diagnostics, nil
+}
+
 // ---------------------------
 // Helpers
 // ---------------------------
 
+// ParseGoMod parses modPath with golang.org/x/mod/modfile instead of
+// scanning lines by hand, so replace/exclude/retract directives, block-form
+// require(...), and the go directive are all understood correctly rather
+// than missed. Declared requirements get their replace target substituted
+// in (the module actually resolved at build time), and their indirect
+// marker preserved on utils.Dependency.Indirect.
 func ParseGoMod(modPath string) ([]utils.Dependency, string, error) {
 	utils.AppendLog(filepath.Dir(modPath), "[GoHandler][ParseGoMod] Parsing go.mod: %s", modPath)
-	if _, err := os.Stat(modPath); os.IsNotExist(err) {
+	data, err := os.ReadFile(modPath)
+	if os.IsNotExist(err) {
 		utils.AppendLog(filepath.Dir(modPath), "[GoHandler][ParseGoMod] go.mod does not exist")
 		return []utils.Dependency{}, "", nil
 	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read go.mod: %v", err)
+	}
 
-	file, err := os.Open(modPath)
+	f, err := modfile.Parse(modPath, data, nil)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to open go.mod: %v", err)
+		return nil, "", fmt.Errorf("invalid go.mod %s: %v", modPath, err)
 	}
-	defer file.Close()
 
-	var deps []utils.Dependency
 	moduleName := ""
-	inRequireBlock := false
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// strip inline comments like: v1.2.3 // indirect
-		if idx := strings.Index(line, "//"); idx != -1 {
-			line = strings.TrimSpace(line[:idx])
-		}
-
-		if line == "" {
-			continue
-		}
+	if f.Module != nil {
+		moduleName = f.Module.Mod.Path
+		utils.AppendLog(filepath.Dir(modPath), "[GoHandler][ParseGoMod] Found module: %s", moduleName)
+	}
 
-		if strings.HasPrefix(line, "module") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				moduleName = sanitizeGoDep(parts[1])
-				utils.AppendLog(filepath.Dir(modPath), "[GoHandler][ParseGoMod] Found module: %s", moduleName)
-			}
-			continue
-		}
+	replacements := make(map[string]module.Version, len(f.Replace))
+	for _, r := range f.Replace {
+		replacements[r.Old.Path] = r.New
+	}
 
-		if strings.HasPrefix(line, "require (") {
-			inRequireBlock = true
-			continue
+	var deps []utils.Dependency
+	for _, req := range f.Require {
+		path := req.Mod.Path
+		version := req.Mod.Version
+		if rep, ok := replacements[path]; ok {
+			utils.AppendLog(filepath.Dir(modPath), "[GoHandler][ParseGoMod] %s replaced by %s %s", path, rep.Path, rep.Version)
+			path = rep.Path
+			version = rep.Version
 		}
-		if inRequireBlock && line == ")" {
-			inRequireBlock = false
-			continue
+		dep := utils.Dependency{
+			GroupID:    "golang",
+			ArtifactID: sanitizeGoDep(path),
+			ImportPath: sanitizeGoDep(path),
+			Version:    sanitizeGoDep(version),
+			Scope:      "compile",
+			Key:        sanitizeGoDep(path),
+			Language:   "go",
+			Indirect:   req.Indirect,
 		}
-
-		// single-line require: `require github.com/x v1.2.3`
-		if strings.HasPrefix(line, "require ") && !inRequireBlock {
-			parts := strings.Fields(line)
-			if len(parts) >= 3 {
-				dep := utils.Dependency{
-					GroupID:    "golang",
-					ArtifactID: sanitizeGoDep(parts[1]),
-					Version:    sanitizeGoDep(parts[2]),
-					Scope:      "compile",
-					Key:        sanitizeGoDep(parts[1]),
-					Language:   "go",
-				}
-				if sanitized := dep.Sanitize(); sanitized != nil {
-					deps = append(deps, *sanitized)
-					utils.AppendLog(filepath.Dir(modPath), "[GoHandler][ParseGoMod] Added single require: %s %s", dep.ArtifactID, dep.Version)
-				}
-			}
-			continue
+		if sanitized := dep.Sanitize(); sanitized != nil {
+			deps = append(deps, *sanitized)
+			utils.AppendLog(filepath.Dir(modPath), "[GoHandler][ParseGoMod] Added require: %s %s (indirect=%v)", sanitized.ArtifactID, sanitized.Version, sanitized.Indirect)
 		}
+	}
 
-		// inside require block: `github.com/x v1.2.3`
-		if inRequireBlock && line != "" {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				dep := utils.Dependency{
-					GroupID:    "golang",
-					ArtifactID: sanitizeGoDep(parts[0]),
-					Version:    sanitizeGoDep(parts[1]),
-					Scope:      "compile",
-					Key:        sanitizeGoDep(parts[0]),
-					Language:   "go",
-				}
-				if sanitized := dep.Sanitize(); sanitized != nil {
-					deps = append(deps, *sanitized)
-					utils.AppendLog(filepath.Dir(modPath), "[GoHandler][ParseGoMod] Added block require: %s %s", dep.ArtifactID, dep.Version)
-				}
-			}
-		}
+	for _, ex := range f.Exclude {
+		utils.AppendLog(filepath.Dir(modPath), "[GoHandler][ParseGoMod] exclude directive: %s %s", ex.Mod.Path, ex.Mod.Version)
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, "", fmt.Errorf("failed to read go.mod: %v", err)
+	for _, rt := range f.Retract {
+		utils.AppendLog(filepath.Dir(modPath), "[GoHandler][ParseGoMod] retract directive: [%s, %s] %s", rt.Low, rt.High, rt.Rationale)
 	}
+
 	utils.AppendLog(filepath.Dir(modPath), "[GoHandler][ParseGoMod] Finished parsing go.mod. deps=%d module=%s", len(deps), moduleName)
 	return deps, moduleName, nil
 }
 
+// ParseGoWork parses a go.work file with modfile.ParseWork and returns the
+// absolute, on-disk module directories its use(...) directives list, so
+// callers can fold each workspace module's go.mod into one scan.
+func ParseGoWork(workPath string) ([]string, error) {
+	utils.AppendLog(filepath.Dir(workPath), "[GoHandler][ParseGoWork] Parsing go.work: %s", workPath)
+	data, err := os.ReadFile(workPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.work: %v", err)
+	}
+
+	f, err := modfile.ParseWork(workPath, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid go.work %s: %v", workPath, err)
+	}
+
+	workDir := filepath.Dir(workPath)
+	var dirs []string
+	for _, use := range f.Use {
+		dir := filepath.Clean(filepath.Join(workDir, use.Path))
+		dirs = append(dirs, dir)
+		utils.AppendLog(workDir, "[GoHandler][ParseGoWork] use: %s", dir)
+	}
+	return dirs, nil
+}
+
 func CollectGoImports(projectDir string, modulePath string) ([]string, error) {
 	utils.AppendLog(projectDir, "[GoHandler][CollectGoImports] Starting import collection in: %s", projectDir)
 	imports := make(map[string]struct{})
@@ -365,6 +604,11 @@ func CollectGoImports(projectDir string, modulePath string) ([]string, error) {
 		if !strings.HasSuffix(path, ".go") {
 			return nil
 		}
+		dir, name := filepath.Split(path)
+		if !matchesAnyPlatform(dir, name) {
+			utils.AppendLog(projectDir, "[GoHandler][CollectGoImports] Skipping file excluded by build constraints on every GOOS/GOARCH in the matrix: %s", path)
+			return nil
+		}
 		utils.AppendLog(projectDir, "[GoHandler][CollectGoImports] Parsing file: %s", path)
 		fset := token.NewFileSet()
 		node, parseErr := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
@@ -407,59 +651,72 @@ func CollectGoImports(projectDir string, modulePath string) ([]string, error) {
 	return list, nil
 }
 
+// WriteGoMod re-emits modPath via modfile.File.Format() so the result is
+// byte-accurate and round-trippable, instead of hand-printing lines. Any
+// go/replace/exclude/retract directives already on disk are preserved;
+// only the require block is rebuilt from deps. Entries still carrying the
+// "latest"/empty sentinel are skipped, same as before, and resolved later
+// with `go get <pkg>@latest`.
 func WriteGoMod(modPath string, moduleName string, deps []utils.Dependency) error {
 	utils.AppendLog(filepath.Dir(modPath), "[GoHandler][WriteGoMod] Writing go.mod: %s", modPath)
-	f, err := os.Create(modPath)
-	if err != nil {
-		return fmt.Errorf("failed to create go.mod: %v", err)
-	}
-	defer f.Close()
 
-	// module line
-	if _, err := f.WriteString(fmt.Sprintf("module %s\n\n", moduleName)); err != nil {
-		return fmt.Errorf("failed to write module line: %v", err)
+	f := new(modfile.File)
+	if err := f.AddModuleStmt(moduleName); err != nil {
+		return fmt.Errorf("failed to set module: %v", err)
 	}
 
-	if len(deps) > 0 {
-		if _, err := f.WriteString("require (\n"); err != nil {
-			return fmt.Errorf("failed to write require ( block: %v", err)
-		}
-		for _, d := range deps {
-			if d.Version == "latest" || d.Version == "" {
-				continue // skip writing "latest"
+	if existing, err := os.ReadFile(modPath); err == nil {
+		if old, perr := modfile.Parse(modPath, existing, nil); perr == nil {
+			if old.Go != nil {
+				if err := f.AddGoStmt(old.Go.Version); err != nil {
+					return fmt.Errorf("failed to carry over go directive: %v", err)
+				}
+			}
+			for _, ex := range old.Exclude {
+				if err := f.AddExclude(ex.Mod.Path, ex.Mod.Version); err != nil {
+					return fmt.Errorf("failed to carry over exclude directive: %v", err)
+				}
+			}
+			for _, r := range old.Replace {
+				if err := f.AddReplace(r.Old.Path, r.Old.Version, r.New.Path, r.New.Version); err != nil {
+					return fmt.Errorf("failed to carry over replace directive: %v", err)
+				}
 			}
-			line := fmt.Sprintf("\t%s %s\n", d.ArtifactID, d.Version)
-			if _, err := f.WriteString(line); err != nil {
-				return fmt.Errorf("failed to write dependency: %v", err)
+			for _, rt := range old.Retract {
+				if err := f.AddRetract(rt.VersionInterval, rt.Rationale); err != nil {
+					return fmt.Errorf("failed to carry over retract directive: %v", err)
+				}
 			}
-			utils.AppendLog(filepath.Dir(modPath), "[GoHandler][WriteGoMod] Added dependency line: %s", strings.TrimSpace(line))
 		}
-		if _, err := f.WriteString(")\n"); err != nil {
-			return fmt.Errorf("failed to close require block: %v", err)
+	}
+
+	for _, d := range deps {
+		if d.Version == "latest" || d.Version == "" {
+			continue // skip writing "latest"; resolved later with `go get`
 		}
+		f.AddNewRequire(d.ArtifactID, d.Version, d.Indirect)
+		utils.AppendLog(filepath.Dir(modPath), "[GoHandler][WriteGoMod] Added require: %s %s (indirect=%v)", d.ArtifactID, d.Version, d.Indirect)
+	}
+	f.SetRequireSeparateIndirect(f.Require)
+	f.Cleanup()
+
+	data, err := f.Format()
+	if err != nil {
+		return fmt.Errorf("failed to format go.mod: %v", err)
+	}
+	if err := os.WriteFile(modPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write go.mod: %v", err)
 	}
 	utils.AppendLog(filepath.Dir(modPath), "[GoHandler][WriteGoMod] go.mod written successfully")
 	return nil
 }
 
 func getModulePath(modPath string) string {
-	f, err := os.Open(modPath)
+	_, moduleName, err := ParseGoMod(modPath)
 	if err != nil {
 		return ""
 	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if strings.HasPrefix(line, "module") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				return sanitizeGoDep(parts[1])
-			}
-		}
-	}
-	return ""
+	return moduleName
 }
 
 // ---------------------------
@@ -480,3 +737,123 @@ func isStdLib(path string) bool {
 	// This heuristic is widely used and acceptable for recovery
 	return !strings.Contains(path, ".")
 }
+
+// importSite is one place a package path was imported, for diagnostics
+// that point back at the triggering source location.
+type importSite struct {
+	file string
+	line int
+}
+
+// collectImportSites walks projectDir like CollectGoImports, but keeps
+// every import site (file + line, relative to projectDir) instead of just
+// the unique set of paths, so Diagnose can point at the code that pulled
+// a dependency in.
+func collectImportSites(projectDir string) (map[string][]importSite, error) {
+	sites := make(map[string][]importSite)
+	err := filepath.Walk(projectDir, func(path string, info os.FileInfo, werr error) error {
+		if werr != nil {
+			return nil
+		}
+		if info.IsDir() {
+			base := strings.ToLower(info.Name())
+			if base == "vendor" || base == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		dir, name := filepath.Split(path)
+		if !matchesAnyPlatform(dir, name) {
+			return nil
+		}
+		fset := token.NewFileSet()
+		node, perr := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if perr != nil {
+			return nil
+		}
+		rel, rerr := filepath.Rel(projectDir, path)
+		if rerr != nil {
+			rel = path
+		}
+		for _, imp := range node.Imports {
+			pathVal := strings.Trim(imp.Path.Value, `"`)
+			if pathVal == "" || isStdLib(pathVal) {
+				continue
+			}
+			clean := sanitizeGoDep(pathVal)
+			if clean == "" {
+				continue
+			}
+			pos := fset.Position(imp.Pos())
+			sites[clean] = append(sites[clean], importSite{file: rel, line: pos.Line})
+		}
+		return nil
+	})
+	return sites, err
+}
+
+// fileListFromSites reduces a path's import sites to its unique file list,
+// in first-seen order, for ModDiagnostic.ImportedBy.
+func fileListFromSites(sites []importSite) []string {
+	if len(sites) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(sites))
+	var files []string
+	for _, s := range sites {
+		if _, ok := seen[s.file]; ok {
+			continue
+		}
+		seen[s.file] = struct{}{}
+		files = append(files, s.file)
+	}
+	return files
+}
+
+// setDiagnosticSite fills in File/Line from the first known import site,
+// if any.
+func setDiagnosticSite(d *utils.ModDiagnostic, sites []importSite) {
+	if len(sites) == 0 {
+		return
+	}
+	d.File, d.Line = sites[0].file, sites[0].line
+}
+
+func directnessLabel(indirect bool) string {
+	if indirect {
+		return "indirect"
+	}
+	return "direct"
+}
+
+// copyProjectTree copies projectDir's go.mod/go.sum, .go sources, and any
+// other regular files into dst (skipping vendor/.git), so `go mod tidy`
+// can run against a scratch copy without mutating the original project.
+func copyProjectTree(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, rerr := filepath.Rel(src, path)
+		if rerr != nil {
+			return rerr
+		}
+		if rel == "." {
+			return nil
+		}
+		if info.IsDir() {
+			base := strings.ToLower(info.Name())
+			if base == "vendor" || base == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		if err := os.MkdirAll(filepath.Join(dst, filepath.Dir(rel)), 0o755); err != nil {
+			return err
+		}
+		return utils.CopyFile(path, filepath.Join(dst, rel))
+	})
+}
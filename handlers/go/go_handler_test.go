@@ -0,0 +1,75 @@
+package gohandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseGoModRequireAndReplace(t *testing.T) {
+	dir := t.TempDir()
+	content := `module example.com/demo
+
+go 1.21
+
+require (
+	github.com/foo/bar v1.2.3
+	github.com/baz/qux v0.0.1 // indirect
+)
+
+replace github.com/foo/bar => github.com/foo/bar-fork v1.2.4
+`
+	modPath := filepath.Join(dir, "go.mod")
+	if err := os.WriteFile(modPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	deps, moduleName, err := ParseGoMod(modPath)
+	if err != nil {
+		t.Fatalf("ParseGoMod: %v", err)
+	}
+	if moduleName != "example.com/demo" {
+		t.Errorf("moduleName = %q, want example.com/demo", moduleName)
+	}
+	if len(deps) != 2 {
+		t.Fatalf("expected 2 deps, got %d: %+v", len(deps), deps)
+	}
+
+	byPath := make(map[string]int, len(deps))
+	for i, d := range deps {
+		byPath[d.ArtifactID] = i
+	}
+
+	bar, ok := byPath["github.com/foo/bar-fork"]
+	if !ok {
+		t.Fatalf("expected replace target github.com/foo/bar-fork, got: %+v", byPath)
+	}
+	if deps[bar].Version != "v1.2.4" {
+		t.Errorf("replaced bar version = %q, want v1.2.4", deps[bar].Version)
+	}
+	if deps[bar].Indirect {
+		t.Errorf("bar should not be indirect")
+	}
+
+	qux, ok := byPath["github.com/baz/qux"]
+	if !ok {
+		t.Fatalf("expected github.com/baz/qux, got: %+v", byPath)
+	}
+	if !deps[qux].Indirect {
+		t.Errorf("qux should be indirect")
+	}
+	if deps[qux].Version != "v0.0.1" {
+		t.Errorf("qux version = %q, want v0.0.1", deps[qux].Version)
+	}
+}
+
+func TestParseGoModMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	deps, moduleName, err := ParseGoMod(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatalf("ParseGoMod on missing file should not error, got: %v", err)
+	}
+	if len(deps) != 0 || moduleName != "" {
+		t.Errorf("expected empty result for missing go.mod, got deps=%+v module=%q", deps, moduleName)
+	}
+}
@@ -0,0 +1,344 @@
+package gohandler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"ort-recovery/utils"
+	"ort-recovery/utils/goproxy"
+	"ort-recovery/utils/vanity"
+)
+
+// resolveLatestWorkers bounds how many concurrent GOPROXY requests
+// resolveLatestDeps and buildVersionGraph issue at once.
+const resolveLatestWorkers = 8
+
+// resolveLatestDeps resolves every "latest"/empty-version entry in deps to
+// a concrete version via the Go module proxy protocol, in parallel across
+// resolveLatestWorkers goroutines, instead of the old one-at-a-time `go
+// get pkg@latest` shell-out. GOPRIVATE modules are left untouched for the
+// caller's `go get` fallback, since a proxy is never expected to have
+// them. Returns a new slice; an entry that couldn't be resolved keeps its
+// original (unresolved) version.
+func resolveLatestDeps(projectDir string, deps []utils.Dependency) []utils.Dependency {
+	client := &goproxy.Client{}
+	resolved := make([]utils.Dependency, len(deps))
+	copy(resolved, deps)
+
+	type job struct {
+		index int
+		dep   utils.Dependency
+	}
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+
+	for w := 0; w < resolveLatestWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if goproxy.IsPrivate(j.dep.ArtifactID) {
+					utils.AppendLog(projectDir, "[GoHandler][resolveLatestDeps] %s matches GOPRIVATE; leaving for `go get` fallback", j.dep.ArtifactID)
+					continue
+				}
+				info, err := client.Latest(j.dep.ArtifactID)
+				if err != nil {
+					utils.AppendLog(projectDir, "[GoHandler][resolveLatestDeps] GOPROXY resolution failed for %s: %v", j.dep.ArtifactID, err)
+					continue
+				}
+				resolved[j.index].Version = info.Version
+				utils.AppendLog(projectDir, "[GoHandler][resolveLatestDeps] %s resolved to %s via GOPROXY", j.dep.ArtifactID, info.Version)
+			}
+		}()
+	}
+
+	for i, d := range deps {
+		if d.Version != "latest" && d.Version != "" {
+			continue
+		}
+		jobs <- job{index: i, dep: d}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return resolved
+}
+
+// buildVersionGraph fetches each pinned dependency's go.mod through the
+// module proxy and recursively walks its own requirements, tracking the
+// highest version seen per module path. This is the same minimal version
+// selection rule the go command uses to settle on one version for a
+// module required at several versions transitively. A module whose
+// go.mod can't be fetched (private, removed, network error) is simply
+// skipped; its declared version is left alone.
+func buildVersionGraph(projectDir string, deps []utils.Dependency) map[string]string {
+	client := &goproxy.Client{}
+	versions := make(map[string]string, len(deps))
+	var queue []utils.Dependency
+	for _, d := range deps {
+		if d.Version == "" || d.Version == "latest" || !semver.IsValid(d.Version) {
+			continue
+		}
+		if existing, ok := versions[d.ArtifactID]; !ok || semver.Compare(d.Version, existing) > 0 {
+			versions[d.ArtifactID] = d.Version
+		}
+		queue = append(queue, utils.Dependency{ArtifactID: d.ArtifactID, Version: d.Version})
+	}
+
+	visited := make(map[string]struct{})
+	var mu sync.Mutex
+
+	for len(queue) > 0 {
+		batch := queue
+		queue = nil
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, resolveLatestWorkers)
+
+		for _, d := range batch {
+			key := d.ArtifactID + "@" + d.Version
+			mu.Lock()
+			if _, ok := visited[key]; ok {
+				mu.Unlock()
+				continue
+			}
+			visited[key] = struct{}{}
+			mu.Unlock()
+
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(d utils.Dependency) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				data, err := client.GoMod(d.ArtifactID, d.Version)
+				if err != nil {
+					utils.AppendLog(projectDir, "[GoHandler][buildVersionGraph] could not fetch go.mod for %s@%s: %v", d.ArtifactID, d.Version, err)
+					return
+				}
+				modf, err := modfile.ParseLax(d.ArtifactID+"@"+d.Version+"/go.mod", data, nil)
+				if err != nil {
+					utils.AppendLog(projectDir, "[GoHandler][buildVersionGraph] could not parse go.mod for %s@%s: %v", d.ArtifactID, d.Version, err)
+					return
+				}
+
+				mu.Lock()
+				for _, req := range modf.Require {
+					if !semver.IsValid(req.Mod.Version) {
+						continue
+					}
+					existing, ok := versions[req.Mod.Path]
+					if ok && semver.Compare(req.Mod.Version, existing) <= 0 {
+						continue
+					}
+					versions[req.Mod.Path] = req.Mod.Version
+					queue = append(queue, utils.Dependency{ArtifactID: req.Mod.Path, Version: req.Mod.Version})
+					utils.AppendLog(projectDir, "[GoHandler][buildVersionGraph] %s@%s requires %s %s", d.ArtifactID, d.Version, req.Mod.Path, req.Mod.Version)
+				}
+				mu.Unlock()
+			}(d)
+		}
+
+		wg.Wait()
+	}
+
+	return versions
+}
+
+// applyVersionGraph raises any dependency whose version is lower than
+// what buildVersionGraph's minimal version selection found a transitive
+// requirement needs. It never downgrades an explicitly declared version.
+func applyVersionGraph(deps []utils.Dependency, graph map[string]string) []utils.Dependency {
+	out := make([]utils.Dependency, len(deps))
+	copy(out, deps)
+	for i, d := range out {
+		v, ok := graph[d.ArtifactID]
+		if !ok || !semver.IsValid(v) || !semver.IsValid(d.Version) {
+			continue
+		}
+		if semver.Compare(v, d.Version) > 0 {
+			out[i].Version = v
+		}
+	}
+	return out
+}
+
+// ---------------------------
+// SBOM metadata enrichment
+// ---------------------------
+
+// enrichGoModGraph populates Checksum (from go.sum) and Requires (direct
+// go.mod requirements, fetched through the module proxy) for every
+// dependency in deps that already carries a concrete pinned version. It's
+// the data GoHandler.EmitSBOM needs for CycloneDX "hashes"/"dependencies"
+// output, gathered here (where a projectDir is available) rather than in
+// EmitSBOM itself, whose signature is shared across every handler and
+// carries no project context. Returns a new slice; a dependency that can't
+// be resolved (private, network error, still "latest") is left unchanged.
+func enrichGoModGraph(projectDir string, deps []utils.Dependency) []utils.Dependency {
+	out := make([]utils.Dependency, len(deps))
+	copy(out, deps)
+
+	sums := readGoSumHashes(projectDir)
+	present := make(map[string]struct{}, len(out))
+	for _, d := range out {
+		present[d.ArtifactID] = struct{}{}
+	}
+
+	client := &goproxy.Client{}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, resolveLatestWorkers)
+
+	for i := range out {
+		d := &out[i]
+		if d.Version == "" || d.Version == "latest" {
+			continue
+		}
+		if hash, ok := sums[d.ArtifactID+"@"+d.Version]; ok {
+			d.Checksum = hash
+		}
+		if goproxy.IsPrivate(d.ArtifactID) {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(d *utils.Dependency) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			data, err := client.GoMod(d.ArtifactID, d.Version)
+			if err != nil {
+				utils.AppendLog(projectDir, "[GoHandler][enrichGoModGraph] could not fetch go.mod for %s@%s: %v", d.ArtifactID, d.Version, err)
+				return
+			}
+			modf, err := modfile.ParseLax(d.ArtifactID+"@"+d.Version+"/go.mod", data, nil)
+			if err != nil {
+				utils.AppendLog(projectDir, "[GoHandler][enrichGoModGraph] could not parse go.mod for %s@%s: %v", d.ArtifactID, d.Version, err)
+				return
+			}
+			for _, req := range modf.Require {
+				if _, ok := present[req.Mod.Path]; ok {
+					d.Requires = append(d.Requires, req.Mod.Path)
+				}
+			}
+		}(d)
+	}
+	wg.Wait()
+
+	return out
+}
+
+// readGoSumHashes parses go.sum into a "module@version" -> "h1:..." map of
+// full-module content hashes, skipping the "/go.mod" hash lines (which hash
+// the go.mod file alone, not the module's content).
+func readGoSumHashes(projectDir string) map[string]string {
+	hashes := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(projectDir, "go.sum"))
+	if err != nil {
+		return hashes
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		hashes[fields[0]+"@"+fields[1]] = fields[2]
+	}
+	return hashes
+}
+
+// ---------------------------
+// Vanity import path resolution
+// ---------------------------
+
+// vanityCacheFile caches vanity.ResolveModuleRoot lookups inside the
+// project's recovery dir (the same directory recovery.log lives in), so
+// a rerun against the same project doesn't re-issue the "?go-get=1"
+// requests non-well-known hosts need.
+const vanityCacheFile = ".ort-recovery-vanity-cache.json"
+
+// vanityCacheTTL bounds how long a cached resolution (hit or miss) is
+// trusted before being looked up again.
+const vanityCacheTTL = 24 * time.Hour
+
+type vanityCacheEntry struct {
+	ModuleRoot string    `json:"moduleRoot"`
+	FetchedAt  time.Time `json:"fetchedAt"`
+}
+
+type vanityCache struct {
+	Entries map[string]vanityCacheEntry `json:"entries"`
+}
+
+func loadVanityCache(projectDir string) *vanityCache {
+	c := &vanityCache{Entries: map[string]vanityCacheEntry{}}
+	data, err := os.ReadFile(filepath.Join(projectDir, vanityCacheFile))
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil || c.Entries == nil {
+		return &vanityCache{Entries: map[string]vanityCacheEntry{}}
+	}
+	return c
+}
+
+func (c *vanityCache) save(projectDir string) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(projectDir, vanityCacheFile), data, 0644)
+}
+
+// resolveModuleRootCached wraps vanity.ResolveModuleRoot with an on-disk,
+// per-project cache, so collapseToModuleRoots doesn't re-resolve the same
+// import path (and re-issue its "?go-get=1" request) on every run.
+// Returns "" when resolution fails, for the caller to fall back to the
+// original import path.
+func resolveModuleRootCached(projectDir, importPath string) string {
+	cache := loadVanityCache(projectDir)
+	if e, ok := cache.Entries[importPath]; ok && time.Since(e.FetchedAt) < vanityCacheTTL {
+		return e.ModuleRoot
+	}
+
+	root, _, err := vanity.ResolveModuleRoot(importPath)
+	if err != nil {
+		utils.AppendLog(projectDir, "[GoHandler][resolveModuleRootCached] could not resolve module root for %s: %v", importPath, err)
+		cache.Entries[importPath] = vanityCacheEntry{FetchedAt: time.Now()}
+		cache.save(projectDir)
+		return ""
+	}
+	utils.AppendLog(projectDir, "[GoHandler][resolveModuleRootCached] %s -> module root %s", importPath, root)
+	cache.Entries[importPath] = vanityCacheEntry{ModuleRoot: root, FetchedAt: time.Now()}
+	cache.save(projectDir)
+	return root
+}
+
+// collapseToModuleRoots resolves each of paths down to its true module
+// root (falling back to the original path when resolution fails),
+// deduplicating since multiple import paths can collapse onto the same
+// module (e.g. several packages under the same repo).
+func collapseToModuleRoots(projectDir string, paths []string) []string {
+	seen := make(map[string]struct{}, len(paths))
+	out := make([]string, 0, len(paths))
+	for _, p := range paths {
+		root := resolveModuleRootCached(projectDir, p)
+		if root == "" {
+			root = p
+		}
+		if _, ok := seen[root]; ok {
+			continue
+		}
+		seen[root] = struct{}{}
+		out = append(out, root)
+	}
+	return out
+}
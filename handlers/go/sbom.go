@@ -0,0 +1,23 @@
+package gohandler
+
+import (
+	"io"
+
+	"ort-recovery/utils"
+	"ort-recovery/utils/sbom"
+)
+
+// EmitSBOM writes deps to w as an SBOM in the given format ("cyclonedx" or
+// "spdx"), replacing the syft.json handoff. Scan already populates each
+// dependency's PURL-relevant fields plus, via enrichGoModGraph, its go.sum
+// content hash (Checksum) and direct requirements (Requires), so this
+// delegates straight to the shared encoder in utils/sbom.
+func (h *GoHandler) EmitSBOM(deps []utils.Dependency, w io.Writer, format string) error {
+	return sbom.Emit(deps, w, format)
+}
+
+// ExportSBOM writes deps to outPath as an SBOM in the given format; see
+// utils/sbom.ExportToFile.
+func (h *GoHandler) ExportSBOM(deps []utils.Dependency, format, outPath, projectDir string) error {
+	return sbom.ExportToFile(deps, format, outPath, projectDir)
+}
@@ -2,12 +2,14 @@ package handlers
 
 import (
 	"fmt"
+	"io"
 
 	cpphandler "ort-recovery/handlers/cpp"
 	dotnethandler "ort-recovery/handlers/dotnet"
 	gohandler "ort-recovery/handlers/go" // alias to avoid keyword clash
 	javahandler "ort-recovery/handlers/java"
 	nodehandler "ort-recovery/handlers/node"
+	cpanhandler "ort-recovery/handlers/perl"
 	phphandler "ort-recovery/handlers/php"
 	pythonhandler "ort-recovery/handlers/python"
 	rubyhandler "ort-recovery/handlers/ruby"
@@ -21,11 +23,27 @@ import (
 type Handler interface {
 	Name() string
 	Detect(projectDir string) bool
-	Scan(projectDir string) ([]utils.Dependency, error)
+	// Scan returns the recovered dependencies alongside a ScanReport
+	// aggregating phase/ecosystem counts, unknowns, curations applied,
+	// and OSV hits, so main can render a per-project summary without
+	// re-parsing scan.log.jsonl.
+	Scan(projectDir string) ([]utils.Dependency, utils.ScanReport, error)
 	GenerateRecoveryFile(deps []utils.Dependency, projectDir, backupDir string) error
+	// EmitSBOM writes deps to w as an SBOM in the given format ("cyclonedx"
+	// or "spdx"), replacing the syft.json handoff with a native encoder
+	// (see utils/sbom).
+	EmitSBOM(deps []utils.Dependency, w io.Writer, format string) error
+	// ExportSBOM writes deps to outPath as an SBOM in the given format, for
+	// the "-sbom" main.go flag to call once per project after recovery.
+	// projectDir is the scanned project's own source tree, stamped into
+	// the document as a source-tree fingerprint (see utils/sbom.ExportToFile).
+	ExportSBOM(deps []utils.Dependency, format, outPath, projectDir string) error
 }
 
 // GetHandlers returns all registered handlers and logs their registration.
+// It allocates a fresh slice of fresh handler instances on every call and
+// touches no shared state, so it's safe to call concurrently from a -jobs
+// worker pool.
 func GetHandlers() []Handler {
 	handlers := []Handler{
 		&javahandler.JavaHandler{},
@@ -36,6 +54,7 @@ func GetHandlers() []Handler {
 		&dotnethandler.DotNetHandler{},
 		&rubyhandler.RubyHandler{},
 		&phphandler.PHPHandler{},
+		&cpanhandler.PerlHandler{},
 		&cpphandler.CppHandler{},
 		&swifthandler.SwiftHandler{},
 	}
@@ -1,10 +1,14 @@
 package javahandler
 
 import (
+	"archive/zip"
 	"bufio"
 	"bytes"
+	"encoding/binary"
 	"encoding/xml"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -12,18 +16,37 @@ import (
 	"time"
 
 	"ort-recovery/utils"
+	"ort-recovery/utils/policy"
+	"ort-recovery/utils/sbom"
 )
 
 // ---------------------------
 // Java Handler (full-featured)
 // ---------------------------
-type JavaHandler struct{}
+type JavaHandler struct {
+	// MavenLocalRepo overrides the local Maven repository root used to
+	// resolve missing versions (see resolveFromMavenLocal). Defaults to
+	// ~/.m2/repository, or the ORT_RECOVERY_MAVEN_LOCAL env var when set.
+	MavenLocalRepo string
+
+	// mavenLocalCache memoizes the version directory listing for each
+	// "groupId:artifactId" so repeated lookups on large projects don't
+	// re-stat the same path.
+	mavenLocalCache map[string][]string
+
+	// Resolvers is a user-provided chain of remote coordinate/version
+	// resolvers, consulted after builtinImportMap misses and before a
+	// dependency's version is left blank. Only used when Online() is
+	// true (see SetOnline); nil falls back to resolversOrDefault.
+	Resolvers []CoordinateResolver
+}
 
 func (h *JavaHandler) Name() string {
 	return "Java"
 }
 
-// Detect checks for Maven/Gradle manifests or any .java files (recursive)
+// Detect checks for Maven/Gradle manifests or any JVM-language source
+// file (.java, .kt/.kts, .scala, .groovy), recursively.
 func (h *JavaHandler) Detect(projectDir string) bool {
 	manifests := []string{
 		filepath.Join(projectDir, "pom.xml"),
@@ -37,7 +60,7 @@ func (h *JavaHandler) Detect(projectDir string) bool {
 		}
 	}
 
-	// recursive scan for .java
+	// recursive scan for any JVM-language source file
 	found := false
 	_ = filepath.WalkDir(projectDir, func(p string, d os.DirEntry, err error) error {
 		if err != nil || found {
@@ -46,7 +69,7 @@ func (h *JavaHandler) Detect(projectDir string) bool {
 		if d.IsDir() {
 			return nil
 		}
-		if strings.HasSuffix(p, ".java") {
+		if _, ok := languageImportExtractors[filepath.Ext(p)]; ok {
 			found = true
 			return filepath.SkipDir
 		}
@@ -57,7 +80,9 @@ func (h *JavaHandler) Detect(projectDir string) bool {
 
 // Scan: parse manifests (Maven + all Gradle files), collect imports recursively,
 // consult syft.json, add missing deps (leave version empty if not found)
-func (h *JavaHandler) Scan(projectDir string) ([]utils.Dependency, error) {
+func (h *JavaHandler) Scan(projectDir string) ([]utils.Dependency, utils.ScanReport, error) {
+	scanReport := utils.NewScanReport()
+	scanLogger := utils.NewScanLogger(projectDir).With(utils.LogKeyHandler, h.Name())
 	var declaredDeps []utils.Dependency
 
 	// 1) Parse Maven (pom.xml) if present
@@ -66,7 +91,7 @@ func (h *JavaHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 		pdeps, err := ParsePom(pomPath)
 		if err != nil {
 			_ = utils.AppendLog(projectDir, "[JavaHandler] Error parsing pom.xml: %v", err)
-			return nil, err
+			return nil, scanReport, err
 		}
 		declaredDeps = append(declaredDeps, pdeps...)
 	}
@@ -116,7 +141,21 @@ func (h *JavaHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 	// 4) Collect imports recursively from repo (prefer scanning src/** but fallback to all .java)
 	codeDeps, err := CollectJavaImports(projectDir)
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
+	}
+
+	// 4b) Fall back to binary/JAR inspection when the project ships compiled
+	// Java but little or no matching source - e.g. ORT asked to recover a
+	// build for a shipped artifact rather than a checkout.
+	javaFiles, binaryFiles := countJavaAndBinaryFiles(projectDir)
+	if binaryFiles > 0 && javaFiles <= binaryFiles {
+		binDeps, err := CollectJavaImportsFromBinaries(projectDir)
+		if err != nil {
+			_ = utils.AppendLog(projectDir, "[JavaHandler] Warning: failed to scan binary artifacts: %v", err)
+		} else {
+			_ = utils.AppendLog(projectDir, "[JavaHandler] Found %d dependency candidate(s) from binary/JAR inspection (%d .java files vs %d binary artifacts).", len(binDeps), javaFiles, binaryFiles)
+			codeDeps = append(codeDeps, binDeps...)
+		}
 	}
 
 	// 5) Parse syft.json (best-effort)
@@ -151,22 +190,40 @@ func (h *JavaHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 		if _, ok := seen[cd.Key]; ok {
 			continue
 		}
-		// Try mapping to canonical coordinates (map or heuristic)
+		// Try mapping to canonical coordinates (builtin map, then a remote
+		// resolver chain when online, then the heuristic already applied
+		// in CollectJavaImports).
 		coord := MapImportToCoordinate(cd.ImportPath)
+		if coord.GroupID == "" || coord.ArtifactID == "" {
+			if remote, ok := h.resolveCoordinateRemote(projectDir, cd.ImportPath); ok {
+				coord = remote
+			}
+		}
 		if coord.GroupID != "" && coord.ArtifactID != "" {
 			cd.GroupID = coord.GroupID
 			cd.ArtifactID = coord.ArtifactID
 			cd.Key = coord.GroupID + ":" + coord.ArtifactID
-		} else {
-			// fallback heuristic already applied in CollectJavaImports (group:artifact)
 		}
 
-		// Try syft for version using key or artifact
-		version := ""
-		if v, ok := syftMap[cd.Key]; ok && v != "" {
-			version = v
-		} else if v, ok := syftMap[cd.ArtifactID]; ok && v != "" {
-			version = v
+		// A version already present (e.g. pinned via an embedded
+		// pom.properties) wins over everything else. Otherwise try the
+		// local Maven cache, then fall back to Syft.
+		version := cd.Version
+		if version == "" {
+			var fromLocal bool
+			version, fromLocal = h.resolveFromMavenLocal(projectDir, cd.GroupID, cd.ArtifactID)
+			if !fromLocal {
+				if v, ok := syftMap[cd.Key]; ok && v != "" {
+					version = v
+				} else if v, ok := syftMap[cd.ArtifactID]; ok && v != "" {
+					version = v
+				}
+				if version != "" {
+					_ = utils.AppendLog(projectDir, "[JavaHandler] Using version from Syft for %s: %s", cd.Key, version)
+				} else if v, ok := h.resolveVersionRemote(projectDir, cd.GroupID, cd.ArtifactID); ok {
+					version = v
+				}
+			}
 		}
 
 		// Leave version empty if unknown (ORT will mark unknown) and log a note
@@ -174,7 +231,6 @@ func (h *JavaHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 			_ = utils.AppendLog(projectDir, "[JavaHandler] Version unknown for %s; leaving empty so ORT marks as unknown.", cd.Key)
 		} else {
 			cd.Version = version
-			_ = utils.AppendLog(projectDir, "[JavaHandler] Using version from Syft for %s: %s", cd.Key, version)
 		}
 
 		// final attributes
@@ -189,7 +245,18 @@ func (h *JavaHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 		_ = utils.AppendLog(projectDir, "[JavaHandler] No declared build file found; will generate a recovery manifest with %d dependencies.", len(codeDeps))
 	}
 
-	return finalDeps, nil
+	scanReport.RecordPhase("declared", len(declaredDeps))
+	scanReport.RecordPhase("scanned", len(codeDeps))
+	scanLogger.Info("parsed manifests", utils.LogKeyPhase, "declared", "deps", len(declaredDeps))
+	scanLogger.Info("scanned imports", utils.LogKeyPhase, "scanned", "deps", len(codeDeps))
+
+	if err := policy.Apply(h.Name(), "maven", projectDir, finalDeps); err != nil {
+		return nil, scanReport, err
+	}
+
+	scanReport.RecordEcosystem("maven", len(finalDeps))
+	scanLogger.Info("scan complete", utils.LogKeyPhase, "ecosystem", utils.LogKeySource, "maven", "deps", len(finalDeps))
+	return finalDeps, scanReport, nil
 }
 
 // GenerateRecoveryFile: prefer Gradle if present (and unique), else Maven; handle multi-gradle gracefully
@@ -197,6 +264,7 @@ func (h *JavaHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir,
 	// find manifests
 	pomPath := filepath.Join(projectDir, "pom.xml")
 	gradleFiles := findAllFiles(projectDir, []string{"build.gradle", "build.gradle.kts"})
+	sourceLanguage := dominantSourceLanguage(projectDir)
 
 	// If exactly one gradle file -> overwrite it (after backup)
 	if len(gradleFiles) == 1 {
@@ -206,7 +274,7 @@ func (h *JavaHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir,
 		}
 		_ = utils.AppendLog(projectDir, "[JavaHandler] Backed up %s", filepath.Base(gf))
 		kotlin := strings.HasSuffix(gf, ".kts")
-		if err := WriteGradle(gf, deps, kotlin); err != nil {
+		if err := WriteGradle(gf, deps, kotlin, sourceLanguage); err != nil {
 			_ = utils.AppendLog(projectDir, "[JavaHandler] Error writing %s: %v", gf, err)
 			return err
 		}
@@ -227,7 +295,7 @@ func (h *JavaHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir,
 		}
 		// write aggregated fallback
 		outPath := filepath.Join(projectDir, "build.recovered.gradle")
-		if err := WriteGradle(outPath, deps, false); err != nil {
+		if err := WriteGradle(outPath, deps, false, sourceLanguage); err != nil {
 			_ = utils.AppendLog(projectDir, "[JavaHandler] Error writing %s: %v", outPath, err)
 			return err
 		}
@@ -302,13 +370,244 @@ func findAllFiles(root string, names []string) []string {
 	return found
 }
 
+// ---------------------------
+// Local Maven repository resolver
+// ---------------------------
+
+// defaultMavenLocalRepo returns the conventional ~/.m2/repository path.
+func defaultMavenLocalRepo() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".m2", "repository")
+}
+
+// mavenLocalRepoPath resolves the local repository root to search, in
+// order of precedence: the handler's MavenLocalRepo field, the
+// ORT_RECOVERY_MAVEN_LOCAL env var, then ~/.m2/repository.
+func (h *JavaHandler) mavenLocalRepoPath() string {
+	if h.MavenLocalRepo != "" {
+		return h.MavenLocalRepo
+	}
+	if env := os.Getenv("ORT_RECOVERY_MAVEN_LOCAL"); env != "" {
+		return env
+	}
+	return defaultMavenLocalRepo()
+}
+
+// resolveFromMavenLocal looks up groupID:artifactID under the local Maven
+// repository and returns the highest available version, per Maven's
+// version ordering rules. Directory listings are cached per
+// (groupID, artifactID) on the handler so large projects only stat each
+// artifact directory once.
+func (h *JavaHandler) resolveFromMavenLocal(projectDir, groupID, artifactID string) (string, bool) {
+	if groupID == "" || artifactID == "" {
+		return "", false
+	}
+	key := groupID + ":" + artifactID
+
+	if h.mavenLocalCache == nil {
+		h.mavenLocalCache = make(map[string][]string)
+	}
+	versions, cached := h.mavenLocalCache[key]
+	if !cached {
+		versions = listMavenLocalVersions(h.mavenLocalRepoPath(), groupID, artifactID)
+		h.mavenLocalCache[key] = versions
+	}
+	if len(versions) == 0 {
+		return "", false
+	}
+
+	best := versions[0]
+	for _, v := range versions[1:] {
+		if compareMavenVersions(v, best) > 0 {
+			best = v
+		}
+	}
+
+	_ = utils.AppendLog(projectDir, "[JavaHandler] Resolved %s from local Maven cache: %s", key, best)
+	return best, true
+}
+
+// listMavenLocalVersions lists the version subdirectories under
+// "<repoRoot>/<groupID-with-slashes>/<artifactID>/".
+func listMavenLocalVersions(repoRoot, groupID, artifactID string) []string {
+	if repoRoot == "" {
+		return nil
+	}
+	artifactDir := filepath.Join(repoRoot, filepath.FromSlash(strings.ReplaceAll(groupID, ".", "/")), artifactID)
+	entries, err := os.ReadDir(artifactDir)
+	if err != nil {
+		return nil
+	}
+	var versions []string
+	for _, e := range entries {
+		if e.IsDir() {
+			versions = append(versions, e.Name())
+		}
+	}
+	return versions
+}
+
+// mavenVersionQualifierRank ranks well-known Maven qualifiers from lowest
+// to highest; anything else ranks between "rc" and a plain release.
+var mavenVersionQualifierRank = map[string]int{
+	"snapshot":  0,
+	"alpha":     1,
+	"a":         1,
+	"beta":      2,
+	"b":         2,
+	"milestone": 3,
+	"m":         3,
+	"rc":        4,
+	"cr":        4,
+	"sp":        6,
+}
+
+// compareMavenVersions orders two Maven version strings the way Maven's
+// own ComparableVersion does for the common case: numeric segments
+// (split on '.', '-', '_') compare numerically, and a trailing qualifier
+// segment (SNAPSHOT, alpha/a, beta/b, milestone/m, rc/cr, sp, or anything
+// else unrecognized) ranks below a plain release, in that order. Returns
+// -1, 0, or 1.
+func compareMavenVersions(a, b string) int {
+	aSegs := splitMavenVersion(a)
+	bSegs := splitMavenVersion(b)
+
+	for i := 0; i < len(aSegs) || i < len(bSegs); i++ {
+		var as, bs string
+		if i < len(aSegs) {
+			as = aSegs[i]
+		}
+		if i < len(bSegs) {
+			bs = bSegs[i]
+		}
+		if c := compareMavenSegment(as, bs); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// splitMavenVersion splits a version string on '.', '-', and '_', the
+// separators Maven treats as segment boundaries.
+func splitMavenVersion(v string) []string {
+	return strings.FieldsFunc(v, func(r rune) bool {
+		return r == '.' || r == '-' || r == '_'
+	})
+}
+
+// compareMavenSegment compares a single version segment from each side.
+// A missing segment (empty string) sorts below a present one, numeric
+// segments compare numerically against each other, and numeric segments
+// always outrank qualifiers (a qualified version is always older than
+// the same numeric release, e.g. "1.0-rc1" < "1.0"). Two qualifiers
+// compare via mavenVersionQualifierRank, falling back to a lexical
+// comparison for anything not in that table.
+func compareMavenSegment(a, b string) int {
+	if a == b {
+		return 0
+	}
+	aNum, aIsNum := parseUintSegment(a)
+	bNum, bIsNum := parseUintSegment(b)
+	switch {
+	case aIsNum && bIsNum:
+		switch {
+		case aNum < bNum:
+			return -1
+		case aNum > bNum:
+			return 1
+		default:
+			return 0
+		}
+	case aIsNum:
+		return 1
+	case bIsNum:
+		return -1
+	}
+
+	aRank, aKnown := mavenVersionQualifierRank[strings.ToLower(a)]
+	bRank, bKnown := mavenVersionQualifierRank[strings.ToLower(b)]
+	if a == "" {
+		aRank, aKnown = -1, true
+	}
+	if b == "" {
+		bRank, bKnown = -1, true
+	}
+	if aKnown && bKnown {
+		switch {
+		case aRank < bRank:
+			return -1
+		case aRank > bRank:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func parseUintSegment(s string) (uint64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	var n uint64
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+		n = n*10 + uint64(r-'0')
+	}
+	return n, true
+}
+
 // ---------------------------
 // Maven Helpers
 // ---------------------------
 
 type Project struct {
-	XMLName      xml.Name   `xml:"project"`
-	Dependencies []MavenDep `xml:"dependencies>dependency"`
+	XMLName       xml.Name   `xml:"project"`
+	GroupID       string     `xml:"groupId"`
+	ArtifactID    string     `xml:"artifactId"`
+	Version       string     `xml:"version"`
+	Parent        *PomParent `xml:"parent"`
+	Properties    PomProps   `xml:"properties"`
+	Dependencies  []MavenDep `xml:"dependencies>dependency"`
+	DepManagement []MavenDep `xml:"dependencyManagement>dependencies>dependency"`
+}
+
+// PomParent is the <parent> coordinate a POM inherits from.
+type PomParent struct {
+	GroupID      string `xml:"groupId"`
+	ArtifactID   string `xml:"artifactId"`
+	Version      string `xml:"version"`
+	RelativePath string `xml:"relativePath"`
+}
+
+// PomProps decodes the free-form <properties> block into a plain map.
+type PomProps map[string]string
+
+func (p *PomProps) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*p = map[string]string{}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var value string
+			if err := d.DecodeElement(&value, &t); err != nil {
+				return err
+			}
+			(*p)[t.Name.Local] = value
+		case xml.EndElement:
+			if t.Name == start.Name {
+				return nil
+			}
+		}
+	}
 }
 
 type MavenDep struct {
@@ -318,31 +617,224 @@ type MavenDep struct {
 	Scope      string `xml:"scope,omitempty"`
 }
 
-func ParsePom(pomPath string) ([]utils.Dependency, error) {
-	if _, err := os.Stat(pomPath); os.IsNotExist(err) {
-		return []utils.Dependency{}, nil
+// MavenRepoBaseURL is the Maven repository parent POMs are fetched from
+// when they can't be found on disk via <relativePath>. Overridable for
+// internal/mirrored repositories.
+var MavenRepoBaseURL = "https://repo1.maven.org/maven2"
+
+// propertyPlaceholderRegex matches Maven's "${property}" interpolation
+// syntax.
+var propertyPlaceholderRegex = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// interpolateProperty substitutes every "${...}" placeholder in s using
+// props, leaving any placeholder with no matching property untouched.
+func interpolateProperty(s string, props map[string]string) string {
+	if !strings.Contains(s, "${") {
+		return s
 	}
+	return propertyPlaceholderRegex.ReplaceAllStringFunc(s, func(match string) string {
+		name := match[2 : len(match)-1]
+		if v, ok := props[name]; ok {
+			return v
+		}
+		return match
+	})
+}
+
+// mergedPom is the flattened result of walking a POM's <parent> chain and
+// any <dependencyManagement><scope>import</scope> BOMs it references.
+type mergedPom struct {
+	properties      map[string]string
+	managedVersions map[string]string // "groupId:artifactId" -> version
+	dependencies    []MavenDep
+}
+
+// resolvePomHierarchy reads pomPath, then recursively loads its <parent>
+// chain (first via relativePath on disk, falling back to fetching the
+// parent POM from MavenRepoBaseURL) and any BOMs pulled in via
+// <dependencyManagement> with <scope>import</scope>, accumulating
+// properties and managed versions along the way. Properties already set
+// by a closer (child) POM are never overwritten by an ancestor's value.
+// A visited set guards against cycles in the parent chain or repeated BOM
+// coordinates.
+func resolvePomHierarchy(pomPath string, visited map[string]bool) (*mergedPom, *Project, error) {
 	data, err := os.ReadFile(pomPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read pom.xml: %v", err)
+		return nil, nil, fmt.Errorf("failed to read %s: %v", pomPath, err)
 	}
+	return parsePomBytes(data, pomPath, visited)
+}
+
+// parsePomBytes is resolvePomHierarchy for POM content already in memory
+// (an on-disk read, or a parent/BOM fetched over HTTP), sharing the same
+// merge logic for both. pomPath is only used to resolve a further
+// <relativePath> beneath it - for a fetched POM this is the URL it came
+// from, so a nested <relativePath> naturally misses on disk and falls
+// through to another HTTP fetch, which is what we want.
+func parsePomBytes(data []byte, pomPath string, visited map[string]bool) (*mergedPom, *Project, error) {
 	var project Project
 	if err := xml.Unmarshal(data, &project); err != nil {
-		return nil, fmt.Errorf("invalid pom.xml: %v", err)
+		return nil, nil, fmt.Errorf("invalid pom at %s: %v", pomPath, err)
+	}
+
+	merged := &mergedPom{
+		properties:      map[string]string{},
+		managedVersions: map[string]string{},
+	}
+
+	// Load the parent chain first so closer (child) values win when merged.
+	if project.Parent != nil {
+		coord := project.Parent.GroupID + ":" + project.Parent.ArtifactID + ":" + project.Parent.Version
+		if !visited[coord] {
+			visited[coord] = true
+			parentData, parentPath, err := loadParentPom(pomPath, project.Parent)
+			if err == nil {
+				parentMerged, parentProject, err := parsePomBytes(parentData, parentPath, visited)
+				if err == nil {
+					for k, v := range parentMerged.properties {
+						merged.properties[k] = v
+					}
+					for k, v := range parentMerged.managedVersions {
+						merged.managedVersions[k] = v
+					}
+					_ = parentProject
+				}
+			}
+		}
+	}
+
+	// This POM's own properties, plus the well-known Maven reflective
+	// properties, take precedence over anything inherited.
+	for k, v := range project.Properties {
+		merged.properties[k] = v
+	}
+	if project.Version != "" {
+		merged.properties["project.version"] = project.Version
+	}
+	if project.GroupID != "" {
+		merged.properties["project.groupId"] = project.GroupID
 	}
+	if project.ArtifactID != "" {
+		merged.properties["project.artifactId"] = project.ArtifactID
+	}
+
+	// Flatten dependencyManagement, including BOM imports.
+	for _, dm := range project.DepManagement {
+		group := interpolateProperty(dm.GroupID, merged.properties)
+		artifact := interpolateProperty(dm.ArtifactID, merged.properties)
+		version := interpolateProperty(dm.Version, merged.properties)
+
+		if strings.EqualFold(dm.Scope, "import") {
+			bomCoord := group + ":" + artifact + ":" + version
+			if visited[bomCoord] {
+				continue
+			}
+			visited[bomCoord] = true
+			bomData, bomPath, err := fetchPomByCoordinate(group, artifact, version)
+			if err == nil {
+				bomMerged, _, err := parsePomBytes(bomData, bomPath, visited)
+				if err == nil {
+					for k, v := range bomMerged.managedVersions {
+						if _, exists := merged.managedVersions[k]; !exists {
+							merged.managedVersions[k] = v
+						}
+					}
+				}
+			}
+			continue
+		}
+
+		key := group + ":" + artifact
+		if _, exists := merged.managedVersions[key]; !exists && version != "" {
+			merged.managedVersions[key] = version
+		}
+	}
+
+	merged.dependencies = project.Dependencies
+	return merged, &project, nil
+}
+
+// loadParentPom finds a parent POM on disk via <relativePath> (default
+// "../pom.xml" when omitted), falling back to fetching it from
+// MavenRepoBaseURL when no local copy exists.
+func loadParentPom(childPomPath string, parent *PomParent) ([]byte, string, error) {
+	relPath := parent.RelativePath
+	if relPath == "" {
+		relPath = "../pom.xml"
+	}
+	candidate := filepath.Join(filepath.Dir(childPomPath), relPath)
+	if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(candidate)
+		return data, candidate, err
+	}
+
+	return fetchPomByCoordinate(parent.GroupID, parent.ArtifactID, parent.Version)
+}
+
+// fetchPomByCoordinate downloads "<groupId>/<artifactId>/<version>/<artifactId>-<version>.pom"
+// from MavenRepoBaseURL, the same layout Syft's recursivelyFindVersionFromParentPom uses.
+func fetchPomByCoordinate(groupID, artifactID, version string) ([]byte, string, error) {
+	if groupID == "" || artifactID == "" || version == "" {
+		return nil, "", fmt.Errorf("incomplete coordinate %s:%s:%s", groupID, artifactID, version)
+	}
+	groupPath := strings.ReplaceAll(groupID, ".", "/")
+	url := fmt.Sprintf("%s/%s/%s/%s/%s-%s.pom", MavenRepoBaseURL, groupPath, artifactID, version, artifactID, version)
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, url, fmt.Errorf("failed to fetch %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, url, fmt.Errorf("fetching %s returned status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, url, fmt.Errorf("failed to read response body for %s: %v", url, err)
+	}
+	return data, url, nil
+}
+
+// ParsePom parses pom.xml into a flat dependency list, walking the
+// <parent> chain (on disk, then over HTTP from MavenRepoBaseURL) and any
+// BOMs pulled in via <dependencyManagement><scope>import</scope> so that
+// properties and managed versions from the whole hierarchy are available.
+// Every dependency's groupId/artifactId/version/scope is then substituted
+// through the merged property map before being returned, and any
+// dependency with no explicit version is resolved from the merged
+// dependencyManagement map.
+func ParsePom(pomPath string) ([]utils.Dependency, error) {
+	if _, err := os.Stat(pomPath); os.IsNotExist(err) {
+		return []utils.Dependency{}, nil
+	}
+
+	merged, _, err := resolvePomHierarchy(pomPath, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
 	var deps []utils.Dependency
-	for _, d := range project.Dependencies {
-		key := d.GroupID + ":" + d.ArtifactID
-		scope := d.Scope
+	for _, d := range merged.dependencies {
+		group := interpolateProperty(d.GroupID, merged.properties)
+		artifact := interpolateProperty(d.ArtifactID, merged.properties)
+		version := interpolateProperty(d.Version, merged.properties)
+		scope := interpolateProperty(d.Scope, merged.properties)
+
+		if version == "" {
+			if managed, ok := merged.managedVersions[group+":"+artifact]; ok {
+				version = managed
+			}
+		}
 		if scope == "" {
 			scope = "compile"
 		}
+
 		deps = append(deps, utils.Dependency{
-			GroupID:    d.GroupID,
-			ArtifactID: d.ArtifactID,
-			Version:    d.Version,
+			GroupID:    group,
+			ArtifactID: artifact,
+			Version:    version,
 			Scope:      scope,
-			Key:        key,
+			Key:        group + ":" + artifact,
 		})
 	}
 	return deps, nil
@@ -441,13 +933,33 @@ func ParseGradle(path string) ([]utils.Dependency, error) {
 	return deps, nil
 }
 
-// WriteGradle writes a Gradle file; if kotlinDSL==true writes .kts style
-func WriteGradle(path string, deps []utils.Dependency, kotlinDSL bool) error {
+// gradlePluginID maps a dominant source language to the Gradle plugin ID
+// that compiles it, so WriteGradle applies "org.jetbrains.kotlin.jvm"
+// instead of "java" for a Kotlin-majority project, and so on. Unknown or
+// "java" values fall back to the plain "java" plugin.
+func gradlePluginID(sourceLanguage string) string {
+	switch sourceLanguage {
+	case "kotlin":
+		return "org.jetbrains.kotlin.jvm"
+	case "scala":
+		return "scala"
+	case "groovy":
+		return "groovy"
+	default:
+		return "java"
+	}
+}
+
+// WriteGradle writes a Gradle file; if kotlinDSL==true writes .kts style.
+// sourceLanguage (as returned by dominantSourceLanguage) selects the
+// applied plugin, e.g. "org.jetbrains.kotlin.jvm" for a Kotlin project.
+func WriteGradle(path string, deps []utils.Dependency, kotlinDSL bool, sourceLanguage string) error {
+	pluginID := gradlePluginID(sourceLanguage)
 	var b strings.Builder
 	if kotlinDSL {
-		b.WriteString("plugins {\n    id(\"java\")\n}\n\nrepositories {\n    mavenCentral()\n}\n\ndependencies {\n")
+		b.WriteString(fmt.Sprintf("plugins {\n    id(\"%s\")\n}\n\nrepositories {\n    mavenCentral()\n}\n\ndependencies {\n", pluginID))
 	} else {
-		b.WriteString("plugins {\n    id 'java'\n}\n\nrepositories {\n    mavenCentral()\n}\n\ndependencies {\n")
+		b.WriteString(fmt.Sprintf("plugins {\n    id '%s'\n}\n\nrepositories {\n    mavenCentral()\n}\n\ndependencies {\n", pluginID))
 	}
 
 	seen := make(map[string]struct{})
@@ -541,8 +1053,6 @@ type JavaImport struct {
 
 // CollectJavaImports scans recursively and returns a list of JavaImport (unique by key)
 func CollectJavaImports(projectDir string) ([]utils.Dependency, error) {
-	importRegex := regexp.MustCompile(`^import\s+([a-zA-Z0-9_\.]+)(\.\*)?;`)
-
 	found := make(map[string]JavaImport)
 	// prefer src/** but fall back to repo-wide
 	searchRoots := []string{
@@ -558,7 +1068,8 @@ func CollectJavaImports(projectDir string) ([]utils.Dependency, error) {
 			if d.IsDir() {
 				return nil
 			}
-			if !strings.HasSuffix(p, ".java") {
+			extract, ok := languageImportExtractors[filepath.Ext(p)]
+			if !ok {
 				return nil
 			}
 			// avoid scanning same file twice if projectDir==src
@@ -575,42 +1086,41 @@ func CollectJavaImports(projectDir string) ([]utils.Dependency, error) {
 			scanner := bufio.NewScanner(f)
 			for scanner.Scan() {
 				line := strings.TrimSpace(scanner.Text())
-				if strings.HasPrefix(line, "import ") {
-					if m := importRegex.FindStringSubmatch(line); len(m) >= 2 {
-						ip := m[1] // package path
-						// map to coordinate or heuristic
-						coord := MapImportToCoordinate(ip)
-						key := ""
-						if coord.GroupID != "" && coord.ArtifactID != "" {
-							key = coord.GroupID + ":" + coord.ArtifactID
-						} else {
-							// fallback heuristic: group = first two tokens if available, artifact = third; else first:second
-							parts := strings.Split(ip, ".")
-							if len(parts) >= 3 {
-								group := strings.Join(parts[:2], ".")
-								artifact := parts[2]
-								key = group + ":" + artifact
-								coord.GroupID = group
-								coord.ArtifactID = artifact
-							} else if len(parts) >= 2 {
-								group := parts[0]
-								artifact := parts[1]
-								key = group + ":" + artifact
-								coord.GroupID = group
-								coord.ArtifactID = artifact
-							} else {
-								// give up
-								continue
-							}
-						}
-						if _, ok := found[key]; !ok {
-							found[key] = JavaImport{
-								ImportPath: ip,
-								GroupID:    coord.GroupID,
-								ArtifactID: coord.ArtifactID,
-								Key:        key,
-							}
-						}
+				ip, ok := extract(line)
+				if !ok {
+					continue
+				}
+				// map to coordinate or heuristic
+				coord := MapImportToCoordinate(ip)
+				key := ""
+				if coord.GroupID != "" && coord.ArtifactID != "" {
+					key = coord.GroupID + ":" + coord.ArtifactID
+				} else {
+					// fallback heuristic: group = first two tokens if available, artifact = third; else first:second
+					parts := strings.Split(ip, ".")
+					if len(parts) >= 3 {
+						group := strings.Join(parts[:2], ".")
+						artifact := parts[2]
+						key = group + ":" + artifact
+						coord.GroupID = group
+						coord.ArtifactID = artifact
+					} else if len(parts) >= 2 {
+						group := parts[0]
+						artifact := parts[1]
+						key = group + ":" + artifact
+						coord.GroupID = group
+						coord.ArtifactID = artifact
+					} else {
+						// give up
+						continue
+					}
+				}
+				if _, ok := found[key]; !ok {
+					found[key] = JavaImport{
+						ImportPath: ip,
+						GroupID:    coord.GroupID,
+						ArtifactID: coord.ArtifactID,
+						Key:        key,
 					}
 				}
 			}
@@ -631,6 +1141,421 @@ func CollectJavaImports(projectDir string) ([]utils.Dependency, error) {
 	return deps, nil
 }
 
+// ---------------------------
+// Multi-language import extraction (Java, Kotlin, Scala, Groovy)
+// ---------------------------
+
+// languageImportExtractors maps a source file extension to the function
+// that pulls an imported package path out of a single line of that
+// language, so CollectJavaImports and Detect can treat every JVM
+// language uniformly instead of special-casing ".java".
+var languageImportExtractors = map[string]func(line string) (string, bool){
+	".java":   extractJavaImport,
+	".kt":     extractKotlinImport,
+	".kts":    extractKotlinImport,
+	".scala":  extractScalaImport,
+	".groovy": extractGroovyImport,
+}
+
+var javaImportRegex = regexp.MustCompile(`^import\s+([a-zA-Z0-9_\.]+)(\.\*)?;`)
+
+// extractJavaImport pulls the package path out of a Java "import a.b.C;"
+// (or "import a.b.*;") statement.
+func extractJavaImport(line string) (string, bool) {
+	if !strings.HasPrefix(line, "import ") {
+		return "", false
+	}
+	m := javaImportRegex.FindStringSubmatch(line)
+	if len(m) < 2 {
+		return "", false
+	}
+	return m[1], true
+}
+
+var kotlinImportRegex = regexp.MustCompile(`^import\s+([a-zA-Z0-9_\.]+)(\.\*)?\s*$`)
+
+// extractKotlinImport pulls the package path out of a Kotlin "import a.b.C"
+// statement. Kotlin has no trailing semicolon and allows "as" aliases,
+// which are stripped before matching.
+func extractKotlinImport(line string) (string, bool) {
+	if !strings.HasPrefix(line, "import ") {
+		return "", false
+	}
+	if idx := strings.Index(line, " as "); idx != -1 {
+		line = line[:idx]
+	}
+	m := kotlinImportRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if len(m) < 2 {
+		return "", false
+	}
+	return m[1], true
+}
+
+var groovyImportRegex = regexp.MustCompile(`^import\s+(?:static\s+)?([a-zA-Z0-9_\.]+)(\.\*)?\s*$`)
+
+// extractGroovyImport pulls the package path out of a Groovy "import a.b.C"
+// (optionally "import static ...") statement.
+func extractGroovyImport(line string) (string, bool) {
+	if !strings.HasPrefix(line, "import ") {
+		return "", false
+	}
+	m := groovyImportRegex.FindStringSubmatch(strings.TrimSpace(line))
+	if len(m) < 2 {
+		return "", false
+	}
+	return m[1], true
+}
+
+// extractScalaImport pulls a package path out of a Scala "import a.b.C"
+// statement, including the selector-list ("import a.b.{C, D}") and
+// wildcard ("import a.b._") forms Scala uses in place of Java's ".*".
+// Selector lists resolve to their enclosing package, since the individual
+// selected names aren't independent artifacts to map to coordinates.
+func extractScalaImport(line string) (string, bool) {
+	if !strings.HasPrefix(line, "import ") {
+		return "", false
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(line, "import "))
+	if idx := strings.IndexByte(rest, '{'); idx != -1 {
+		rest = rest[:idx]
+	}
+	rest = strings.TrimSuffix(rest, ".")
+	rest = strings.TrimSuffix(rest, "._")
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return "", false
+	}
+	return rest, true
+}
+
+// dominantSourceLanguage walks projectDir counting JVM-language source
+// files and returns whichever language has the most matches ("java",
+// "kotlin", "scala", or "groovy"), defaulting to "java" when none are
+// found. GenerateRecoveryFile uses this to pick the right Gradle plugin
+// for a freshly written build file.
+func dominantSourceLanguage(projectDir string) string {
+	counts := map[string]int{"java": 0, "kotlin": 0, "scala": 0, "groovy": 0}
+	_ = filepath.WalkDir(projectDir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(p) {
+		case ".java":
+			counts["java"]++
+		case ".kt", ".kts":
+			counts["kotlin"]++
+		case ".scala":
+			counts["scala"]++
+		case ".groovy":
+			counts["groovy"]++
+		}
+		return nil
+	})
+
+	best := "java"
+	bestCount := counts["java"]
+	for _, lang := range []string{"kotlin", "scala", "groovy"} {
+		if counts[lang] > bestCount {
+			best = lang
+			bestCount = counts[lang]
+		}
+	}
+	return best
+}
+
+// ---------------------------
+// Binary/JAR import recovery (source-less projects)
+// ---------------------------
+
+// binaryArtifactExts are the compiled-Java file extensions that trigger
+// binary import recovery in Scan.
+var binaryArtifactExts = []string{".jar", ".war", ".ear", ".class"}
+
+// countJavaAndBinaryFiles walks projectDir once and counts plain .java
+// sources against compiled artifacts (.class, .jar, .war, .ear), so Scan
+// can decide whether this looks like a source checkout or a shipped
+// binary with little or no matching source.
+func countJavaAndBinaryFiles(projectDir string) (javaFiles, binaryFiles int) {
+	_ = filepath.WalkDir(projectDir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if strings.HasSuffix(p, ".java") {
+			javaFiles++
+			return nil
+		}
+		for _, ext := range binaryArtifactExts {
+			if strings.HasSuffix(p, ext) {
+				binaryFiles++
+				return nil
+			}
+		}
+		return nil
+	})
+	return javaFiles, binaryFiles
+}
+
+// CollectJavaImportsFromBinaries scans .class files and .jar/.war/.ear
+// archives for dependency evidence when a project ships compiled Java
+// without matching sources. It combines three signals:
+//   - an embedded META-INF/maven/<groupId>/<artifactId>/pom.properties,
+//     which gives an exact groupId/artifactId/version;
+//   - a jar's META-INF/MANIFEST.MF Bundle-SymbolicName, mapped to a
+//     coordinate the same way a source import is; and
+//   - CONSTANT_Class entries (tag 7) in the constant pool of loose
+//     .class files, resolved via MapImportToCoordinate.
+//
+// WAR/EAR archives are recursed into via their WEB-INF/lib nested jars.
+// A dependency pinned via pom.properties always wins over the same
+// coordinate found only through a manifest or constant-pool reference.
+func CollectJavaImportsFromBinaries(projectDir string) ([]utils.Dependency, error) {
+	pinned := make(map[string]utils.Dependency)
+	found := make(map[string]utils.Dependency)
+
+	walkErr := filepath.WalkDir(projectDir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		switch {
+		case strings.HasSuffix(p, ".jar"), strings.HasSuffix(p, ".war"), strings.HasSuffix(p, ".ear"):
+			data, rerr := os.ReadFile(p)
+			if rerr != nil {
+				return nil
+			}
+			scanJavaArchive(data, pinned, found)
+		case strings.HasSuffix(p, ".class"):
+			data, rerr := os.ReadFile(p)
+			if rerr != nil {
+				return nil
+			}
+			for _, ip := range classConstantPoolImports(data) {
+				addBinaryImport(found, ip)
+			}
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return nil, fmt.Errorf("failed to scan %s for compiled Java artifacts: %v", projectDir, walkErr)
+	}
+
+	deps := make([]utils.Dependency, 0, len(pinned)+len(found))
+	for key, dep := range pinned {
+		deps = append(deps, dep)
+		delete(found, key)
+	}
+	for _, dep := range found {
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// scanJavaArchive reads a jar/war/ear already in memory and records its
+// embedded pom.properties (into pinned) and manifest Bundle-SymbolicName
+// (into found), recursing into any WEB-INF/lib/*.jar it bundles. A
+// corrupt or non-zip archive is skipped rather than failing the scan -
+// one bad jar in a shipped artifact shouldn't abort the recovery.
+func scanJavaArchive(data []byte, pinned, found map[string]utils.Dependency) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return
+	}
+	for _, f := range zr.File {
+		switch {
+		case f.Name == "META-INF/MANIFEST.MF":
+			if symbolicName, _ := readManifestInfo(f); symbolicName != "" {
+				addBinaryImport(found, symbolicName)
+			}
+		case strings.HasPrefix(f.Name, "META-INF/maven/") && strings.HasSuffix(f.Name, "/pom.properties"):
+			if dep, ok := readPomProperties(f); ok {
+				pinned[dep.Key] = dep
+			}
+		case strings.HasPrefix(f.Name, "WEB-INF/lib/") && strings.HasSuffix(f.Name, ".jar"):
+			rc, err := f.Open()
+			if err != nil {
+				continue
+			}
+			nested, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				continue
+			}
+			scanJavaArchive(nested, pinned, found)
+		}
+	}
+}
+
+// readManifestInfo extracts the Bundle-SymbolicName and Implementation-Vendor
+// headers from a jar's MANIFEST.MF, stripping any trailing OSGi directive
+// (e.g. ";singleton:=true") from the symbolic name.
+func readManifestInfo(f *zip.File) (symbolicName, vendor string) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", ""
+	}
+	defer rc.Close()
+	scanner := bufio.NewScanner(rc)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "Bundle-SymbolicName:"):
+			symbolicName = strings.TrimSpace(strings.TrimPrefix(line, "Bundle-SymbolicName:"))
+			if idx := strings.Index(symbolicName, ";"); idx != -1 {
+				symbolicName = symbolicName[:idx]
+			}
+		case strings.HasPrefix(line, "Implementation-Vendor:"):
+			vendor = strings.TrimSpace(strings.TrimPrefix(line, "Implementation-Vendor:"))
+		}
+	}
+	return symbolicName, vendor
+}
+
+// readPomProperties parses a jar-embedded pom.properties (plain
+// "key=value" lines) into an exact, versioned dependency.
+func readPomProperties(f *zip.File) (utils.Dependency, bool) {
+	rc, err := f.Open()
+	if err != nil {
+		return utils.Dependency{}, false
+	}
+	defer rc.Close()
+
+	props := map[string]string{}
+	scanner := bufio.NewScanner(rc)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		props[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	group, artifact, version := props["groupId"], props["artifactId"], props["version"]
+	if group == "" || artifact == "" {
+		return utils.Dependency{}, false
+	}
+	return utils.Dependency{
+		GroupID:    group,
+		ArtifactID: artifact,
+		Version:    version,
+		Scope:      "compile",
+		Key:        group + ":" + artifact,
+	}, true
+}
+
+// addBinaryImport maps importPath to a known coordinate via
+// MapImportToCoordinate and records it in found. Unlike source-import
+// scanning, it does not fall back to a first-segments heuristic when
+// there's no builtin mapping: a class file's constant pool references
+// thousands of unrelated JDK/library classes, and without the builtin
+// map as a filter that noise would swamp any real findings.
+func addBinaryImport(found map[string]utils.Dependency, importPath string) {
+	if isJDKPackage(importPath) {
+		return
+	}
+	coord := MapImportToCoordinate(importPath)
+	if coord.GroupID == "" || coord.ArtifactID == "" {
+		return
+	}
+	key := coord.GroupID + ":" + coord.ArtifactID
+	if _, ok := found[key]; ok {
+		return
+	}
+	found[key] = utils.Dependency{
+		GroupID:    coord.GroupID,
+		ArtifactID: coord.ArtifactID,
+		Version:    "",
+		Scope:      "compile",
+		Key:        key,
+	}
+}
+
+// isJDKPackage reports whether importPath belongs to the JDK itself
+// (java.*, javax.*, jdk.*, sun.*), which is never worth mapping to a
+// third-party coordinate.
+func isJDKPackage(importPath string) bool {
+	for _, prefix := range []string{"java.", "javax.", "jdk.", "sun.", "com.sun."} {
+		if strings.HasPrefix(importPath, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// classConstantPoolImports decodes a .class file's constant pool far
+// enough to collect every CONSTANT_Class (tag 7) entry's name, resolved
+// through its CONSTANT_Utf8 (tag 1) entry and converted from JVM
+// internal form ("org/foo/Bar") to a dotted fully-qualified class name.
+// It returns nil for anything that doesn't parse as a well-formed class
+// file rather than risk misreading the rest of the pool.
+func classConstantPoolImports(data []byte) []string {
+	if len(data) < 10 || binary.BigEndian.Uint32(data[0:4]) != 0xCAFEBABE {
+		return nil
+	}
+	count := int(binary.BigEndian.Uint16(data[8:10]))
+	offset := 10
+
+	utf8s := make(map[int]string, count)
+	var classNameIndexes []int
+
+	for i := 1; i < count; i++ {
+		if offset >= len(data) {
+			return nil
+		}
+		tag := data[offset]
+		offset++
+		switch tag {
+		case 1: // CONSTANT_Utf8: u2 length + that many bytes
+			if offset+2 > len(data) {
+				return nil
+			}
+			length := int(binary.BigEndian.Uint16(data[offset : offset+2]))
+			offset += 2
+			if offset+length > len(data) {
+				return nil
+			}
+			utf8s[i] = string(data[offset : offset+length])
+			offset += length
+		case 7: // CONSTANT_Class: u2 name_index
+			if offset+2 > len(data) {
+				return nil
+			}
+			classNameIndexes = append(classNameIndexes, int(binary.BigEndian.Uint16(data[offset:offset+2])))
+			offset += 2
+		case 8, 16, 19, 20: // String, MethodType, Module, Package: u2
+			offset += 2
+		case 15: // MethodHandle: u1 + u2
+			offset += 3
+		case 3, 4, 9, 10, 11, 12, 17, 18: // Integer, Float, *ref, NameAndType, Dynamic, InvokeDynamic: u4
+			offset += 4
+		case 5, 6: // Long, Double: u8, and occupy two constant-pool entries
+			offset += 8
+			i++
+		default:
+			// Unrecognized tag: stop rather than risk misreading the pool.
+			return nil
+		}
+	}
+
+	seen := make(map[string]bool, len(classNameIndexes))
+	var imports []string
+	for _, idx := range classNameIndexes {
+		name, ok := utf8s[idx]
+		if !ok || name == "" {
+			continue
+		}
+		fqcn := strings.ReplaceAll(name, "/", ".")
+		if seen[fqcn] {
+			continue
+		}
+		seen[fqcn] = true
+		imports = append(imports, fqcn)
+	}
+	return imports
+}
+
 // ---------------------------
 // Mapping layer for imports -> Maven coordinates
 // ---------------------------
@@ -666,6 +1591,15 @@ var builtinImportMap = map[string]MapEntry{
 	"org.joda.time":                    {GroupID: "joda-time", ArtifactID: "joda-time"},
 	"org.mockito":                      {GroupID: "org.mockito", ArtifactID: "mockito-core"},
 	"com.zaxxer":                       {GroupID: "com.zaxxer", ArtifactID: "HikariCP"},
+
+	// Kotlin/Scala/Groovy standard libraries and common ecosystem packages.
+	"kotlin":                {GroupID: "org.jetbrains.kotlin", ArtifactID: "kotlin-stdlib"},
+	"kotlinx.coroutines":    {GroupID: "org.jetbrains.kotlinx", ArtifactID: "kotlinx-coroutines-core"},
+	"kotlinx.serialization": {GroupID: "org.jetbrains.kotlinx", ArtifactID: "kotlinx-serialization-json"},
+	"scala":                 {GroupID: "org.scala-lang", ArtifactID: "scala-library"},
+	"cats":                  {GroupID: "org.typelevel", ArtifactID: "cats-core"},
+	"zio":                   {GroupID: "dev.zio", ArtifactID: "zio"},
+	"groovy":                {GroupID: "org.codehaus.groovy", ArtifactID: "groovy"},
 }
 
 // MapImportToCoordinate looks up builtin map and returns best-effort coords
@@ -681,3 +1615,15 @@ func MapImportToCoordinate(importPath string) MapEntry {
 	// no builtin mapping found
 	return MapEntry{}
 }
+
+// EmitSBOM writes deps to w as an SBOM in the given format ("cyclonedx" or
+// "spdx"); see utils/sbom.
+func (h *JavaHandler) EmitSBOM(deps []utils.Dependency, w io.Writer, format string) error {
+	return sbom.Emit(deps, w, format)
+}
+
+// ExportSBOM writes deps to outPath as an SBOM in the given format
+// ("cyclonedx" or "spdx"); see utils/sbom.ExportToFile.
+func (h *JavaHandler) ExportSBOM(deps []utils.Dependency, format, outPath, projectDir string) error {
+	return sbom.ExportToFile(deps, format, outPath, projectDir)
+}
@@ -0,0 +1,418 @@
+package javahandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ort-recovery/utils"
+)
+
+// CoordinateResolver looks up Maven coordinates from a remote source when
+// builtinImportMap has no mapping for an import, and the latest known
+// version of a coordinate when neither the local Maven cache nor Syft
+// have one. "Not found" should be reported as a zero value with a nil
+// error; only unexpected failures (network, malformed response) should
+// be returned as an error.
+type CoordinateResolver interface {
+	// Name identifies the resolver in log lines, e.g. "MavenCentral".
+	Name() string
+	// ResolveCoordinate discovers the groupId:artifactId that provides importPath.
+	ResolveCoordinate(importPath string) (MapEntry, error)
+	// ResolveLatestVersion returns the latest known version of group:artifact.
+	ResolveLatestVersion(group, artifact string) (string, error)
+}
+
+// onlineEnabled gates every CoordinateResolver network call. Many ORT
+// users run air-gapped, so remote resolution is opt-in via SetOnline
+// (wired to the --online flag) or the ORT_RECOVERY_ONLINE=1 env var.
+var onlineEnabled = os.Getenv("ORT_RECOVERY_ONLINE") == "1"
+
+// SetOnline overrides the process-wide online flag (mirrors
+// policy.SetMode for the policy engine).
+func SetOnline(v bool) { onlineEnabled = v }
+
+// Online reports whether remote coordinate/version resolution is enabled.
+func Online() bool { return onlineEnabled }
+
+// resolversOrDefault returns h.Resolvers when the caller configured any,
+// otherwise the built-in chain: Maven Central first, then an Artifactory
+// resolver when ORT_RECOVERY_ARTIFACTORY_URL is set.
+func (h *JavaHandler) resolversOrDefault() []CoordinateResolver {
+	if h.Resolvers != nil {
+		return h.Resolvers
+	}
+	resolvers := []CoordinateResolver{&MavenCentralResolver{}}
+	if base := os.Getenv("ORT_RECOVERY_ARTIFACTORY_URL"); base != "" {
+		resolvers = append(resolvers, &ArtifactoryResolver{
+			BaseURL: base,
+			Token:   os.Getenv("ORT_RECOVERY_ARTIFACTORY_TOKEN"),
+		})
+	}
+	return resolvers
+}
+
+// ---------------------------
+// On-disk resolver cache
+// ---------------------------
+
+// resolverCacheTTL is how long a cached remote lookup (hit or miss) is
+// trusted before it's looked up again.
+const resolverCacheTTL = 24 * time.Hour
+
+// resolverCacheFile is the on-disk cache of remote lookups, kept inside
+// the project directory so cached results travel with a recovery run
+// instead of leaking machine-wide.
+const resolverCacheFile = ".ort-recovery-resolver-cache.json"
+
+type resolverCacheEntry struct {
+	Value     string    `json:"value"` // "g:a" for a coordinate lookup, a bare version for a version lookup; empty means a cached miss
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+type resolverCache struct {
+	Coordinates map[string]resolverCacheEntry `json:"coordinates"`
+	Versions    map[string]resolverCacheEntry `json:"versions"`
+}
+
+func loadResolverCache(projectDir string) *resolverCache {
+	c := &resolverCache{Coordinates: map[string]resolverCacheEntry{}, Versions: map[string]resolverCacheEntry{}}
+	data, err := os.ReadFile(filepath.Join(projectDir, resolverCacheFile))
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return &resolverCache{Coordinates: map[string]resolverCacheEntry{}, Versions: map[string]resolverCacheEntry{}}
+	}
+	if c.Coordinates == nil {
+		c.Coordinates = map[string]resolverCacheEntry{}
+	}
+	if c.Versions == nil {
+		c.Versions = map[string]resolverCacheEntry{}
+	}
+	return c
+}
+
+func (c *resolverCache) save(projectDir string) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(projectDir, resolverCacheFile), data, 0644)
+}
+
+func (c *resolverCache) fresh(entries map[string]resolverCacheEntry, key string) (string, bool) {
+	e, ok := entries[key]
+	if !ok || time.Since(e.FetchedAt) > resolverCacheTTL {
+		return "", false
+	}
+	return e.Value, true
+}
+
+// ---------------------------
+// JavaHandler wiring
+// ---------------------------
+
+// resolveCoordinateRemote consults the resolver chain (in order) for a
+// groupId:artifactId that provides importPath, honoring the on-disk
+// cache and the online opt-in flag. It returns false when resolution is
+// disabled or every resolver misses.
+func (h *JavaHandler) resolveCoordinateRemote(projectDir, importPath string) (MapEntry, bool) {
+	if !Online() {
+		return MapEntry{}, false
+	}
+
+	cache := loadResolverCache(projectDir)
+	if cached, ok := cache.fresh(cache.Coordinates, importPath); ok {
+		if cached == "" {
+			return MapEntry{}, false
+		}
+		if group, artifact, ok := strings.Cut(cached, ":"); ok {
+			_ = utils.AppendLog(projectDir, "[JavaHandler] Resolver cache hit for %s: %s", importPath, cached)
+			return MapEntry{GroupID: group, ArtifactID: artifact}, true
+		}
+	}
+
+	for _, r := range h.resolversOrDefault() {
+		entry, err := r.ResolveCoordinate(importPath)
+		if err != nil {
+			_ = utils.AppendLog(projectDir, "[JavaHandler] %s coordinate lookup failed for %s: %v", r.Name(), importPath, err)
+			continue
+		}
+		if entry.GroupID == "" || entry.ArtifactID == "" {
+			_ = utils.AppendLog(projectDir, "[JavaHandler] %s found no coordinate for %s", r.Name(), importPath)
+			continue
+		}
+		_ = utils.AppendLog(projectDir, "[JavaHandler] %s resolved %s -> %s:%s", r.Name(), importPath, entry.GroupID, entry.ArtifactID)
+		cache.Coordinates[importPath] = resolverCacheEntry{Value: entry.GroupID + ":" + entry.ArtifactID, FetchedAt: time.Now()}
+		cache.save(projectDir)
+		return entry, true
+	}
+
+	cache.Coordinates[importPath] = resolverCacheEntry{Value: "", FetchedAt: time.Now()}
+	cache.save(projectDir)
+	return MapEntry{}, false
+}
+
+// resolveVersionRemote consults the resolver chain for the latest
+// version of group:artifact, honoring the on-disk cache and the online
+// opt-in flag. It returns false when resolution is disabled or every
+// resolver misses.
+func (h *JavaHandler) resolveVersionRemote(projectDir, group, artifact string) (string, bool) {
+	if !Online() || group == "" || artifact == "" {
+		return "", false
+	}
+	key := group + ":" + artifact
+
+	cache := loadResolverCache(projectDir)
+	if cached, ok := cache.fresh(cache.Versions, key); ok {
+		if cached == "" {
+			return "", false
+		}
+		_ = utils.AppendLog(projectDir, "[JavaHandler] Resolver cache hit for %s version: %s", key, cached)
+		return cached, true
+	}
+
+	for _, r := range h.resolversOrDefault() {
+		version, err := r.ResolveLatestVersion(group, artifact)
+		if err != nil {
+			_ = utils.AppendLog(projectDir, "[JavaHandler] %s version lookup failed for %s: %v", r.Name(), key, err)
+			continue
+		}
+		if version == "" {
+			_ = utils.AppendLog(projectDir, "[JavaHandler] %s found no version for %s", r.Name(), key)
+			continue
+		}
+		_ = utils.AppendLog(projectDir, "[JavaHandler] %s resolved %s to version %s", r.Name(), key, version)
+		cache.Versions[key] = resolverCacheEntry{Value: version, FetchedAt: time.Now()}
+		cache.save(projectDir)
+		return version, true
+	}
+
+	cache.Versions[key] = resolverCacheEntry{Value: "", FetchedAt: time.Now()}
+	cache.save(projectDir)
+	return "", false
+}
+
+// ---------------------------
+// Maven Central resolver
+// ---------------------------
+
+// MavenCentralResolver resolves coordinates and versions against Maven
+// Central's Solr-backed search API (https://search.maven.org).
+type MavenCentralResolver struct {
+	// BaseURL defaults to https://search.maven.org when empty.
+	BaseURL string
+	// HTTPClient defaults to http.DefaultClient when nil.
+	HTTPClient *http.Client
+}
+
+func (r *MavenCentralResolver) Name() string { return "MavenCentral" }
+
+func (r *MavenCentralResolver) baseURL() string {
+	if r.BaseURL != "" {
+		return r.BaseURL
+	}
+	return "https://search.maven.org"
+}
+
+func (r *MavenCentralResolver) client() *http.Client {
+	if r.HTTPClient != nil {
+		return r.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+type mavenSearchResponse struct {
+	Response struct {
+		Docs []struct {
+			GroupID    string `json:"g"`
+			ArtifactID string `json:"a"`
+			Version    string `json:"v"`
+		} `json:"docs"`
+	} `json:"response"`
+}
+
+// ResolveCoordinate queries Central's "fc" (full class name) field for
+// the artifact that provides importPath.
+func (r *MavenCentralResolver) ResolveCoordinate(importPath string) (MapEntry, error) {
+	values := url.Values{"q": {fmt.Sprintf(`fc:"%s"`, importPath)}, "rows": {"1"}, "wt": {"json"}}
+	var resp mavenSearchResponse
+	if err := getJSON(r.client(), r.baseURL()+"/solrsearch/select?"+values.Encode(), &resp); err != nil {
+		return MapEntry{}, err
+	}
+	if len(resp.Response.Docs) == 0 {
+		return MapEntry{}, nil
+	}
+	doc := resp.Response.Docs[0]
+	return MapEntry{GroupID: doc.GroupID, ArtifactID: doc.ArtifactID}, nil
+}
+
+// ResolveLatestVersion queries Central's "gav" core for the newest
+// version of group:artifact.
+func (r *MavenCentralResolver) ResolveLatestVersion(group, artifact string) (string, error) {
+	values := url.Values{
+		"q":    {fmt.Sprintf(`g:"%s" AND a:"%s"`, group, artifact)},
+		"core": {"gav"},
+		"rows": {"1"},
+		"wt":   {"json"},
+	}
+	var resp mavenSearchResponse
+	if err := getJSON(r.client(), r.baseURL()+"/solrsearch/select?"+values.Encode(), &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Response.Docs) == 0 {
+		return "", nil
+	}
+	return resp.Response.Docs[0].Version, nil
+}
+
+// getJSON performs an HTTP GET and decodes a JSON response body into out.
+func getJSON(client *http.Client, requestURL string, out interface{}) error {
+	resp, err := client.Get(requestURL)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %v", requestURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", requestURL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response from %s: %v", requestURL, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %v", requestURL, err)
+	}
+	return nil
+}
+
+// ---------------------------
+// Artifactory resolver
+// ---------------------------
+
+// ArtifactoryResolver resolves coordinates and versions against a JFrog
+// Artifactory instance's GAVC search API, the same endpoint JFrog's own
+// CLI uses for coordinate lookups.
+type ArtifactoryResolver struct {
+	// BaseURL is the Artifactory root, e.g. "https://artifactory.example.com/artifactory".
+	BaseURL string
+	// Token, when set, is sent as a Bearer token on every request.
+	Token string
+	// HTTPClient defaults to http.DefaultClient when nil.
+	HTTPClient *http.Client
+}
+
+func (r *ArtifactoryResolver) Name() string { return "Artifactory" }
+
+func (r *ArtifactoryResolver) client() *http.Client {
+	if r.HTTPClient != nil {
+		return r.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+type artifactoryGAVCResponse struct {
+	Results []struct {
+		URI string `json:"uri"`
+	} `json:"results"`
+}
+
+// gavcQuery runs a GAVC search (/api/search/gavc) with the given query
+// parameters (c=, g=, a=, v=) and returns the matched artifact URIs.
+func (r *ArtifactoryResolver) gavcQuery(values url.Values) (artifactoryGAVCResponse, error) {
+	requestURL := strings.TrimRight(r.BaseURL, "/") + "/api/search/gavc?" + values.Encode()
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return artifactoryGAVCResponse{}, fmt.Errorf("failed to build request for %s: %v", requestURL, err)
+	}
+	if r.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.Token)
+	}
+
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return artifactoryGAVCResponse{}, fmt.Errorf("request to %s failed: %v", requestURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return artifactoryGAVCResponse{}, fmt.Errorf("%s returned status %d", requestURL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return artifactoryGAVCResponse{}, fmt.Errorf("failed to read response from %s: %v", requestURL, err)
+	}
+	var result artifactoryGAVCResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return artifactoryGAVCResponse{}, fmt.Errorf("failed to parse response from %s: %v", requestURL, err)
+	}
+	return result, nil
+}
+
+// ResolveCoordinate searches by class name (c=) and recovers the
+// coordinate from the matched artifact's storage path.
+func (r *ArtifactoryResolver) ResolveCoordinate(importPath string) (MapEntry, error) {
+	result, err := r.gavcQuery(url.Values{"c": {importPath}})
+	if err != nil {
+		return MapEntry{}, err
+	}
+	if len(result.Results) == 0 {
+		return MapEntry{}, nil
+	}
+	group, artifact, _ := splitArtifactoryStorageURI(result.Results[0].URI)
+	if group == "" || artifact == "" {
+		return MapEntry{}, nil
+	}
+	return MapEntry{GroupID: group, ArtifactID: artifact}, nil
+}
+
+// ResolveLatestVersion searches by group+artifact and picks the newest
+// version by Maven version ordering, since the GAVC API doesn't
+// guarantee result order.
+func (r *ArtifactoryResolver) ResolveLatestVersion(group, artifact string) (string, error) {
+	result, err := r.gavcQuery(url.Values{"g": {group}, "a": {artifact}})
+	if err != nil {
+		return "", err
+	}
+	var best string
+	for _, res := range result.Results {
+		_, _, version := splitArtifactoryStorageURI(res.URI)
+		if version == "" {
+			continue
+		}
+		if best == "" || compareMavenVersions(version, best) > 0 {
+			best = version
+		}
+	}
+	return best, nil
+}
+
+// splitArtifactoryStorageURI extracts group, artifact, and version from
+// a GAVC search result's storage URI, of the form
+// ".../api/storage/<repoKey>/<group/path>/<artifactId>/<version>/<file>".
+func splitArtifactoryStorageURI(uri string) (group, artifact, version string) {
+	const marker = "/api/storage/"
+	idx := strings.Index(uri, marker)
+	if idx == -1 {
+		return "", "", ""
+	}
+	segments := strings.Split(uri[idx+len(marker):], "/")
+	if len(segments) < 4 {
+		// repoKey / at least one group segment / artifactId / version / file
+		return "", "", ""
+	}
+	segments = segments[:len(segments)-1] // drop the filename
+	version = segments[len(segments)-1]
+	artifact = segments[len(segments)-2]
+	groupSegments := segments[1 : len(segments)-2] // segments[0] is the repo key
+	if len(groupSegments) == 0 {
+		return "", "", ""
+	}
+	return strings.Join(groupSegments, "."), artifact, version
+}
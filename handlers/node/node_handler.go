@@ -1,16 +1,20 @@
 package nodehandler
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
 
+	"gopkg.in/yaml.v3"
+
 	"ort-recovery/utils"
+	"ort-recovery/utils/policy"
+	"ort-recovery/utils/sbom"
 )
 
 // ---------------------------
@@ -44,20 +48,22 @@ func (h *NodeHandler) Detect(projectDir string) bool {
 		if err != nil || d.IsDir() {
 			return nil
 		}
-		if strings.HasSuffix(path, ".js") ||
-			strings.HasSuffix(path, ".ts") ||
-			strings.HasSuffix(path, ".mjs") ||
-			strings.HasSuffix(path, ".cjs") {
-			found = true
-			return filepath.SkipAll
+		for _, ext := range nodeSourceExts {
+			if strings.HasSuffix(path, ext) {
+				found = true
+				return filepath.SkipAll
+			}
 		}
 		return nil
 	})
 	return found
 }
 
-// Scan checks source imports against package.json, fills missing from Syft or leaves as "latest"
-func (h *NodeHandler) Scan(projectDir string) ([]utils.Dependency, error) {
+// Scan checks source imports against package.json, fills missing versions
+// from a lockfile or SBOM, or leaves them as "latest"
+func (h *NodeHandler) Scan(projectDir string) ([]utils.Dependency, utils.ScanReport, error) {
+	scanReport := utils.NewScanReport()
+	scanLogger := utils.NewScanLogger(projectDir).With(utils.LogKeyHandler, h.Name())
 	var declaredDeps []utils.Dependency
 
 	// Parse package.json if exists
@@ -65,26 +71,36 @@ func (h *NodeHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 	if _, err := os.Stat(pkgPath); err == nil {
 		declaredDeps, err = ParsePackageJSON(pkgPath)
 		if err != nil {
-			return nil, err
+			return nil, scanReport, err
 		}
 	}
+	scanReport.RecordPhase("declared", len(declaredDeps))
+	scanLogger.Info("parsed manifest", utils.LogKeyFile, "package.json", utils.LogKeyPhase, "declared", "deps", len(declaredDeps))
 
 	// Collect imports from source
 	codeDeps, err := ParseNodeFiles(projectDir)
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
+	}
+	scanReport.RecordPhase("scanned", len(codeDeps))
+	scanLogger.Info("scanned imports", utils.LogKeyPhase, "scanned", "deps", len(codeDeps))
+
+	// Parse whichever lockfile is present, preferring the most specific
+	// resolution source (npm/yarn/pnpm all pin exact versions + integrity).
+	lockDeps := h.parseAnyLockfile(projectDir)
+	lockMap := make(map[string]utils.Dependency, len(lockDeps))
+	for _, d := range lockDeps {
+		lockMap[d.ArtifactID] = d
 	}
 
-	// Parse syft.json if exists
-	syftPath := filepath.Join(projectDir, "syft.json")
-	var syftDeps []utils.Dependency
-	if _, err := os.Stat(syftPath); err == nil {
-		data, err := os.ReadFile(syftPath)
-		if err == nil {
-			syftDeps, _ = utils.ParseSyftJSON(data, "node")
-		}
-	} else {
-		utils.AppendLog("", "[NodeHandler] WARNING: syft.json not found, versions may be incomplete")
+	// Fall back to whichever SBOM the project has - a real CycloneDX/SPDX
+	// export if one exists, otherwise the Syft scan main.go already ran.
+	sbomDeps, err := sbom.Load(projectDir)
+	if err != nil {
+		utils.AppendLog(projectDir, "[NodeHandler] WARNING: failed to load SBOM sources: %v", err)
+	}
+	if len(sbomDeps) == 0 {
+		utils.AppendLog("", "[NodeHandler] WARNING: no SBOM found, versions may be incomplete")
 	}
 
 	// Map declared
@@ -95,34 +111,93 @@ func (h *NodeHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 
 	finalDeps := declaredDeps
 
+	// If a declared dependency's manifest range can be resolved to an
+	// exact pinned version via the lockfile, prefer that (a recovered
+	// package.json shouldn't lose the pin a real lockfile already had).
+	for i, d := range finalDeps {
+		if lock, ok := lockMap[d.ArtifactID]; ok && lock.Version != "" {
+			finalDeps[i].Version = lock.Version
+			finalDeps[i].Checksum = lock.Checksum
+		}
+	}
+
 	// Add missing ones
 	for _, dep := range codeDeps {
 		if _, found := declaredMap[dep.Key]; !found {
 			version := "latest"
-			for _, s := range syftDeps {
-				if s.ArtifactID == dep.ArtifactID {
-					version = s.Version
-					break
+			source := "no resolution found, using 'latest'"
+			checksum := ""
+
+			if lock, ok := lockMap[dep.ArtifactID]; ok && lock.Version != "" {
+				version = lock.Version
+				checksum = lock.Checksum
+				source = "lockfile"
+			} else {
+				for _, s := range sbomDeps {
+					if s.ArtifactID == dep.ArtifactID {
+						version = s.Version
+						source = "SBOM"
+						break
+					}
 				}
 			}
+
 			newDep := utils.Dependency{
 				GroupID:    "npm",
 				ArtifactID: dep.ArtifactID,
 				Version:    version,
 				Scope:      "compile",
 				Key:        dep.Key,
+				Checksum:   checksum,
 			}
 			finalDeps = append(finalDeps, newDep)
 
-			if version == "latest" {
-				utils.AppendLog("", fmt.Sprintf("[NodeHandler] Added missing dependency: %s (version unknown, using 'latest')", dep.Key))
-			} else {
-				utils.AppendLog("", fmt.Sprintf("[NodeHandler] Added missing dependency: %s %s (from Syft)", dep.Key, version))
-			}
+			utils.AppendLog("", fmt.Sprintf("[NodeHandler] Added missing dependency: %s %s (resolved from %s)", dep.Key, version, source))
+		}
+	}
+
+	if err := policy.Apply(h.Name(), "npm", projectDir, finalDeps); err != nil {
+		return nil, scanReport, err
+	}
+
+	scanReport.RecordEcosystem("npm", len(finalDeps))
+	scanLogger.Info("scan complete", utils.LogKeyPhase, "ecosystem", utils.LogKeySource, "npm", "deps", len(finalDeps))
+	return finalDeps, scanReport, nil
+}
+
+// parseAnyLockfile tries package-lock.json, then yarn.lock, then
+// pnpm-lock.yaml, and returns the first one that parses successfully.
+func (h *NodeHandler) parseAnyLockfile(projectDir string) []utils.Dependency {
+	if path := filepath.Join(projectDir, "package-lock.json"); fileExists(path) {
+		deps, err := ParsePackageLock(path)
+		if err == nil {
+			utils.AppendLog(projectDir, "[NodeHandler] Resolved %d exact versions from package-lock.json", len(deps))
+			return deps
 		}
+		utils.AppendLog(projectDir, "[NodeHandler] WARNING: failed to parse package-lock.json: %v", err)
 	}
+	if path := filepath.Join(projectDir, "yarn.lock"); fileExists(path) {
+		deps, err := ParseYarnLock(path)
+		if err == nil {
+			utils.AppendLog(projectDir, "[NodeHandler] Resolved %d exact versions from yarn.lock", len(deps))
+			return deps
+		}
+		utils.AppendLog(projectDir, "[NodeHandler] WARNING: failed to parse yarn.lock: %v", err)
+	}
+	if path := filepath.Join(projectDir, "pnpm-lock.yaml"); fileExists(path) {
+		deps, err := ParsePnpmLock(path)
+		if err == nil {
+			utils.AppendLog(projectDir, "[NodeHandler] Resolved %d exact versions from pnpm-lock.yaml", len(deps))
+			return deps
+		}
+		utils.AppendLog(projectDir, "[NodeHandler] WARNING: failed to parse pnpm-lock.yaml: %v", err)
+	}
+	return nil
+}
 
-	return finalDeps, nil
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
 
 // GenerateRecoveryFile writes package.json (or creates new one) and backs up old version safely
@@ -145,6 +220,15 @@ func (h *NodeHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir,
 	}
 	utils.AppendLog("", "[NodeHandler] Wrote updated package.json")
 
+	// Also emit a CycloneDX SBOM of the recovered manifest, for downstream
+	// ORT/OSS-Review-Toolkit consumption without re-running Syft.
+	bomPath := filepath.Join(projectDir, "bom.json")
+	if err := sbom.WriteCycloneDX(deps, bomPath); err != nil {
+		utils.AppendLog("", fmt.Sprintf("[NodeHandler] WARNING: failed to write CycloneDX SBOM: %v", err))
+	} else {
+		utils.AppendLog("", "[NodeHandler] Wrote recovered dependencies as bom.json")
+	}
+
 	return nil
 }
 
@@ -215,59 +299,253 @@ func WritePackageJSON(pkgPath string, deps []utils.Dependency) error {
 	return os.WriteFile(pkgPath, data, 0644)
 }
 
-// ParseNodeFiles → recursively find require/import deps in .js/.ts/.mjs/.cjs
+// packageRootFromSpecifier reduces a module specifier to the npm package
+// name it resolves to, so scoped packages ("@scope/name/sub/path") and
+// subpath imports ("lodash/fp") both collapse to their installable root
+// ("@scope/name", "lodash") instead of being treated as distinct packages.
+func packageRootFromSpecifier(spec string) string {
+	parts := strings.Split(spec, "/")
+	if strings.HasPrefix(spec, "@") {
+		if len(parts) >= 2 {
+			return parts[0] + "/" + parts[1]
+		}
+		return spec
+	}
+	return parts[0]
+}
+
+// nodeSourceExts are the file extensions ParseNodeFiles scans for imports.
+var nodeSourceExts = []string{".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs"}
+
+// nodeBuiltinModules is the set of Node.js core modules that never resolve
+// to an npm package. Both bare ("fs") and "node:"-prefixed ("node:fs")
+// forms are recognized.
+var nodeBuiltinModules = map[string]bool{
+	"assert": true, "async_hooks": true, "buffer": true, "child_process": true,
+	"cluster": true, "console": true, "constants": true, "crypto": true,
+	"dgram": true, "diagnostics_channel": true, "dns": true, "domain": true,
+	"events": true, "fs": true, "http": true, "http2": true, "https": true,
+	"inspector": true, "module": true, "net": true, "os": true, "path": true,
+	"perf_hooks": true, "process": true, "punycode": true, "querystring": true,
+	"readline": true, "repl": true, "stream": true, "string_decoder": true,
+	"sys": true, "timers": true, "tls": true, "trace_events": true, "tty": true,
+	"url": true, "util": true, "v8": true, "vm": true, "wasi": true,
+	"worker_threads": true, "zlib": true,
+}
+
+func isNodeBuiltin(spec string) bool {
+	spec = strings.TrimPrefix(spec, "node:")
+	return nodeBuiltinModules[spec]
+}
+
+// jsTokenKind classifies a token produced by scanJSTokens.
+type jsTokenKind int
+
+const (
+	jsWord jsTokenKind = iota // identifier or keyword
+	jsString
+	jsPunct
+)
+
+type jsToken struct {
+	kind jsTokenKind
+	text string // for jsString, the literal's content (unquoted)
+}
+
+// scanJSTokens is a small hand-rolled lexer for JS/TS/JSX source. It skips
+// line and block comments and yields string/template literal contents
+// verbatim, which is enough to reliably locate import/require/export
+// specifiers without a full parser. It does not attempt to disambiguate
+// regex literals from division, but that only risks mis-tokenizing regex
+// bodies as punctuation/words, which never matches an import/require/from
+// keyword sequence in practice.
+func scanJSTokens(src string) []jsToken {
+	var tokens []jsToken
+	n := len(src)
+	i := 0
+	for i < n {
+		c := src[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+
+		case c == '/' && i+1 < n && src[i+1] == '/':
+			for i < n && src[i] != '\n' {
+				i++
+			}
+
+		case c == '/' && i+1 < n && src[i+1] == '*':
+			i += 2
+			for i+1 < n && !(src[i] == '*' && src[i+1] == '/') {
+				i++
+			}
+			i += 2
+
+		case c == '\'' || c == '"' || c == '`':
+			quote := c
+			j := i + 1
+			var b strings.Builder
+			for j < n && src[j] != quote {
+				if src[j] == '\\' && j+1 < n {
+					b.WriteByte(src[j+1])
+					j += 2
+					continue
+				}
+				b.WriteByte(src[j])
+				j++
+			}
+			tokens = append(tokens, jsToken{kind: jsString, text: b.String()})
+			i = j + 1
+
+		case isIdentStart(c):
+			j := i + 1
+			for j < n && isIdentPart(src[j]) {
+				j++
+			}
+			tokens = append(tokens, jsToken{kind: jsWord, text: src[i:j]})
+			i = j
+
+		default:
+			tokens = append(tokens, jsToken{kind: jsPunct, text: string(c)})
+			i++
+		}
+	}
+	return tokens
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// extractModuleSpecifiers walks a token stream and returns every module
+// specifier referenced via static import, dynamic import(), export ... from,
+// or require(), including multi-line statements, `import type`, and
+// re-exports — all of which the previous line-anchored regexes missed.
+func extractModuleSpecifiers(tokens []jsToken) []string {
+	var specs []string
+
+	for idx := 0; idx < len(tokens); idx++ {
+		tok := tokens[idx]
+		if tok.kind != jsWord {
+			continue
+		}
+
+		switch tok.text {
+		case "import":
+			// Dynamic import: import( "x" )
+			if idx+1 < len(tokens) && tokens[idx+1].kind == jsPunct && tokens[idx+1].text == "(" {
+				if idx+2 < len(tokens) && tokens[idx+2].kind == jsString {
+					specs = append(specs, tokens[idx+2].text)
+				}
+				continue
+			}
+			// Side-effect import: import "x"
+			if idx+1 < len(tokens) && tokens[idx+1].kind == jsString {
+				specs = append(specs, tokens[idx+1].text)
+				continue
+			}
+			// Static import (incl. `import type ... from "x"`): scan
+			// forward to the next "from" keyword before a semicolon.
+			if spec, ok := findFromClause(tokens, idx+1); ok {
+				specs = append(specs, spec)
+			}
+
+		case "export":
+			// `export * from "x"`, `export { a } from "x"`, but not a
+			// local `export const x = ...` (no "from" before ";").
+			if spec, ok := findFromClause(tokens, idx+1); ok {
+				specs = append(specs, spec)
+			}
+
+		case "require":
+			if idx+1 < len(tokens) && tokens[idx+1].kind == jsPunct && tokens[idx+1].text == "(" {
+				if idx+2 < len(tokens) && tokens[idx+2].kind == jsString {
+					specs = append(specs, tokens[idx+2].text)
+				}
+			}
+		}
+	}
+	return specs
+}
+
+// findFromClause scans forward from a token index looking for a top-level
+// "from" keyword followed by a string literal, stopping at a statement
+// boundary (";" or "{"-balanced end) so it doesn't run away across
+// unrelated statements when there is no "from" clause at all.
+func findFromClause(tokens []jsToken, start int) (string, bool) {
+	for i := start; i < len(tokens) && i < start+200; i++ {
+		t := tokens[i]
+		if t.kind == jsPunct && t.text == ";" {
+			return "", false
+		}
+		if t.kind == jsWord && t.text == "from" {
+			if i+1 < len(tokens) && tokens[i+1].kind == jsString {
+				return tokens[i+1].text, true
+			}
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// ParseNodeFiles recursively scans .js/.jsx/.ts/.tsx/.mjs/.cjs files for
+// static imports, dynamic import(), export...from re-exports, and
+// require() calls, and returns the npm packages they reference. Relative
+// specifiers and Node.js built-ins are excluded; each returned dependency
+// records the file it was first seen in via SourceFile.
 func ParseNodeFiles(projectDir string) ([]utils.Dependency, error) {
 	var deps []utils.Dependency
-
-	importRegex := regexp.MustCompile(`^(?:import|const|let|var).*['"]([^'"]+)['"]`)
-	requireRegex := regexp.MustCompile(`require\(['"]([^'"]+)['"]\)`)
+	seen := make(map[string]bool)
 
 	err := filepath.WalkDir(projectDir, func(path string, d os.DirEntry, err error) error {
 		if err != nil || d.IsDir() {
 			return nil
 		}
-		if !(strings.HasSuffix(path, ".js") ||
-			strings.HasSuffix(path, ".ts") ||
-			strings.HasSuffix(path, ".mjs") ||
-			strings.HasSuffix(path, ".cjs")) {
+		matched := false
+		for _, ext := range nodeSourceExts {
+			if strings.HasSuffix(path, ext) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
 			return nil
 		}
 
-		f, err := os.Open(path)
+		data, err := os.ReadFile(path)
 		if err != nil {
 			return nil
 		}
-		defer f.Close()
-
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if m := importRegex.FindStringSubmatch(line); len(m) > 1 {
-				pkg := m[1]
-				if !strings.HasPrefix(pkg, ".") {
-					deps = append(deps, utils.Dependency{
-						GroupID:    "npm",
-						ArtifactID: pkg,
-						Version:    "",
-						Scope:      "compile",
-						Key:        pkg,
-					})
-				}
+
+		relPath, err := filepath.Rel(projectDir, path)
+		if err != nil {
+			relPath = path
+		}
+
+		for _, spec := range extractModuleSpecifiers(scanJSTokens(string(data))) {
+			if spec == "" || strings.HasPrefix(spec, ".") || isNodeBuiltin(spec) {
+				continue
 			}
-			if m := requireRegex.FindStringSubmatch(line); len(m) > 1 {
-				pkg := m[1]
-				if !strings.HasPrefix(pkg, ".") {
-					deps = append(deps, utils.Dependency{
-						GroupID:    "npm",
-						ArtifactID: pkg,
-						Version:    "",
-						Scope:      "compile",
-						Key:        pkg,
-					})
-				}
+			pkg := packageRootFromSpecifier(spec)
+			if seen[pkg] {
+				continue
 			}
+			seen[pkg] = true
+			deps = append(deps, utils.Dependency{
+				GroupID:    "npm",
+				ArtifactID: pkg,
+				Version:    "",
+				Scope:      "compile",
+				Key:        pkg,
+				SourceFile: relPath,
+			})
 		}
-		return scanner.Err()
+		return nil
 	})
 
 	if err != nil {
@@ -275,3 +553,253 @@ func ParseNodeFiles(projectDir string) ([]utils.Dependency, error) {
 	}
 	return deps, nil
 }
+
+// ---------------------------
+// Lockfile parsers
+// ---------------------------
+
+type npmLockPackage struct {
+	Version      string                     `json:"version"`
+	Resolved     string                     `json:"resolved"`
+	Integrity    string                     `json:"integrity"`
+	Dependencies map[string]*npmLockPackage `json:"dependencies,omitempty"`
+}
+
+type npmLockFile struct {
+	LockfileVersion int                        `json:"lockfileVersion"`
+	Dependencies    map[string]*npmLockPackage `json:"dependencies,omitempty"`
+	Packages        map[string]*npmLockPackage `json:"packages,omitempty"`
+}
+
+// ParsePackageLock reads package-lock.json and returns the exact resolved
+// version + integrity hash for every package it pins. It understands both
+// the v1 "dependencies" tree (recursing into nested deps) and the flat
+// v2/v3 "packages" map keyed by "node_modules/<name>" path.
+func ParsePackageLock(path string) ([]utils.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package-lock.json: %v", err)
+	}
+
+	var lock npmLockFile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("invalid package-lock.json: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var deps []utils.Dependency
+
+	add := func(name string, pkg *npmLockPackage) {
+		if name == "" || pkg == nil || seen[name] {
+			return
+		}
+		seen[name] = true
+		deps = append(deps, utils.Dependency{
+			GroupID:    "npm",
+			ArtifactID: name,
+			Version:    pkg.Version,
+			Scope:      "compile",
+			Key:        name,
+			Checksum:   pkg.Integrity,
+		})
+	}
+
+	// v2/v3: "packages" is a flat map keyed by "node_modules/name" or
+	// "node_modules/@scope/name" (nested paths for transitive deps).
+	for pkgPath, pkg := range lock.Packages {
+		if pkgPath == "" {
+			continue // root project entry
+		}
+		idx := strings.LastIndex(pkgPath, "node_modules/")
+		if idx < 0 {
+			continue
+		}
+		name := pkgPath[idx+len("node_modules/"):]
+		add(name, pkg)
+	}
+
+	// v1: "dependencies" is a tree, recurse into nested "dependencies".
+	var walk func(map[string]*npmLockPackage)
+	walk = func(tree map[string]*npmLockPackage) {
+		for name, pkg := range tree {
+			add(name, pkg)
+			if pkg != nil && pkg.Dependencies != nil {
+				walk(pkg.Dependencies)
+			}
+		}
+	}
+	walk(lock.Dependencies)
+
+	return deps, nil
+}
+
+// yarnV1EntryRegex matches a yarn.lock v1 block header, e.g.
+// `lodash@^4.17.21, lodash@^4.17.4:`
+var yarnV1EntryRegex = regexp.MustCompile(`^"?([^@"]+(?:/[^@"]+)?)@`)
+
+// ParseYarnLock reads a yarn.lock and returns the exact resolved version +
+// integrity hash for every entry. yarn v1 uses a bespoke indented format;
+// v2+ ("Berry") lockfiles are valid YAML with a top-level "__metadata" key,
+// so we dispatch on that before picking a parsing strategy.
+func ParseYarnLock(path string) ([]utils.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read yarn.lock: %v", err)
+	}
+
+	if strings.Contains(string(data), "__metadata:") {
+		return parseYarnLockV2(data)
+	}
+	return parseYarnLockV1(data)
+}
+
+func parseYarnLockV1(data []byte) ([]utils.Dependency, error) {
+	var deps []utils.Dependency
+	seen := make(map[string]bool)
+
+	lines := strings.Split(string(data), "\n")
+	var currentName string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && strings.HasSuffix(strings.TrimSpace(line), ":") {
+			m := yarnV1EntryRegex.FindStringSubmatch(strings.TrimPrefix(line, "\""))
+			currentName = ""
+			if len(m) > 1 {
+				currentName = m[1]
+			}
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if currentName == "" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "version ") {
+			version := strings.Trim(strings.TrimPrefix(trimmed, "version "), "\"")
+			if !seen[currentName] {
+				seen[currentName] = true
+				deps = append(deps, utils.Dependency{
+					GroupID:    "npm",
+					ArtifactID: currentName,
+					Version:    version,
+					Scope:      "compile",
+					Key:        currentName,
+				})
+			}
+		} else if strings.HasPrefix(trimmed, "integrity ") && len(deps) > 0 {
+			integrity := strings.Trim(strings.TrimPrefix(trimmed, "integrity "), "\"")
+			last := &deps[len(deps)-1]
+			if last.ArtifactID == currentName {
+				last.Checksum = integrity
+			}
+		}
+	}
+	return deps, nil
+}
+
+func parseYarnLockV2(data []byte) ([]utils.Dependency, error) {
+	var raw map[string]map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid yarn.lock (v2+): %v", err)
+	}
+
+	var deps []utils.Dependency
+	seen := make(map[string]bool)
+
+	for key, entry := range raw {
+		if key == "__metadata" {
+			continue
+		}
+		m := yarnV1EntryRegex.FindStringSubmatch(strings.Trim(strings.Split(key, ",")[0], "\""))
+		if len(m) < 2 {
+			continue
+		}
+		name := m[1]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		version, _ := entry["version"].(string)
+		checksum, _ := entry["checksum"].(string)
+		deps = append(deps, utils.Dependency{
+			GroupID:    "npm",
+			ArtifactID: name,
+			Version:    version,
+			Scope:      "compile",
+			Key:        name,
+			Checksum:   checksum,
+		})
+	}
+	return deps, nil
+}
+
+// pnpmResolution carries the "resolution.integrity" field pnpm records
+// for each pinned package.
+type pnpmResolution struct {
+	Integrity string `yaml:"integrity"`
+}
+
+type pnpmPackageEntry struct {
+	Resolution pnpmResolution `yaml:"resolution"`
+}
+
+type pnpmLockFile struct {
+	Packages map[string]pnpmPackageEntry `yaml:"packages"`
+}
+
+// pnpmKeyRegex extracts name+version from a pnpm-lock.yaml "packages" key,
+// e.g. "/lodash@4.17.21:" or "/@scope/name@1.2.3(peerdep@1.0.0):".
+var pnpmKeyRegex = regexp.MustCompile(`^/(.+)@([^@/(]+)(?:\(.*\))?:?$`)
+
+// ParsePnpmLock reads pnpm-lock.yaml and returns the exact resolved
+// version + integrity hash for every package it pins.
+func ParsePnpmLock(path string) ([]utils.Dependency, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pnpm-lock.yaml: %v", err)
+	}
+
+	var lock pnpmLockFile
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("invalid pnpm-lock.yaml: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var deps []utils.Dependency
+	for key, entry := range lock.Packages {
+		key = strings.TrimSuffix(key, ":")
+		m := pnpmKeyRegex.FindStringSubmatch(key + ":")
+		if len(m) < 3 {
+			continue
+		}
+		name, version := m[1], m[2]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		deps = append(deps, utils.Dependency{
+			GroupID:    "npm",
+			ArtifactID: name,
+			Version:    version,
+			Scope:      "compile",
+			Key:        name,
+			Checksum:   entry.Resolution.Integrity,
+		})
+	}
+	return deps, nil
+}
+
+// EmitSBOM writes deps to w as an SBOM in the given format ("cyclonedx" or
+// "spdx"); see utils/sbom.
+func (h *NodeHandler) EmitSBOM(deps []utils.Dependency, w io.Writer, format string) error {
+	return sbom.Emit(deps, w, format)
+}
+
+// ExportSBOM writes deps to outPath as an SBOM in the given format
+// ("cyclonedx" or "spdx"); see utils/sbom.ExportToFile.
+func (h *NodeHandler) ExportSBOM(deps []utils.Dependency, format, outPath, projectDir string) error {
+	return sbom.ExportToFile(deps, format, outPath, projectDir)
+}
@@ -0,0 +1,395 @@
+package cpanhandler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ort-recovery/utils"
+	"ort-recovery/utils/policy"
+	"ort-recovery/utils/sbom"
+)
+
+// ---------------------------
+// Perl/CPAN Handler
+// ---------------------------
+type PerlHandler struct{}
+
+// Name returns the handler name
+func (h *PerlHandler) Name() string {
+	return "Perl"
+}
+
+// Detect: true if cpanfile, cpanfile.snapshot, Makefile.PL, Build.PL, or
+// any .pm/.pl source file exists
+func (h *PerlHandler) Detect(projectDir string) bool {
+	files := []string{"cpanfile", "cpanfile.snapshot", "Makefile.PL", "Build.PL"}
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(projectDir, f)); err == nil {
+			utils.AppendLog(projectDir, fmt.Sprintf("Detected %s in project", f))
+			return true
+		}
+	}
+	for _, pattern := range []string{"*.pm", "*.pl"} {
+		matches, _ := filepath.Glob(filepath.Join(projectDir, pattern))
+		if len(matches) > 0 {
+			utils.AppendLog(projectDir, fmt.Sprintf("Detected %s in project", pattern))
+			return true
+		}
+	}
+	return false
+}
+
+// Scan parses cpanfile + cpanfile.snapshot + Syft, compares with use/require
+func (h *PerlHandler) Scan(projectDir string) ([]utils.Dependency, utils.ScanReport, error) {
+	scanReport := utils.NewScanReport()
+	scanLogger := utils.NewScanLogger(projectDir).With(utils.LogKeyHandler, h.Name())
+	var declaredDeps []utils.Dependency
+	var unknowns []utils.Unknown
+
+	// Parse cpanfile
+	if _, err := os.Stat(filepath.Join(projectDir, "cpanfile")); err == nil {
+		utils.AppendLog(projectDir, "Parsing cpanfile...")
+		d, u, _ := ParseCPANFile(filepath.Join(projectDir, "cpanfile"))
+		scanLogger.Info("parsed manifest", utils.LogKeyFile, "cpanfile", utils.LogKeyPhase, "declared", "deps", len(d))
+		declaredDeps = append(declaredDeps, utils.TagOrigin(d, "declared")...)
+		unknowns = append(unknowns, u...)
+	}
+
+	// Parse cpanfile.snapshot
+	if _, err := os.Stat(filepath.Join(projectDir, "cpanfile.snapshot")); err == nil {
+		utils.AppendLog(projectDir, "Parsing cpanfile.snapshot...")
+		d, u, _ := ParseCPANSnapshot(filepath.Join(projectDir, "cpanfile.snapshot"))
+		scanLogger.Info("parsed lockfile", utils.LogKeyFile, "cpanfile.snapshot", utils.LogKeyPhase, "lockfile", "deps", len(d))
+		declaredDeps = append(declaredDeps, utils.TagOrigin(d, "lockfile")...)
+		unknowns = append(unknowns, u...)
+	}
+
+	// Parse Syft output, if a SourceAdapter produced one (see main.go
+	// "-source"); native ingestion leaves no syft.json, and that's fine.
+	var syftDeps []utils.Dependency
+	if syftData, err := os.ReadFile(filepath.Join(projectDir, "syft.json")); err == nil {
+		syftDeps, err = utils.ParseSyftJSON(syftData, "perl")
+		if err != nil {
+			utils.AppendLog(projectDir, "Failed to parse syft.json")
+			return nil, scanReport, err
+		}
+		utils.TagOrigin(syftDeps, "syft")
+	} else {
+		utils.AppendLog(projectDir, "No syft.json found; continuing with declared + scanned dependencies only")
+	}
+
+	// Scan .pm/.pl files for `use`/`require`
+	usedDeps, usedUnknowns, err := ScanPerlFiles(projectDir)
+	if err != nil {
+		utils.AppendLog(projectDir, "Failed to scan Perl files")
+		return nil, scanReport, err
+	}
+	unknowns = append(unknowns, usedUnknowns...)
+	utils.TagOrigin(usedDeps, "scanned")
+	scanLogger.Info("scanned imports", utils.LogKeyPhase, "scanned", "deps", len(usedDeps))
+
+	if err := utils.WriteUnknowns(projectDir, unknowns); err != nil {
+		utils.AppendLog(projectDir, fmt.Sprintf("Failed to write unknowns.json: %v", err))
+	}
+	scanReport.Unknowns = len(unknowns)
+	scanLogger.Info("unknowns written", utils.LogKeyPhase, "unknowns", "count", len(unknowns))
+	scanReport.RecordPhase("declared", len(declaredDeps))
+	scanReport.RecordPhase("scanned", len(usedDeps))
+
+	// Merge all sources before reconciliation
+	allDeps := append(declaredDeps, usedDeps...)
+	allDeps = append(allDeps, syftDeps...)
+
+	finalDeps, report, err := utils.ReconcileDependencies(allDeps)
+	if err != nil {
+		return nil, scanReport, err
+	}
+
+	utils.AppendLog(projectDir, fmt.Sprintf("Scan complete: %d dependencies found", len(finalDeps)))
+	if len(report.Conflicts) > 0 {
+		utils.AppendLog(projectDir, fmt.Sprintf("Reconcile resolved %d version conflicts", len(report.Conflicts)))
+	}
+
+	// Enrich with known OSV vulnerabilities (opt-in, see Config.EnableOSV).
+	finalDeps, err = utils.EnrichVulnerabilities(finalDeps, "cpan", projectDir)
+	if err != nil {
+		return nil, scanReport, err
+	}
+	scanReport.OSVHits = utils.CountOSVHits(finalDeps)
+	scanLogger.Info("osv enrichment complete", utils.LogKeyPhase, "osv", "hits", scanReport.OSVHits)
+
+	if err := policy.Apply(h.Name(), "cpan", projectDir, finalDeps); err != nil {
+		return nil, scanReport, err
+	}
+
+	scanReport.RecordEcosystem("cpan", len(finalDeps))
+	scanLogger.Info("scan complete", utils.LogKeyPhase, "ecosystem", utils.LogKeySource, "cpan", "deps", len(finalDeps))
+	return finalDeps, scanReport, nil
+}
+
+// GenerateRecoveryFile updates cpanfile (main declaration) and backup
+func (h *PerlHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir, backupDir string) error {
+	cpanfilePath := filepath.Join(projectDir, "cpanfile")
+
+	// Backup if exists
+	if _, err := os.Stat(cpanfilePath); err == nil {
+		backupPath := filepath.Join(backupDir, "cpanfile_backup")
+		if err := utils.CopyFile(cpanfilePath, backupPath); err != nil {
+			utils.AppendLog(projectDir, "Failed to backup cpanfile")
+			return fmt.Errorf("failed to backup cpanfile: %v", err)
+		}
+		utils.AppendLog(projectDir, "cpanfile backup created")
+	}
+
+	utils.AppendLog(projectDir, "Writing recovery cpanfile...")
+	return WriteCPANFile(cpanfilePath, deps)
+}
+
+// ---------------------------
+// Helpers
+// ---------------------------
+
+// cpanPhaseScope maps an `on '<phase>' => sub { ... };` block's phase to
+// a Dependency.Scope; unrecognized phases fall back to "compile" (the
+// scope requires outside any block get).
+func cpanPhaseScope(phase string) string {
+	switch phase {
+	case "test", "develop", "build":
+		return phase
+	default:
+		return "compile"
+	}
+}
+
+// ParseCPANFile walks a cpanfile's directive grammar: `requires`,
+// `recommends`, and `suggests` lines (recommends/suggests are recorded
+// with Optional set, the same way RustHandler marks an optional Cargo
+// dependency), and `on '<phase>' => sub { ... };` blocks, which set the
+// scope for every directive inside until the closing `};`. A line that
+// looks like a directive but doesn't match the expected quoted-argument
+// shape is reported as an Unknown instead of being dropped silently.
+func ParseCPANFile(path string) ([]utils.Dependency, []utils.Unknown, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		utils.AppendLog("", fmt.Sprintf("Failed to open cpanfile: %v", err))
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	var deps []utils.Dependency
+	var unknowns []utils.Unknown
+	reqRe := regexp.MustCompile(`^(requires|recommends|suggests)\s+['"]([^'"]+)['"](?:\s*,\s*['"]([^'"]*)['"])?\s*;?`)
+	reqLine := regexp.MustCompile(`^(requires|recommends|suggests)\b`)
+	onOpenRe := regexp.MustCompile(`^on\s+['"]([^'"]+)['"]\s*=>\s*sub\s*\{`)
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	inBlock := false
+	scope := "compile"
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if m := onOpenRe.FindStringSubmatch(line); m != nil {
+			inBlock = true
+			scope = cpanPhaseScope(m[1])
+			continue
+		}
+		if inBlock && (line == "};" || line == "}") {
+			inBlock = false
+			scope = "compile"
+			continue
+		}
+		if m := reqRe.FindStringSubmatch(line); m != nil {
+			deps = append(deps, utils.Dependency{
+				GroupID:    "cpan",
+				ArtifactID: m[2],
+				Version:    m[3],
+				Scope:      scope,
+				Key:        m[2],
+				Optional:   m[1] != "requires",
+			})
+		} else if reqLine.MatchString(line) {
+			unknowns = append(unknowns, utils.Unknown{
+				File:    path,
+				Line:    lineNum,
+				Reason:  "unmatched cpanfile directive",
+				RawText: line,
+			})
+		}
+	}
+	return deps, unknowns, scanner.Err()
+}
+
+// ParseCPANSnapshot reads cpanfile.snapshot's DISTRIBUTIONS tree: each
+// distribution's `provides:` block lists `Module::Name version` lines,
+// which become Dependencies. `pathname:`/`requirements:` and other keyed
+// sections are skipped. A line inside `provides:` that doesn't split into
+// a module name and version is reported as an Unknown.
+func ParseCPANSnapshot(path string) ([]utils.Dependency, []utils.Unknown, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		utils.AppendLog("", fmt.Sprintf("Failed to open cpanfile.snapshot: %v", err))
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	var deps []utils.Dependency
+	var unknowns []utils.Unknown
+	provideRe := regexp.MustCompile(`^([A-Za-z0-9_:]+)\s+(\S+)$`)
+
+	inProvides := false
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line == "DISTRIBUTIONS":
+			continue
+		case line == "provides:":
+			inProvides = true
+			continue
+		case strings.HasSuffix(line, ":"):
+			// "requirements:", "pathname:", or any other keyed section
+			// that isn't "provides:" ends the provides block.
+			inProvides = false
+			continue
+		}
+		if !inProvides {
+			continue
+		}
+		if m := provideRe.FindStringSubmatch(line); m != nil {
+			name := m[1]
+			deps = append(deps, utils.Dependency{
+				GroupID:    "cpan",
+				ArtifactID: name,
+				Version:    m[2],
+				Scope:      "compile",
+				Key:        name,
+			})
+		} else {
+			unknowns = append(unknowns, utils.Unknown{
+				File:    path,
+				Line:    lineNum,
+				Reason:  "could not parse provides entry",
+				RawText: line,
+			})
+		}
+	}
+	return deps, unknowns, scanner.Err()
+}
+
+// cpanPragmas are `use`d constructs that aren't CPAN module dependencies:
+// compiler pragmas and version declarations (`use v5.10;`/`use 5.010;`).
+var cpanPragmas = map[string]bool{
+	"strict":   true,
+	"warnings": true,
+	"utf8":     true,
+	"feature":  true,
+}
+
+var cpanVersionLiteral = regexp.MustCompile(`^v?[0-9][0-9.]*$`)
+
+// ScanPerlFiles finds `use Module::Name;` / `require Module::Name;`
+// imports across .pm/.pl files, skipping pragmas (see cpanPragmas) and
+// bare version declarations. A `use`/`require` line that doesn't resolve
+// to a module name is reported as an Unknown.
+func ScanPerlFiles(projectDir string) ([]utils.Dependency, []utils.Unknown, error) {
+	var deps []utils.Dependency
+	var unknowns []utils.Unknown
+	useRe := regexp.MustCompile(`^(use|require)\s+([A-Za-z_][A-Za-z0-9_:]*)`)
+	useLine := regexp.MustCompile(`^(use|require)\b`)
+
+	err := filepath.Walk(projectDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if !strings.HasSuffix(path, ".pm") && !strings.HasSuffix(path, ".pl") {
+			return nil
+		}
+		data, _ := os.ReadFile(path)
+		lines := strings.Split(string(data), "\n")
+		for i, line := range lines {
+			line = strings.TrimSpace(line)
+			if m := useRe.FindStringSubmatch(line); m != nil {
+				name := m[2]
+				if cpanPragmas[name] || cpanVersionLiteral.MatchString(name) {
+					continue
+				}
+				deps = append(deps, utils.Dependency{
+					GroupID:    "cpan",
+					ArtifactID: name,
+					Version:    "",
+					Scope:      "compile",
+					Key:        name,
+				})
+			} else if useLine.MatchString(line) {
+				unknowns = append(unknowns, utils.Unknown{
+					File:    path,
+					Line:    i + 1,
+					Reason:  "unmatched use/require statement",
+					RawText: line,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		utils.AppendLog(projectDir, "Error scanning Perl files")
+	}
+	return deps, unknowns, err
+}
+
+// WriteCPANFile regenerates cpanfile, one `requires`/`recommends` line
+// per dependency (recommends for deps marked Optional, the same flag
+// RustHandler uses for an optional Cargo dependency).
+func WriteCPANFile(path string, deps []utils.Dependency) error {
+	file, err := os.Create(path)
+	if err != nil {
+		utils.AppendLog("", fmt.Sprintf("Failed to create cpanfile: %v", err))
+		return err
+	}
+	defer file.Close()
+
+	for _, d := range deps {
+		directive := "requires"
+		if d.Optional {
+			directive = "recommends"
+		}
+		var line string
+		if d.Version == "" {
+			line = fmt.Sprintf("%s '%s';\n", directive, d.ArtifactID)
+		} else {
+			line = fmt.Sprintf("%s '%s', '%s';\n", directive, d.ArtifactID, d.Version)
+		}
+		if d.Scope != "" && d.Scope != "compile" {
+			line = fmt.Sprintf("on '%s' => sub {\n    %s};\n", d.Scope, line)
+		}
+		if _, err := file.WriteString(line); err != nil {
+			utils.AppendLog("", fmt.Sprintf("Failed to write cpanfile entry for %s", d.ArtifactID))
+			return err
+		}
+	}
+	utils.AppendLog("", "cpanfile written successfully")
+	return nil
+}
+
+// EmitSBOM writes deps to w as an SBOM in the given format ("cyclonedx" or
+// "spdx"); see utils/sbom.
+func (h *PerlHandler) EmitSBOM(deps []utils.Dependency, w io.Writer, format string) error {
+	return sbom.Emit(deps, w, format)
+}
+
+// ExportSBOM writes deps to outPath as an SBOM in the given format
+// ("cyclonedx" or "spdx"); see utils/sbom.ExportToFile.
+func (h *PerlHandler) ExportSBOM(deps []utils.Dependency, format, outPath, projectDir string) error {
+	return sbom.ExportToFile(deps, format, outPath, projectDir)
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -11,6 +12,8 @@ import (
 	"time"
 
 	"ort-recovery/utils"
+	"ort-recovery/utils/policy"
+	"ort-recovery/utils/sbom"
 )
 
 // ---------------------------
@@ -49,17 +52,24 @@ func (h *PHPHandler) Detect(projectDir string) bool {
 }
 
 // Scan merges declared deps, lockfile/syft versions, and inferred deps from .php files
-func (h *PHPHandler) Scan(projectDir string) ([]utils.Dependency, error) {
+func (h *PHPHandler) Scan(projectDir string) ([]utils.Dependency, utils.ScanReport, error) {
+	scanReport := utils.NewScanReport()
+	scanLogger := utils.NewScanLogger(projectDir).With(utils.LogKeyHandler, h.Name())
+
 	var declaredDeps []utils.Dependency
+	var unknowns []utils.Unknown
 
 	// 1. Parse composer.json if exists
 	composerPath := filepath.Join(projectDir, "composer.json")
 	if _, err := os.Stat(composerPath); err == nil {
-		d, err := ParseComposerJSON(composerPath)
+		d, u, err := ParseComposerJSON(composerPath)
 		if err != nil {
-			return nil, err
+			return nil, scanReport, err
 		}
 		declaredDeps = d
+		unknowns = append(unknowns, u...)
+		scanReport.RecordPhase("declared", len(d))
+		scanLogger.Info("parsed manifest", utils.LogKeyFile, "composer.json", utils.LogKeyPhase, "declared", "deps", len(d))
 	}
 
 	// 2. Parse composer.lock if exists
@@ -68,7 +78,11 @@ func (h *PHPHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 	if _, err := os.Stat(lockPath); err == nil {
 		data, err := os.ReadFile(lockPath)
 		if err == nil {
-			lockDeps, _ = ParseComposerLock(data)
+			var u []utils.Unknown
+			lockDeps, u, _ = ParseComposerLock(data)
+			unknowns = append(unknowns, u...)
+			scanReport.RecordPhase("lockfile", len(lockDeps))
+			scanLogger.Info("parsed lockfile", utils.LogKeyFile, "composer.lock", utils.LogKeyPhase, "lockfile", "deps", len(lockDeps))
 		}
 	}
 
@@ -79,29 +93,54 @@ func (h *PHPHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 		data, err := os.ReadFile(syftPath)
 		if err == nil {
 			syftDeps, _ = utils.ParseSyftJSON(data, "php")
+			scanReport.RecordPhase("syft", len(syftDeps))
 		}
 	} else {
 		utils.AppendLog("", "[PHPHandler] syft.json not found, versions may be incomplete")
 	}
 
 	// 4. Scan .php source files
-	codeDeps, err := ParsePHPFiles(projectDir)
+	codeDeps, codeUnknowns, err := ParsePHPFiles(projectDir)
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
+	}
+	unknowns = append(unknowns, codeUnknowns...)
+	scanReport.RecordPhase("scanned", len(codeDeps))
+
+	if err := utils.WriteUnknowns(projectDir, unknowns); err != nil {
+		utils.AppendLog(projectDir, "[PHPHandler] WARNING: failed to write unknowns.json: %v", err)
 	}
+	scanReport.Unknowns = len(unknowns)
+	scanLogger.Info("unknowns written", utils.LogKeyPhase, "unknowns", "count", len(unknowns))
 
 	// 5. Merge dependencies
 	all := utils.MergeDependencies(declaredDeps, lockDeps)
 	all = utils.MergeDependencies(all, syftDeps)
 	all = utils.MergeDependencies(all, codeDeps)
+	scanReport.RecordEcosystem("packagist", len(all))
+
+	// 6. Enrich with known OSV vulnerabilities (opt-in, see Config.EnableOSV)
+	// before curations run, so AutoPatch rules can see Vulnerabilities.
+	all, err = utils.EnrichVulnerabilities(all, "packagist", projectDir)
+	if err != nil {
+		return nil, scanReport, err
+	}
+	scanReport.OSVHits = utils.CountOSVHits(all)
+	scanLogger.Info("osv enrichment complete", utils.LogKeyPhase, "osv", "hits", scanReport.OSVHits)
 
-	// 6. Apply curations
-	finalDeps, err := utils.ApplyCurations(all, "configs/master_curations.yml")
+	// 7. Apply curations
+	finalDeps, applied, err := utils.ApplyCurations(all, "configs/master_curations.yml")
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
+	}
+	scanReport.CurationsApplied = applied
+	scanLogger.Info("curations applied", utils.LogKeyPhase, "curations", "count", applied)
+
+	if err := policy.Apply(h.Name(), "packagist", projectDir, finalDeps); err != nil {
+		return nil, scanReport, err
 	}
 
-	return finalDeps, nil
+	return finalDeps, scanReport, nil
 }
 
 // GenerateRecoveryFile writes composer.json and creates a timestamped backup
@@ -131,21 +170,31 @@ func (h *PHPHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir, b
 // ---------------------------
 
 type ComposerJSON struct {
-	Require    map[string]string `json:"require,omitempty"`
-	RequireDev map[string]string `json:"require-dev,omitempty"`
+	Require      map[string]string `json:"require,omitempty"`
+	RequireDev   map[string]string `json:"require-dev,omitempty"`
+	Repositories []ComposerRepo    `json:"repositories,omitempty"`
 }
 
-func ParseComposerJSON(composerPath string) ([]utils.Dependency, error) {
+// ComposerRepo is one entry of composer.json's top-level "repositories"
+// array: either a registry mirror ({"type":"composer","url":...}) or a
+// VCS-backed replacement ({"type":"vcs","url":...}), per a "packagist"
+// rule in configs/mirrors.yml (see utils.LoadMirrorRules).
+type ComposerRepo struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+func ParseComposerJSON(composerPath string) ([]utils.Dependency, []utils.Unknown, error) {
 	if _, err := os.Stat(composerPath); os.IsNotExist(err) {
-		return []utils.Dependency{}, nil
+		return []utils.Dependency{}, nil, nil
 	}
 	data, err := os.ReadFile(composerPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read %s: %v", composerPath, err)
+		return nil, nil, fmt.Errorf("failed to read %s: %v", composerPath, err)
 	}
 	var composer ComposerJSON
 	if err := json.Unmarshal(data, &composer); err != nil {
-		return nil, fmt.Errorf("invalid JSON in %s: %v", composerPath, err)
+		return nil, nil, fmt.Errorf("invalid JSON in %s: %v", composerPath, err)
 	}
 
 	var deps []utils.Dependency
@@ -167,66 +216,92 @@ func ParseComposerJSON(composerPath string) ([]utils.Dependency, error) {
 			Key:        name,
 		})
 	}
-	return deps, nil
+	return deps, nil, nil
 }
 
-// ParseComposerLock extracts deps from composer.lock
-func ParseComposerLock(data []byte) ([]utils.Dependency, error) {
+// ParseComposerLock extracts deps from composer.lock. A package entry
+// missing "name" or "version" is reported as an Unknown instead of being
+// dropped silently.
+func ParseComposerLock(data []byte) ([]utils.Dependency, []utils.Unknown, error) {
 	type LockFile struct {
 		Packages    []map[string]interface{} `json:"packages"`
 		PackagesDev []map[string]interface{} `json:"packages-dev"`
 	}
 	var lock LockFile
 	if err := json.Unmarshal(data, &lock); err != nil {
-		return nil, fmt.Errorf("invalid composer.lock: %v", err)
+		return nil, nil, fmt.Errorf("invalid composer.lock: %v", err)
 	}
 	var deps []utils.Dependency
-	for _, pkg := range lock.Packages {
-		if name, ok := pkg["name"].(string); ok {
-			version := ""
-			if v, ok := pkg["version"].(string); ok {
-				version = v
+	var unknowns []utils.Unknown
+	collect := func(pkgs []map[string]interface{}, scope string) {
+		for _, pkg := range pkgs {
+			name, ok := pkg["name"].(string)
+			if !ok || name == "" {
+				unknowns = append(unknowns, utils.Unknown{
+					File:    "composer.lock",
+					Reason:  "package entry missing name",
+					RawText: fmt.Sprintf("%v", pkg),
+				})
+				continue
 			}
-			deps = append(deps, utils.Dependency{
-				GroupID:    "packagist",
-				ArtifactID: name,
-				Version:    version,
-				Scope:      "compile",
-				Key:        name,
-			})
-		}
-	}
-	for _, pkg := range lock.PackagesDev {
-		if name, ok := pkg["name"].(string); ok {
-			version := ""
-			if v, ok := pkg["version"].(string); ok {
-				version = v
+			version, _ := pkg["version"].(string)
+			if version == "" {
+				unknowns = append(unknowns, utils.Unknown{
+					File:    "composer.lock",
+					Reason:  "package entry missing version",
+					RawText: name,
+				})
+				continue
 			}
 			deps = append(deps, utils.Dependency{
 				GroupID:    "packagist",
 				ArtifactID: name,
 				Version:    version,
-				Scope:      "test",
+				Scope:      scope,
 				Key:        name,
 			})
 		}
 	}
-	return deps, nil
+	collect(lock.Packages, "compile")
+	collect(lock.PackagesDev, "test")
+	return deps, unknowns, nil
 }
 
-// WriteComposerJSON writes curated deps into composer.json
+// WriteComposerJSON writes curated deps into composer.json. Packages
+// matching a "packagist" rule in configs/mirrors.yml (see
+// utils.LoadMirrorRules) get a top-level "repositories" entry: a "vcs"
+// repo for a VCS rule, a "composer" repo (an internal Nexus-style
+// mirror) otherwise.
 func WriteComposerJSON(composerPath string, deps []utils.Dependency) error {
+	rules, err := utils.LoadMirrorRules(utils.MirrorsFileName)
+	if err != nil {
+		return fmt.Errorf("failed to load mirrors file: %v", err)
+	}
+
 	composer := ComposerJSON{
 		Require:    map[string]string{},
 		RequireDev: map[string]string{},
 	}
+	seenRepos := map[string]bool{}
 	for _, d := range deps {
 		if d.Scope == "test" {
 			composer.RequireDev[d.ArtifactID] = d.Version
 		} else {
 			composer.Require[d.ArtifactID] = d.Version
 		}
+
+		rule, mirrored := utils.MatchMirror(rules, "packagist", d.ArtifactID)
+		if !mirrored || seenRepos[rule.Replacement] {
+			continue
+		}
+		seenRepos[rule.Replacement] = true
+		repoType := "composer"
+		if rule.VCS {
+			repoType = "vcs"
+		}
+		composer.Repositories = append(composer.Repositories, ComposerRepo{Type: repoType, URL: rule.Replacement})
 	}
+
 	data, err := json.MarshalIndent(composer, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal composer.json: %v", err)
@@ -234,12 +309,17 @@ func WriteComposerJSON(composerPath string, deps []utils.Dependency) error {
 	return os.WriteFile(composerPath, data, 0644)
 }
 
-// ParsePHPFiles scans .php files for require/include/use statements
-func ParsePHPFiles(projectDir string) ([]utils.Dependency, error) {
+// ParsePHPFiles scans .php files for require/include/use statements. A
+// `use` statement whose namespace we can't map to a composer vendor/
+// package name (no backslash, i.e. not namespaced) is reported as an
+// Unknown instead of being dropped silently.
+func ParsePHPFiles(projectDir string) ([]utils.Dependency, []utils.Unknown, error) {
 	var deps []utils.Dependency
+	var unknowns []utils.Unknown
 
 	requireRegex := regexp.MustCompile(`\b(require|include)(_once)?\s*['"]([^'"]+)['"]`)
 	useRegex := regexp.MustCompile(`^use\s+([A-Za-z0-9_\\]+)`)
+	useLine := regexp.MustCompile(`^use\s+`)
 
 	err := filepath.WalkDir(projectDir, func(path string, d os.DirEntry, err error) error {
 		if err != nil || d.IsDir() {
@@ -255,7 +335,9 @@ func ParsePHPFiles(projectDir string) ([]utils.Dependency, error) {
 		defer f.Close()
 
 		scanner := bufio.NewScanner(f)
+		lineNum := 0
 		for scanner.Scan() {
+			lineNum++
 			line := strings.TrimSpace(scanner.Text())
 			if m := requireRegex.FindStringSubmatch(line); len(m) > 3 {
 				pkg := m[3]
@@ -268,6 +350,15 @@ func ParsePHPFiles(projectDir string) ([]utils.Dependency, error) {
 				})
 			}
 			if m := useRegex.FindStringSubmatch(line); len(m) > 1 {
+				if !strings.Contains(m[1], "\\") {
+					unknowns = append(unknowns, utils.Unknown{
+						File:    path,
+						Line:    lineNum,
+						Reason:  "use statement has no namespace to map to a composer package",
+						RawText: line,
+					})
+					continue
+				}
 				pkg := strings.ReplaceAll(m[1], "\\", "/")
 				deps = append(deps, utils.Dependency{
 					GroupID:    "packagist",
@@ -276,12 +367,31 @@ func ParsePHPFiles(projectDir string) ([]utils.Dependency, error) {
 					Scope:      "compile",
 					Key:        pkg,
 				})
+			} else if useLine.MatchString(line) {
+				unknowns = append(unknowns, utils.Unknown{
+					File:    path,
+					Line:    lineNum,
+					Reason:  "unmatched use statement",
+					RawText: line,
+				})
 			}
 		}
 		return scanner.Err()
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return deps, nil
+	return deps, unknowns, nil
+}
+
+// EmitSBOM writes deps to w as an SBOM in the given format ("cyclonedx" or
+// "spdx"); see utils/sbom.
+func (h *PHPHandler) EmitSBOM(deps []utils.Dependency, w io.Writer, format string) error {
+	return sbom.Emit(deps, w, format)
+}
+
+// ExportSBOM writes deps to outPath as an SBOM in the given format
+// ("cyclonedx" or "spdx"); see utils/sbom.ExportToFile.
+func (h *PHPHandler) ExportSBOM(deps []utils.Dependency, format, outPath, projectDir string) error {
+	return sbom.ExportToFile(deps, format, outPath, projectDir)
 }
@@ -0,0 +1,358 @@
+package pythonhandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"ort-recovery/utils"
+)
+
+// noTransitiveEnabled disables the transitive dependency closure walk
+// (see transitiveClosure), wired to the --no-transitive flag or the
+// ORT_RECOVERY_NO_TRANSITIVE=1 env var, mirroring javahandler's Online
+// toggle.
+var noTransitiveEnabled = os.Getenv("ORT_RECOVERY_NO_TRANSITIVE") == "1"
+
+// SetNoTransitive opts a scan out of transitive dependency resolution,
+// keeping Scan's result to direct (declared + scanned) dependencies only.
+func SetNoTransitive(v bool) { noTransitiveEnabled = v }
+
+// NoTransitive reports whether SetNoTransitive has been enabled.
+func NoTransitive() bool { return noTransitiveEnabled }
+
+// DepTree is the transitive-dependency resolution state transitiveClosure
+// drains: ToProcess holds distribution names queued for a PyPI metadata
+// fetch, Resolved holds every dependency already resolved (keyed by its
+// PEP 503 normalized name), and Missing records names PyPI had no record
+// of (withdrawn or private packages), so the walk doesn't keep retrying
+// them. Named after yay's own dependency-graph rewrite for AUR installs.
+type DepTree struct {
+	ToProcess []string
+	Resolved  map[string]utils.Dependency
+	Missing   map[string]struct{}
+}
+
+// DepOrder is a topologically sorted install order: a dependency always
+// appears after everything it itself requires, the order a package
+// manager would actually install them in.
+type DepOrder []string
+
+// pep503SeparatorRe matches PEP 503's run-of-separator normalization rule
+// for distribution names.
+var pep503SeparatorRe = regexp.MustCompile(`[-_.]+`)
+
+// normalizePyPIName applies PEP 503 name normalization (lowercase, runs
+// of "-"/"_"/"." collapsed to a single "-"), so "Flask-Cors" and
+// "flask_cors" are recognized as the same dependency while walking the
+// graph.
+func normalizePyPIName(name string) string {
+	return strings.ToLower(pep503SeparatorRe.ReplaceAllString(name, "-"))
+}
+
+// pypiRequirement is one parsed entry of a PyPI release's requires_dist
+// metadata: a distribution name plus its raw (unevaluated) version
+// specifier.
+type pypiRequirement struct {
+	Name      string
+	Specifier string
+}
+
+// requiresDistNameRe pulls the leading distribution name off a
+// requires_dist entry, e.g. "charset-normalizer" out of
+// "charset-normalizer (<4,>=2) ; extra == \"full\"".
+var requiresDistNameRe = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// parseRequiresDist decodes a release's requires_dist entries into the
+// requirements transitiveClosure actually needs to walk: requirements
+// gated behind an `extra == "..."` marker are dropped, since those are
+// only pulled in by an explicit extras install (e.g. "requests[socks]"),
+// which CollectPythonImports has no way to know was requested. Markers
+// referencing python_version/sys_platform are left unevaluated rather
+// than built out into a full PEP 508 environment-marker grammar -- a
+// deliberate scope cut, not an oversight.
+func parseRequiresDist(raw []string) []pypiRequirement {
+	var out []pypiRequirement
+	for _, r := range raw {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		main, marker, hasMarker := strings.Cut(r, ";")
+		if hasMarker && strings.Contains(marker, "extra") {
+			continue
+		}
+		main = strings.TrimSpace(main)
+		name := requiresDistNameRe.FindString(main)
+		if name == "" {
+			continue
+		}
+		rest := strings.TrimSpace(main[len(name):])
+		if strings.HasPrefix(rest, "[") {
+			if end := strings.Index(rest, "]"); end != -1 {
+				rest = strings.TrimSpace(rest[end+1:])
+			}
+		}
+		spec := strings.Trim(rest, "()")
+		out = append(out, pypiRequirement{Name: name, Specifier: strings.TrimSpace(spec)})
+	}
+	return out
+}
+
+// exactPinRe pulls an "==" pin out of a version specifier.
+var exactPinRe = regexp.MustCompile(`==\s*([A-Za-z0-9.\-]+)`)
+
+func exactPin(specifier string) string {
+	m := exactPinRe.FindStringSubmatch(specifier)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// conflictingPin reports whether specifier pins its dependency to an
+// exact version other than resolvedVersion -- the narrow case
+// innerConflicts exists to catch. It isn't a full PEP 440 range solver
+// (it doesn't check whether ">=1,<2" and ">=1.5" actually overlap): two
+// incompatible exact pins is the failure mode that actually bites a
+// recovered manifest, and the one yay's own innerConflicts check targets.
+func conflictingPin(resolvedVersion, specifier string) bool {
+	pin := exactPin(specifier)
+	return pin != "" && pin != resolvedVersion
+}
+
+// transitiveDistFile and transitiveCacheFile locate the per-project
+// requires_dist cache, keyed by "name@version", so repeated scans don't
+// re-query PyPI for a release this project has already resolved (see
+// utils/osv.go's OSV result cache for the same convention).
+const transitiveCacheDir = ".ort-recovery"
+const transitiveCacheFile = "python-transitive-cache.json"
+
+type transitiveCacheEntry struct {
+	Found        bool     `json:"found"`
+	RequiresDist []string `json:"requiresDist,omitempty"`
+}
+
+type transitiveCache struct {
+	Versions map[string]transitiveCacheEntry `json:"versions"`
+}
+
+func loadTransitiveCache(projectDir string) *transitiveCache {
+	c := &transitiveCache{Versions: map[string]transitiveCacheEntry{}}
+	data, err := os.ReadFile(filepath.Join(projectDir, transitiveCacheDir, transitiveCacheFile))
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return &transitiveCache{Versions: map[string]transitiveCacheEntry{}}
+	}
+	if c.Versions == nil {
+		c.Versions = map[string]transitiveCacheEntry{}
+	}
+	return c
+}
+
+func (c *transitiveCache) save(projectDir string) {
+	dir := filepath.Join(projectDir, transitiveCacheDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(dir, transitiveCacheFile), data, 0644)
+}
+
+// fetchRequiresDist returns the requires_dist entries PyPI reports for
+// name@version, querying /pypi/<name>/<version>/json and caching the
+// result (including a miss) on disk under projectDir/.ort-recovery.
+func fetchRequiresDist(projectDir, name, version string) ([]string, bool) {
+	key := name + "@" + version
+	cache := loadTransitiveCache(projectDir)
+	if entry, ok := cache.Versions[key]; ok {
+		return entry.RequiresDist, entry.Found
+	}
+	if pypiOfflineMode {
+		return nil, false
+	}
+
+	reqs, found := fetchRequiresDistUncached(name, version)
+	cache.Versions[key] = transitiveCacheEntry{Found: found, RequiresDist: reqs}
+	cache.save(projectDir)
+	return reqs, found
+}
+
+func fetchRequiresDistUncached(name, version string) ([]string, bool) {
+	resp, err := pypiHTTPClient.Get(fmt.Sprintf("https://pypi.org/pypi/%s/%s/json", name, version))
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, false
+	}
+	var info struct {
+		Info struct {
+			RequiresDist []string `json:"requires_dist"`
+		} `json:"info"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, false
+	}
+	return info.Info.RequiresDist, true
+}
+
+// resolveLatestVersion returns the current published version of name, for
+// a transitive requirement with no exact pin of its own; "" if PyPI has
+// no record of it.
+func resolveLatestVersion(name string) string {
+	info, err := fetchPyPIProjectInfo(name)
+	if err != nil || info == nil {
+		return ""
+	}
+	return info.Info.Version
+}
+
+// requirement is one (parent, specifier) edge recorded while walking the
+// graph, kept so a ConflictError can report every requirement on a
+// dependency, not just the two that first disagreed.
+type requirement struct {
+	parent    string
+	specifier string
+}
+
+// transitiveClosure walks the runtime requires_dist graph of every
+// dependency in roots, recursing through PyPI JSON metadata (cached on
+// disk, see fetchRequiresDist) until every transitive requirement is
+// either resolved or confirmed missing. It returns the newly discovered
+// dependencies (Scope "transitive", not already present in roots) plus
+// their DepOrder, or a *utils.ConflictError (wrapped with %w) the first
+// time two parents pin the same dependency to incompatible exact
+// versions -- mirroring yay's innerConflicts check rather than silently
+// picking one parent's pin over the other's.
+func transitiveClosure(projectDir string, roots []utils.Dependency) ([]utils.Dependency, DepOrder, error) {
+	tree := &DepTree{Resolved: map[string]utils.Dependency{}, Missing: map[string]struct{}{}}
+
+	rootNames := map[string]struct{}{}
+	var queue []string
+	for _, r := range roots {
+		key := normalizePyPIName(r.ArtifactID)
+		rootNames[key] = struct{}{}
+		tree.Resolved[key] = r
+		tree.ToProcess = append(tree.ToProcess, key)
+		queue = append(queue, key)
+	}
+
+	requiredBy := map[string][]requirement{}
+	edges := map[string][]string{}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		dep := tree.Resolved[name]
+		if dep.Version == "" {
+			tree.Missing[name] = struct{}{}
+			continue
+		}
+
+		raw, found := fetchRequiresDist(projectDir, dep.ArtifactID, dep.Version)
+		if !found {
+			continue
+		}
+
+		for _, req := range parseRequiresDist(raw) {
+			childName := normalizePyPIName(req.Name)
+			edges[name] = append(edges[name], childName)
+			requiredBy[childName] = append(requiredBy[childName], requirement{parent: dep.ArtifactID, specifier: req.Specifier})
+
+			if existing, ok := tree.Resolved[childName]; ok {
+				if conflictingPin(existing.Version, req.Specifier) {
+					var specs, parents []string
+					for _, rb := range requiredBy[childName] {
+						specs = append(specs, rb.specifier)
+						parents = append(parents, rb.parent)
+					}
+					return nil, nil, fmt.Errorf("python transitive resolution: %w", &utils.ConflictError{
+						Name:       req.Name,
+						Specifiers: specs,
+						RequiredBy: parents,
+					})
+				}
+				continue
+			}
+			if _, ok := tree.Missing[childName]; ok {
+				continue
+			}
+
+			version := exactPin(req.Specifier)
+			if version == "" {
+				version = resolveLatestVersion(req.Name)
+			}
+			if version == "" {
+				tree.Missing[childName] = struct{}{}
+				continue
+			}
+
+			childDep := utils.Dependency{
+				GroupID:    "pypi",
+				ArtifactID: req.Name,
+				Version:    version,
+				Scope:      "transitive",
+				Key:        req.Name,
+				Origin:     "transitive",
+			}
+			tree.Resolved[childName] = childDep
+			tree.ToProcess = append(tree.ToProcess, childName)
+			queue = append(queue, childName)
+		}
+	}
+
+	var added []utils.Dependency
+	for name, dep := range tree.Resolved {
+		if _, ok := rootNames[name]; ok {
+			continue
+		}
+		added = append(added, dep)
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].ArtifactID < added[j].ArtifactID })
+
+	var rootOrder []string
+	for _, r := range roots {
+		rootOrder = append(rootOrder, normalizePyPIName(r.ArtifactID))
+	}
+	order := topoSort(rootOrder, edges)
+
+	return added, order, nil
+}
+
+// topoSort returns a leaves-first install order over edges (name -> the
+// names it directly requires), starting from roots. Cycles -- rare in
+// practice, but PyPI doesn't forbid them -- can't stall it: a name is
+// marked visited before its own requirements are walked, so revisiting it
+// through a cycle just returns immediately.
+func topoSort(roots []string, edges map[string][]string) DepOrder {
+	visited := map[string]bool{}
+	var order []string
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		for _, child := range edges[name] {
+			visit(child)
+		}
+		order = append(order, name)
+	}
+	for _, r := range roots {
+		visit(r)
+	}
+	return DepOrder(order)
+}
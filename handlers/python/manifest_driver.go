@@ -0,0 +1,76 @@
+package pythonhandler
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"ort-recovery/utils"
+)
+
+// ManifestDriver is one supported Python manifest format -- pyproject.toml,
+// setup.py, requirements.txt, Pipfile, environment.yml today, and whatever
+// a future driver adds (PDM's pdm.lock, uv's uv.lock, Poetry 2.0's PEP 621
+// merge) -- without Scan/GenerateRecoveryFile's dispatch logic growing a
+// case for every one of them. A project where more than one driver's
+// Detect returns true (e.g. both pyproject.toml and a Poetry lock file)
+// is resolved by Priority rather than silently preferring whichever
+// format happened to be checked first.
+type ManifestDriver interface {
+	// Name is the manifest's conventional file name, e.g. "pyproject.toml".
+	// detectManifest joins it onto the project directory to build the path
+	// passed to Parse/Write.
+	Name() string
+	// Detect reports whether this driver's manifest is present in dir.
+	Detect(dir string) bool
+	// Priority breaks ties when more than one driver's Detect returns true
+	// for the same project; detectManifest picks the highest value.
+	Priority() int
+	// Parse reads path (as located by Detect) into declared dependencies.
+	Parse(path string) ([]utils.Dependency, error)
+	// Write renders deps back out to path in this driver's native format.
+	Write(path string, deps []utils.Dependency) error
+}
+
+var (
+	driversMu sync.Mutex
+	drivers   []ManifestDriver
+)
+
+// RegisterManifestDriver adds driver to the process-global registry
+// detectManifest consults, the same way database/sql's Register lets a
+// driver be added without touching the core dispatch code. Meant to be
+// called from an init() in the driver's own file; panics on a duplicate
+// Name(), the same contract database/sql uses for a duplicate driver name.
+func RegisterManifestDriver(driver ManifestDriver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	for _, d := range drivers {
+		if d.Name() == driver.Name() {
+			panic(fmt.Sprintf("pythonhandler: RegisterManifestDriver called twice for %q", driver.Name()))
+		}
+	}
+	drivers = append(drivers, driver)
+}
+
+// registeredDrivers returns every registered driver, highest Priority()
+// first (ties broken by registration order, for deterministic output).
+func registeredDrivers() []ManifestDriver {
+	driversMu.Lock()
+	out := make([]ManifestDriver, len(drivers))
+	copy(out, drivers)
+	driversMu.Unlock()
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Priority() > out[j].Priority() })
+	return out
+}
+
+// driverFor returns the highest-priority registered driver whose Detect
+// returns true for dir, or nil if none match.
+func driverFor(dir string) ManifestDriver {
+	for _, d := range registeredDrivers() {
+		if d.Detect(dir) {
+			return d
+		}
+	}
+	return nil
+}
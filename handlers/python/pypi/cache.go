@@ -0,0 +1,78 @@
+package pypi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// cacheEntry is one package's cached simple-index response. Body is the
+// raw index payload exactly as the server returned it (HTML or JSON,
+// whichever content type was negotiated); ETag/LastModified let a later
+// request send a conditional GET so an unchanged index costs a 304
+// instead of a full re-download.
+type cacheEntry struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	ContentType  string `json:"contentType,omitempty"`
+	Body         string `json:"body"`
+}
+
+// pep503SeparatorRe mirrors pythonhandler's normalizePyPIName (duplicated
+// rather than imported, since pythonhandler imports this package).
+var pep503SeparatorRe = regexp.MustCompile(`[-_.]+`)
+
+func normalizeName(name string) string {
+	return pep503SeparatorRe.ReplaceAllString(name, "-")
+}
+
+// cacheDir returns ~/.cache/ort-recovery/pypi, creating it if needed.
+func cacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "ort-recovery", "pypi")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// cacheFileFor hashes indexURL+name into a cache file name, so different
+// configured index URLs (see SetIndexURL) don't collide in one cache dir.
+func cacheFileFor(dir, indexURL, name string) string {
+	sum := sha256.Sum256([]byte(indexURL + "|" + normalizeName(name)))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func loadCacheEntry(indexURL, name string) (*cacheEntry, bool) {
+	dir, err := cacheDir()
+	if err != nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(cacheFileFor(dir, indexURL, name))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+func saveCacheEntry(indexURL, name string, entry *cacheEntry) {
+	dir, err := cacheDir()
+	if err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(cacheFileFor(dir, indexURL, name), data, 0644)
+}
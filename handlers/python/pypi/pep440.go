@@ -0,0 +1,190 @@
+// Package pypi is a PEP 503/PEP 691 simple-index client for resolving a
+// PyPI package name to its newest version matching a set of PEP 440
+// specifiers, with an on-disk cache so repeated scans don't re-fetch an
+// unchanged index. See resolve.go for the ResolveLatest entry point.
+package pypi
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// parsedVersion is a deliberately narrow PEP 440 version model: release
+// segments (e.g. "1.2.3" -> [1,2,3]), a stage (pre-release, final, or
+// post-release), and, when relevant, the pre-release letter's rank
+// (a/b/rc) and the pre- or post-release number. Epochs ("1!2.0"),
+// dev-releases, and local version segments ("+cpu") are stripped rather
+// than modeled -- this covers the version strings that actually appear on
+// PyPI's simple index for everyday packages, not the full PEP 440 grammar.
+type parsedVersion struct {
+	release []int
+	stage   int // 0 = pre-release, 1 = final, 2 = post-release
+	preRank int // 0=a, 1=b, 2=rc; only meaningful when stage == 0
+	num     int // pre-release or post-release number; 0 for a final release
+}
+
+var versionRe = regexp.MustCompile(`(?i)^(?:\d+!)?(\d+(?:\.\d+)*)((?:a|b|rc)\d+)?(?:\.post(\d+))?(?:\.dev\d+)?$`)
+
+func parseVersion(v string) (parsedVersion, bool) {
+	v = strings.TrimSpace(v)
+	if i := strings.IndexByte(v, '+'); i != -1 {
+		v = v[:i] // drop the local version segment
+	}
+	m := versionRe.FindStringSubmatch(v)
+	if m == nil {
+		return parsedVersion{}, false
+	}
+
+	var release []int
+	for _, part := range strings.Split(m[1], ".") {
+		n, _ := strconv.Atoi(part)
+		release = append(release, n)
+	}
+	pv := parsedVersion{release: release, stage: 1}
+
+	switch {
+	case m[2] != "":
+		pv.stage = 0
+		letter := strings.ToLower(strings.TrimRight(m[2], "0123456789"))
+		pv.num, _ = strconv.Atoi(m[2][len(letter):])
+		switch letter {
+		case "a":
+			pv.preRank = 0
+		case "b":
+			pv.preRank = 1
+		case "rc":
+			pv.preRank = 2
+		}
+	case m[3] != "":
+		pv.stage = 2
+		pv.num, _ = strconv.Atoi(m[3])
+	}
+	return pv, true
+}
+
+// compareRelease compares two release-segment slices numerically,
+// treating a missing trailing segment as 0 (so "1.2" == "1.2.0").
+func compareRelease(a, b []int) int {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+// compareVersions returns <0, 0, >0 as a compares below, equal to, or
+// above b. Versions that don't parse as PEP 440 fall back to a lexical
+// comparison, which at least keeps the newest-wins logic deterministic.
+func compareVersions(a, b string) int {
+	pa, oka := parseVersion(a)
+	pb, okb := parseVersion(b)
+	if !oka || !okb {
+		return strings.Compare(a, b)
+	}
+	if c := compareRelease(pa.release, pb.release); c != 0 {
+		return c
+	}
+	if pa.stage != pb.stage {
+		return pa.stage - pb.stage // pre-release(0) < final(1) < post-release(2)
+	}
+	if pa.stage == 0 && pa.preRank != pb.preRank {
+		return pa.preRank - pb.preRank
+	}
+	return pa.num - pb.num
+}
+
+// specClauseRe matches one comma-separated PEP 440 specifier clause, e.g.
+// ">=1.2" or "==2.0.*".
+var specClauseRe = regexp.MustCompile(`^(==|!=|<=|>=|<|>|~=)\s*([A-Za-z0-9.\-]+\*?)$`)
+
+// satisfiesSpecifier reports whether version matches every clause of a
+// (possibly comma-separated) PEP 440 specifier set. An unrecognized
+// clause is skipped rather than rejected -- a conservative default that
+// favors resolving a version over refusing one PEP 440's looser corners
+// (epochs, local versions, complex wildcards) would otherwise trip on.
+func satisfiesSpecifier(version, specifier string) bool {
+	specifier = strings.TrimSpace(specifier)
+	if specifier == "" {
+		return true
+	}
+	for _, clause := range strings.Split(specifier, ",") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		m := specClauseRe.FindStringSubmatch(clause)
+		if m == nil {
+			continue
+		}
+		if !satisfiesClause(version, m[1], m[2]) {
+			return false
+		}
+	}
+	return true
+}
+
+func satisfiesClause(version, op, target string) bool {
+	if op == "==" && strings.HasSuffix(target, ".*") {
+		return strings.HasPrefix(version, strings.TrimSuffix(target, ".*"))
+	}
+
+	if op == "~=" {
+		pt, ok := parseVersion(target)
+		if !ok || len(pt.release) < 2 {
+			return false
+		}
+		prefix := pt.release[:len(pt.release)-1]
+		pv, ok := parseVersion(version)
+		if !ok || len(pv.release) < len(prefix) {
+			return false
+		}
+		for i, seg := range prefix {
+			if pv.release[i] != seg {
+				return false
+			}
+		}
+		return compareVersions(version, target) >= 0
+	}
+
+	cmp := compareVersions(version, target)
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<=":
+		return cmp <= 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case ">":
+		return cmp > 0
+	default:
+		return false
+	}
+}
+
+// satisfiesAll reports whether version satisfies every specifier in
+// constraints (each may itself be a comma-separated clause list).
+func satisfiesAll(version string, constraints []string) bool {
+	for _, c := range constraints {
+		if !satisfiesSpecifier(version, c) {
+			return false
+		}
+	}
+	return true
+}
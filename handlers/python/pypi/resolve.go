@@ -0,0 +1,65 @@
+package pypi
+
+import (
+	"fmt"
+	"os"
+)
+
+// offlineMode disables every network call this package makes, mirroring
+// the ORT_RECOVERY_OFFLINE convention already used by utils/osv.go,
+// handlers/dotnet/nugetindex, and handlers/python's own PyPI fallback.
+var offlineMode = os.Getenv("ORT_RECOVERY_OFFLINE") == "1"
+
+// SetOffline opts out of network calls, relying solely on whatever's
+// already in the on-disk index cache. Wired to the --offline flag.
+func SetOffline(v bool) { offlineMode = v }
+
+// Offline reports whether SetOffline has been enabled.
+func Offline() bool { return offlineMode }
+
+// indexURL is the configured PEP 503/691 simple-index base URL.
+var indexURL = "https://pypi.org/simple/"
+
+// SetIndexURL points ResolveLatest at a different simple index, e.g. a
+// private PyPI mirror or Artifactory's PyPI proxy.
+func SetIndexURL(url string) { indexURL = url }
+
+// IndexURL returns the configured simple-index base URL.
+func IndexURL() string { return indexURL }
+
+// ResolveLatest returns the newest version of name on the configured
+// simple index that satisfies every PEP 440 specifier in constraints
+// (pass nil for "any version"). It's the third tier pythonhandler falls
+// back to for a discovered-but-unpinned import, after the declared
+// manifest and Syft.
+//
+// It does not filter by the project's own requires-python: doing that
+// correctly means intersecting two PEP 440 specifier sets, and the only
+// place that information could be threaded in without widening this
+// signature is a package-global set once at startup -- wrong here, since
+// requires-python varies per project and -jobs processes several projects
+// concurrently. Left as future work rather than risk a cross-project race.
+func ResolveLatest(name string, constraints []string) (string, error) {
+	entries, err := fetchIndex(name)
+	if err != nil {
+		return "", err
+	}
+
+	var best string
+	for _, e := range entries {
+		version, ok := versionFromFilename(e.Filename)
+		if !ok {
+			continue
+		}
+		if !satisfiesAll(version, constraints) {
+			continue
+		}
+		if best == "" || compareVersions(version, best) > 0 {
+			best = version
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("pypi: no version of %s satisfies constraints %v", name, constraints)
+	}
+	return best, nil
+}
@@ -0,0 +1,163 @@
+package pypi
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// fileEntry is one project file listed on the simple index: its filename
+// (a wheel or sdist) and, when the index advertises it, the PEP 345
+// "requires-python" constraint that release declared.
+type fileEntry struct {
+	Filename       string
+	RequiresPython string
+}
+
+// fetchIndex returns name's simple-index file listing, preferring the
+// on-disk cache via a conditional GET and falling back to a stale cache
+// entry if the network request fails or the server errors -- a simple
+// index a previous run already saw is better than no data at all.
+func fetchIndex(name string) ([]fileEntry, error) {
+	cached, hasCache := loadCacheEntry(IndexURL(), name)
+
+	if Offline() {
+		if hasCache {
+			return parseIndexBody([]byte(cached.Body), cached.ContentType)
+		}
+		return nil, fmt.Errorf("pypi: offline mode and no cached index for %s", name)
+	}
+
+	url := strings.TrimRight(IndexURL(), "/") + "/" + name + "/"
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Ask for PEP 691 JSON first; fall back to PEP 503 HTML.
+	req.Header.Set("Accept", "application/vnd.pypi.simple.v1+json, application/vnd.pypi.simple.v1+html;q=0.2, text/html;q=0.01")
+	if hasCache {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		if hasCache {
+			return parseIndexBody([]byte(cached.Body), cached.ContentType)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && hasCache {
+		return parseIndexBody([]byte(cached.Body), cached.ContentType)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if hasCache {
+			return parseIndexBody([]byte(cached.Body), cached.ContentType)
+		}
+		return nil, fmt.Errorf("pypi: simple index request for %s returned %s", name, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	contentType := resp.Header.Get("Content-Type")
+	saveCacheEntry(IndexURL(), name, &cacheEntry{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		ContentType:  contentType,
+		Body:         string(body),
+	})
+	return parseIndexBody(body, contentType)
+}
+
+func parseIndexBody(body []byte, contentType string) ([]fileEntry, error) {
+	if strings.Contains(contentType, "json") {
+		return parseJSONIndex(body)
+	}
+	return parseHTMLIndex(body)
+}
+
+// parseJSONIndex decodes a PEP 691 project detail response.
+func parseJSONIndex(body []byte) ([]fileEntry, error) {
+	var doc struct {
+		Files []struct {
+			Filename       string `json:"filename"`
+			RequiresPython string `json:"requires-python"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	entries := make([]fileEntry, 0, len(doc.Files))
+	for _, f := range doc.Files {
+		entries = append(entries, fileEntry{Filename: f.Filename, RequiresPython: f.RequiresPython})
+	}
+	return entries, nil
+}
+
+var (
+	anchorRe             = regexp.MustCompile(`(?is)<a\s+([^>]*)>([^<]*)</a>`)
+	requiresPythonAttrRe = regexp.MustCompile(`data-requires-python=(?:"([^"]*)"|'([^']*)')`)
+)
+
+// parseHTMLIndex extracts each <a> tag's link text (the filename) and its
+// data-requires-python attribute from a PEP 503 index page. It doesn't
+// parse general HTML -- a real tag-soup parser is unwarranted for a page
+// whose whole schema is "a flat list of anchor tags" -- so markup PyPI
+// itself doesn't produce (nested tags inside an anchor, for instance)
+// isn't handled.
+func parseHTMLIndex(body []byte) ([]fileEntry, error) {
+	var entries []fileEntry
+	for _, m := range anchorRe.FindAllStringSubmatch(string(body), -1) {
+		attrs, text := m[1], strings.TrimSpace(m[2])
+		filename := html.UnescapeString(text)
+		if filename == "" {
+			continue
+		}
+		requiresPython := ""
+		if rm := requiresPythonAttrRe.FindStringSubmatch(attrs); rm != nil {
+			if rm[1] != "" {
+				requiresPython = html.UnescapeString(rm[1])
+			} else {
+				requiresPython = html.UnescapeString(rm[2])
+			}
+		}
+		entries = append(entries, fileEntry{Filename: filename, RequiresPython: requiresPython})
+	}
+	return entries, nil
+}
+
+// wheelVersionRe and sdistVersionRe pull the version segment out of a
+// wheel or sdist filename. They don't verify the leading segment matches
+// the project name being queried -- PEP 427 wheel names replace runs of
+// "-_." in the project name with "_", and sdist names vary by build
+// backend, so a strict match would reject filenames real indexes serve --
+// a deliberate scope cut, not an oversight.
+var (
+	wheelVersionRe = regexp.MustCompile(`^[A-Za-z0-9_.]+-([A-Za-z0-9_.!+]+)-[^-]+-[^-]+-[^-]+\.whl$`)
+	sdistVersionRe = regexp.MustCompile(`^[A-Za-z0-9_.]+-([A-Za-z0-9_.!+]+)\.(?:tar\.gz|tar\.bz2|zip)$`)
+)
+
+func versionFromFilename(filename string) (string, bool) {
+	if m := wheelVersionRe.FindStringSubmatch(filename); m != nil {
+		return m[1], true
+	}
+	if m := sdistVersionRe.FindStringSubmatch(filename); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
@@ -0,0 +1,237 @@
+package pythonhandler
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ImportRef is one import statement CollectPythonImportRefs found, with
+// enough provenance for Scan to log where a recovered dependency
+// originated and to decide its Scope: Conditional is true when the
+// import is only reachable inside a `try: ... except ImportError:` (or
+// bare `except:`) block, the common "optional acceleration backend"
+// pattern (e.g. `try: import ujson as json except ImportError: import
+// json`).
+type ImportRef struct {
+	Module      string
+	File        string
+	Line        int
+	Conditional bool
+}
+
+// CollectPythonImportRefs walks projectDir's .py files and parses every
+// import statement with a small hand-rolled scanner rather than two
+// line-regexes, so it copes with `import a, b`, aliased imports
+// (`import x as y`), imports split across a parenthesized `from x import
+// (...)`, `importlib.import_module("x")`/`__import__("x")` string-literal
+// forms, and try/except-gated conditional imports. Relative imports
+// (`from .x import y`) and `from __future__ import ...` are excluded
+// entirely, since neither names an external dependency. It's not a full
+// Python parser -- there's no real tokenizer, so a string literal
+// containing an unbalanced bracket or a `#` can throw off logical-line
+// joining or comment stripping -- but it covers the import forms that
+// actually show up in real projects, which a plain `python -m ast` shell
+// out would require bundling a Python interpreter to match anyway.
+func CollectPythonImportRefs(projectDir string) ([]ImportRef, error) {
+	var refs []ImportRef
+
+	err := filepath.Walk(projectDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && (strings.HasPrefix(info.Name(), "venv") || info.Name() == ".venv" || info.Name() == "env") {
+			return filepath.SkipDir
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if !strings.HasSuffix(path, ".py") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil // ignore unreadable files
+		}
+		rel, err := filepath.Rel(projectDir, path)
+		if err != nil {
+			rel = path
+		}
+		refs = append(refs, parsePythonImports(string(data), rel)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// pyLogicalLine is one Python statement after joining the backslash- and
+// bracket-continued physical lines that make it up.
+type pyLogicalLine struct {
+	Text      string
+	StartLine int
+	Indent    int
+}
+
+// joinLogicalLines merges continued physical lines (a trailing "\", or an
+// unbalanced ( [ { that a later line closes) into single logical lines,
+// stripping "#" comments first. It doesn't track string literals, so a
+// bracket or "#" inside one can mis-join or mis-split a line -- a
+// deliberate scope cut, not an oversight.
+func joinLogicalLines(content string) []pyLogicalLine {
+	var out []pyLogicalLine
+	var buf strings.Builder
+	startLine := 0
+	depth := 0
+
+	rawLines := strings.Split(content, "\n")
+	for i, raw := range rawLines {
+		stripped := stripPyComment(raw)
+		if buf.Len() == 0 {
+			startLine = i + 1
+		}
+		trimmed := strings.TrimRight(stripped, " \t\r")
+		continued := strings.HasSuffix(trimmed, "\\")
+		if continued {
+			trimmed = strings.TrimSuffix(trimmed, "\\")
+		}
+		buf.WriteString(trimmed)
+		depth += strings.Count(trimmed, "(") + strings.Count(trimmed, "[") + strings.Count(trimmed, "{")
+		depth -= strings.Count(trimmed, ")") + strings.Count(trimmed, "]") + strings.Count(trimmed, "}")
+		if depth < 0 {
+			depth = 0
+		}
+		if continued || depth > 0 {
+			buf.WriteString(" ")
+			continue
+		}
+		out = append(out, pyLogicalLine{Text: buf.String(), StartLine: startLine, Indent: pyIndentOf(rawLines[startLine-1])})
+		buf.Reset()
+	}
+	if strings.TrimSpace(buf.String()) != "" {
+		out = append(out, pyLogicalLine{Text: buf.String(), StartLine: startLine, Indent: pyIndentOf(rawLines[startLine-1])})
+	}
+	return out
+}
+
+var pyCommentRe = regexp.MustCompile(`#.*$`)
+
+func stripPyComment(line string) string {
+	return pyCommentRe.ReplaceAllString(line, "")
+}
+
+func pyIndentOf(line string) int {
+	n := 0
+	for _, r := range line {
+		if r != ' ' && r != '\t' {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// pyTryFrame tracks one active try/except construct while parsing:
+// indent is the column "try:" itself sits at, pending indexes refs that
+// belong to it (from either the try body or any except body, since both
+// are conditionally executed), and catchesImportError records whether
+// any except clause seen so far for this try names ImportError (or
+// catches broadly with a bare "except:"/"except Exception").
+type pyTryFrame struct {
+	indent             int
+	pending            []int
+	catchesImportError bool
+}
+
+var (
+	pyFromImportRe  = regexp.MustCompile(`^from\s+([.\w]*)\s+import\s+(.+)$`)
+	pyImportRe      = regexp.MustCompile(`^import\s+(.+)$`)
+	pyDynamicCallRe = regexp.MustCompile(`(?:importlib\.import_module|__import__)\s*\(\s*["']([^"']+)["']`)
+	pyAsSuffixRe    = regexp.MustCompile(`\s+as\s+\w+$`)
+)
+
+// parsePythonImports runs the logical-line/try-except state machine
+// described on CollectPythonImportRefs over one file's content.
+func parsePythonImports(content, file string) []ImportRef {
+	lines := joinLogicalLines(content)
+
+	var refs []ImportRef
+	var stack []*pyTryFrame
+
+	finalize := func(frame *pyTryFrame) {
+		for _, idx := range frame.pending {
+			refs[idx].Conditional = frame.catchesImportError
+		}
+	}
+
+	recordRoot := func(root string, line int) {
+		root = packageRoot(root)
+		if root == "" || !isExternalPyPackage(root) {
+			return
+		}
+		refs = append(refs, ImportRef{Module: root, File: file, Line: line})
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			top.pending = append(top.pending, len(refs)-1)
+		}
+	}
+
+	for _, ln := range lines {
+		text := strings.TrimSpace(ln.Text)
+		if text == "" {
+			continue
+		}
+
+		// Pop/resolve try frames whose construct has ended at this indent.
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if ln.Indent > top.indent {
+				break
+			}
+			if ln.Indent == top.indent && (strings.HasPrefix(text, "except") || strings.HasPrefix(text, "else") || strings.HasPrefix(text, "finally")) {
+				if strings.HasPrefix(text, "except") {
+					if strings.Contains(text, "ImportError") || strings.Contains(text, "Exception") || strings.TrimRight(text, ":") == "except" {
+						top.catchesImportError = true
+					}
+				}
+				break
+			}
+			finalize(top)
+			stack = stack[:len(stack)-1]
+		}
+
+		if text == "try:" || text == "try" {
+			stack = append(stack, &pyTryFrame{indent: ln.Indent})
+			continue
+		}
+
+		if m := pyFromImportRe.FindStringSubmatch(text); m != nil {
+			module := m[1]
+			if module == "" || strings.HasPrefix(module, ".") || module == "__future__" {
+				continue // relative import or a future-statement: not an external dep
+			}
+			recordRoot(module, ln.StartLine)
+			continue
+		}
+		if m := pyImportRe.FindStringSubmatch(text); m != nil {
+			for _, item := range strings.Split(m[1], ",") {
+				item = strings.TrimSpace(pyAsSuffixRe.ReplaceAllString(strings.TrimSpace(item), ""))
+				if item == "" {
+					continue
+				}
+				recordRoot(item, ln.StartLine)
+			}
+			continue
+		}
+		if m := pyDynamicCallRe.FindStringSubmatch(text); m != nil {
+			recordRoot(m[1], ln.StartLine)
+		}
+	}
+
+	for _, frame := range stack {
+		finalize(frame)
+	}
+	return refs
+}
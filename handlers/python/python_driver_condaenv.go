@@ -0,0 +1,90 @@
+package pythonhandler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"ort-recovery/utils"
+)
+
+func init() {
+	RegisterManifestDriver(condaEnvDriver{})
+}
+
+// condaEnvDriver is the environment.yml ManifestDriver. Lowest priority
+// of the five, matching the order the original hard-coded switch checked
+// manifests in.
+type condaEnvDriver struct{}
+
+func (condaEnvDriver) Name() string  { return "environment.yml" }
+func (condaEnvDriver) Priority() int { return 10 }
+
+func (condaEnvDriver) Detect(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "environment.yml"))
+	return err == nil
+}
+
+func (condaEnvDriver) Parse(path string) ([]utils.Dependency, error) {
+	return ParseCondaEnv(path)
+}
+
+func (condaEnvDriver) Write(path string, deps []utils.Dependency) error {
+	return WriteCondaEnv(path, deps)
+}
+
+// ---------- environment.yml (conda) ----------
+type CondaEnv struct {
+	Dependencies []interface{} `yaml:"dependencies"`
+}
+
+func ParseCondaEnv(path string) ([]utils.Dependency, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []utils.Dependency{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var env CondaEnv
+	if err := yaml.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+	deps := []utils.Dependency{}
+	for _, it := range env.Dependencies {
+		if s, ok := it.(string); ok {
+			parts := strings.SplitN(s, "=", 2)
+			name := parts[0]
+			ver := ""
+			if len(parts) > 1 {
+				ver = parts[1]
+			}
+			deps = append(deps, utils.Dependency{
+				GroupID:    "pypi",
+				ArtifactID: name,
+				Version:    ver,
+				Scope:      "compile",
+				Key:        name,
+			})
+		}
+	}
+	return deps, nil
+}
+
+func WriteCondaEnv(path string, deps []utils.Dependency) error {
+	env := CondaEnv{Dependencies: []interface{}{}}
+	for _, d := range deps {
+		entry := d.ArtifactID
+		if d.Version != "" && d.Version != "latest" {
+			entry += "=" + d.Version
+		}
+		env.Dependencies = append(env.Dependencies, entry)
+	}
+	out, err := yaml.Marshal(&env)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0644)
+}
@@ -0,0 +1,119 @@
+package pythonhandler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	toml "github.com/pelletier/go-toml/v2"
+
+	"ort-recovery/utils"
+)
+
+func init() {
+	RegisterManifestDriver(pipfileDriver{})
+}
+
+// pipfileDriver is the Pipfile ManifestDriver. A project that only
+// committed the resolved Pipfile.lock (no Pipfile) is still detected and
+// parsed -- Pipfile.lock is TOML-compatible enough for ParsePipfile's
+// [packages] table lookup -- but the recovery file is always written out
+// as "Pipfile", since a hand-maintained Pipfile is what pipenv expects to
+// regenerate the lock from.
+type pipfileDriver struct{}
+
+func (pipfileDriver) Name() string  { return "Pipfile" }
+func (pipfileDriver) Priority() int { return 20 }
+
+func (pipfileDriver) Detect(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, "Pipfile")); err == nil {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(dir, "Pipfile.lock"))
+	return err == nil
+}
+
+func (pipfileDriver) Parse(path string) ([]utils.Dependency, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if lockPath := filepath.Join(filepath.Dir(path), "Pipfile.lock"); fileExists(lockPath) {
+			path = lockPath
+		}
+	}
+	return ParsePipfile(path)
+}
+
+func (pipfileDriver) Write(path string, deps []utils.Dependency) error {
+	return WritePipfile(path, deps)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// ---------- Pipfile (TOML) ----------
+func ParsePipfile(path string) ([]utils.Dependency, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []utils.Dependency{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tree := map[string]any{}
+	if err := toml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	deps := []utils.Dependency{}
+	if pkgs, ok := tree["packages"].(map[string]any); ok {
+		for k, v := range pkgs {
+			switch vv := v.(type) {
+			case string:
+				deps = append(deps, utils.Dependency{
+					GroupID:    "pypi",
+					ArtifactID: k,
+					Version:    strings.Trim(vv, `"' `),
+					Scope:      "compile",
+					Key:        k,
+				})
+			case map[string]any:
+				if verRaw, ok := vv["version"]; ok {
+					if vs, ok := verRaw.(string); ok {
+						deps = append(deps, utils.Dependency{
+							GroupID:    "pypi",
+							ArtifactID: k,
+							Version:    strings.Trim(vs, `"' `),
+							Scope:      "compile",
+							Key:        k,
+						})
+					}
+				}
+			}
+		}
+	}
+	return deps, nil
+}
+
+// WritePipfile emits a [packages] table. A dependency with Hashes
+// populated (see FetchHashes) gets pipenv's inline table form instead of
+// a bare version string, mirroring what `pipenv lock` itself writes into
+// Pipfile.lock's per-package entries.
+func WritePipfile(path string, deps []utils.Dependency) error {
+	lines := []string{"[packages]"}
+	for _, d := range deps {
+		switch {
+		case d.Version == "" || d.Version == "latest":
+			lines = append(lines, fmt.Sprintf("%s = \"*\"", d.ArtifactID))
+		case len(d.Hashes) > 0:
+			quoted := make([]string, len(d.Hashes))
+			for i, h := range d.Hashes {
+				quoted[i] = fmt.Sprintf("\"%s\"", h)
+			}
+			lines = append(lines, fmt.Sprintf("%s = { version = \"==%s\", hashes = [%s] }", d.ArtifactID, d.Version, strings.Join(quoted, ", ")))
+		default:
+			lines = append(lines, fmt.Sprintf("%s = \"%s\"", d.ArtifactID, d.Version))
+		}
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+}
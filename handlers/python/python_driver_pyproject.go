@@ -0,0 +1,154 @@
+package pythonhandler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	toml "github.com/pelletier/go-toml/v2"
+
+	"ort-recovery/utils"
+)
+
+func init() {
+	RegisterManifestDriver(pyprojectDriver{})
+}
+
+// pyprojectDriver is the pyproject.toml ManifestDriver: PEP 621
+// [project] dependencies, or failing that Poetry's [tool.poetry]
+// dependencies table. Highest priority of the five, matching the order
+// the original hard-coded switch checked manifests in.
+type pyprojectDriver struct{}
+
+func (pyprojectDriver) Name() string  { return "pyproject.toml" }
+func (pyprojectDriver) Priority() int { return 50 }
+
+func (pyprojectDriver) Detect(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "pyproject.toml"))
+	return err == nil
+}
+
+func (pyprojectDriver) Parse(path string) ([]utils.Dependency, error) {
+	return ParsePyProject(path)
+}
+
+func (pyprojectDriver) Write(path string, deps []utils.Dependency) error {
+	return WritePyProject(path, deps)
+}
+
+// ---------- pyproject.toml ----------
+func ParsePyProject(path string) ([]utils.Dependency, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []utils.Dependency{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tree := map[string]any{}
+	if err := toml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	// try PEP 621: [project] dependencies (array)
+	if project, ok := tree["project"].(map[string]any); ok {
+		if deps, ok := project["dependencies"].([]any); ok {
+			return depsFromTomlArray(deps), nil
+		}
+	}
+	// try poetry: [tool.poetry.dependencies] (table)
+	if tool, ok := tree["tool"].(map[string]any); ok {
+		if poetry, ok := tool["poetry"].(map[string]any); ok {
+			if depTable, ok := poetry["dependencies"].(map[string]any); ok {
+				return depsFromTomlTable(depTable), nil
+			}
+		}
+	}
+	return []utils.Dependency{}, nil
+}
+
+func depsFromTomlArray(arr []any) []utils.Dependency {
+	var deps []utils.Dependency
+	for _, it := range arr {
+		if s, ok := it.(string); ok {
+			name, ver := splitReqLine(s)
+			deps = append(deps, utils.Dependency{
+				GroupID:    "pypi",
+				ArtifactID: name,
+				Version:    ver,
+				Scope:      "compile",
+				Key:        name,
+			})
+		}
+	}
+	return deps
+}
+
+func depsFromTomlTable(tbl map[string]any) []utils.Dependency {
+	var deps []utils.Dependency
+	for k, v := range tbl {
+		if k == "python" {
+			continue
+		}
+		switch val := v.(type) {
+		case string:
+			name, ver := k, strings.Trim(val, `"' `)
+			deps = append(deps, utils.Dependency{
+				GroupID:    "pypi",
+				ArtifactID: name,
+				Version:    ver,
+				Scope:      "compile",
+				Key:        name,
+			})
+		case map[string]any:
+			// poetry can specify { version = "^1.0" }
+			if verRaw, ok := val["version"]; ok {
+				if vs, ok := verRaw.(string); ok {
+					deps = append(deps, utils.Dependency{
+						GroupID:    "pypi",
+						ArtifactID: k,
+						Version:    strings.Trim(vs, `"' `),
+						Scope:      "compile",
+						Key:        k,
+					})
+				}
+			}
+		}
+	}
+	return deps
+}
+
+// WritePyProject emits a minimal [project] dependencies = [...] array. PEP
+// 621 has no per-dependency hash field, so a dependency with Hashes
+// populated (see FetchHashes) instead gets an entry in a
+// [tool.ort-recovery.hashes] table -- our own namespaced table, not a
+// real Poetry/pip construct, since neither defines one for this.
+func WritePyProject(path string, deps []utils.Dependency) error {
+	lines := []string{"[project]", "dependencies = ["}
+	for _, d := range deps {
+		if d.Version == "" || d.Version == "latest" {
+			lines = append(lines, fmt.Sprintf("  \"%s\",", d.ArtifactID))
+		} else {
+			lines = append(lines, fmt.Sprintf("  \"%s==%s\",", d.ArtifactID, d.Version))
+		}
+	}
+	lines = append(lines, "]")
+
+	var hashLines []string
+	for _, d := range deps {
+		if len(d.Hashes) == 0 {
+			continue
+		}
+		quoted := make([]string, len(d.Hashes))
+		for i, h := range d.Hashes {
+			quoted[i] = fmt.Sprintf("\"%s\"", h)
+		}
+		hashLines = append(hashLines, fmt.Sprintf("%s = [%s]", d.ArtifactID, strings.Join(quoted, ", ")))
+	}
+	if len(hashLines) > 0 {
+		lines = append(lines, "", "[tool.ort-recovery.hashes]")
+		lines = append(lines, hashLines...)
+	}
+
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+}
@@ -0,0 +1,106 @@
+package pythonhandler
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ort-recovery/utils"
+)
+
+func init() {
+	RegisterManifestDriver(requirementsDriver{})
+}
+
+// requirementsDriver is the requirements.txt ManifestDriver. It's also
+// GenerateRecoveryFile's fallback when no manifest was detected at all.
+type requirementsDriver struct{}
+
+func (requirementsDriver) Name() string  { return "requirements.txt" }
+func (requirementsDriver) Priority() int { return 30 }
+
+func (requirementsDriver) Detect(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "requirements.txt"))
+	return err == nil
+}
+
+func (requirementsDriver) Parse(path string) ([]utils.Dependency, error) {
+	return ParseRequirements(path)
+}
+
+func (requirementsDriver) Write(path string, deps []utils.Dependency) error {
+	return WriteRequirements(path, deps)
+}
+
+// ---------- requirements.txt ----------
+func ParseRequirements(path string) ([]utils.Dependency, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []utils.Dependency{}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var deps []utils.Dependency
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, ver := splitReqLine(line)
+		deps = append(deps, utils.Dependency{
+			GroupID:    "pypi",
+			ArtifactID: name,
+			Version:    ver,
+			Scope:      "compile",
+			Key:        name,
+		})
+	}
+	return deps, nil
+}
+
+// WriteRequirements writes deps in pip's native format; a dependency with
+// Hashes populated (see FetchHashes) gets one "--hash=algo:hexdigest" per
+// entry, putting the file in pip's hash-checking mode for that line.
+func WriteRequirements(path string, deps []utils.Dependency) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for _, d := range deps {
+		if d.Version == "" || d.Version == "latest" {
+			// write unpinned if version empty or intentionally latest is used (user opted)
+			_, _ = f.WriteString(d.ArtifactID + "\n")
+			continue
+		}
+		line := fmt.Sprintf("%s==%s", d.ArtifactID, d.Version)
+		for _, h := range d.Hashes {
+			line += " --hash=" + h
+		}
+		_, _ = f.WriteString(line + "\n")
+	}
+	return nil
+}
+
+func splitReqLine(line string) (string, string) {
+	// support "pkg==1.2.3", "pkg>=1.2", "pkg"
+	ops := []string{"==", ">=", "<=", "!=", ">", "<", "~="}
+	for _, op := range ops {
+		if strings.Contains(line, op) {
+			parts := strings.SplitN(line, op, 2)
+			return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		}
+	}
+	// fallback: if contains "=", handle as key=value (rare)
+	if strings.Contains(line, "=") && !strings.Contains(line, "==") {
+		parts := strings.SplitN(line, "=", 2)
+		return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	}
+	return line, ""
+}
@@ -0,0 +1,86 @@
+package pythonhandler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ort-recovery/utils"
+)
+
+func init() {
+	RegisterManifestDriver(setupPyDriver{})
+}
+
+// setupPyDriver is the setup.py ManifestDriver.
+type setupPyDriver struct{}
+
+func (setupPyDriver) Name() string  { return "setup.py" }
+func (setupPyDriver) Priority() int { return 40 }
+
+func (setupPyDriver) Detect(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "setup.py"))
+	return err == nil
+}
+
+func (setupPyDriver) Parse(path string) ([]utils.Dependency, error) {
+	return ParseSetupPy(path)
+}
+
+func (setupPyDriver) Write(path string, deps []utils.Dependency) error {
+	return WriteSetupPy(path, deps)
+}
+
+// ---------- setup.py (conservative parse/write) ----------
+func ParseSetupPy(path string) ([]utils.Dependency, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []utils.Dependency{}, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content := string(b)
+	deps := []utils.Dependency{}
+	// simple heuristic: find install_requires = [ ... ]
+	idx := strings.Index(content, "install_requires")
+	if idx == -1 {
+		return deps, nil
+	}
+	blockStart := strings.Index(content[idx:], "[")
+	blockEnd := strings.Index(content[idx:], "]")
+	if blockStart == -1 || blockEnd == -1 || blockEnd <= blockStart {
+		return deps, nil
+	}
+	block := content[idx+blockStart+1 : idx+blockEnd]
+	for _, line := range strings.Split(block, ",") {
+		line = strings.TrimSpace(line)
+		line = strings.Trim(line, `"' `)
+		if line == "" {
+			continue
+		}
+		name, ver := splitReqLine(line)
+		deps = append(deps, utils.Dependency{
+			GroupID:    "pypi",
+			ArtifactID: name,
+			Version:    ver,
+			Scope:      "compile",
+			Key:        name,
+		})
+	}
+	return deps, nil
+}
+
+func WriteSetupPy(path string, deps []utils.Dependency) error {
+	lines := []string{"from setuptools import setup", "", "setup(", "    install_requires=["}
+	for _, d := range deps {
+		if d.Version == "" || d.Version == "latest" {
+			lines = append(lines, fmt.Sprintf("        \"%s\",", d.ArtifactID))
+		} else {
+			lines = append(lines, fmt.Sprintf("        \"%s==%s\",", d.ArtifactID, d.Version))
+		}
+	}
+	lines = append(lines, "    ]", ")")
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+}
@@ -1,18 +1,17 @@
 package pythonhandler
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
 
-	toml "github.com/pelletier/go-toml/v2"
-	"gopkg.in/yaml.v3"
-
+	"ort-recovery/handlers/python/pypi"
 	"ort-recovery/utils"
+	"ort-recovery/utils/policy"
+	"ort-recovery/utils/sbom"
 )
 
 // ---------------------------
@@ -22,49 +21,100 @@ type PythonHandler struct{}
 
 func (h *PythonHandler) Name() string { return "Python" }
 
-// Detect: any of the known manifests or any .py files
+// Detect: any registered ManifestDriver, or any .py files
 func (h *PythonHandler) Detect(projectDir string) bool {
-	manifests := []string{"pyproject.toml", "setup.py", "requirements.txt", "Pipfile", "Pipfile.lock", "environment.yml"}
-	for _, m := range manifests {
-		if _, err := os.Stat(filepath.Join(projectDir, m)); err == nil {
-			return true
-		}
+	if h.detectManifest(projectDir) != "" {
+		return true
 	}
 	pyMatches, _ := filepath.Glob(filepath.Join(projectDir, "*.py"))
 	return len(pyMatches) > 0
 }
 
 // Scan: parse declared, scan code for imports, merge, use syft for versions, apply curations
-func (h *PythonHandler) Scan(projectDir string) ([]utils.Dependency, error) {
-	manifest := h.detectManifest(projectDir)
+func (h *PythonHandler) Scan(projectDir string) ([]utils.Dependency, utils.ScanReport, error) {
+	scanReport := utils.NewScanReport()
+	scanLogger := utils.NewScanLogger(projectDir).With(utils.LogKeyHandler, h.Name())
+	driver := driverFor(projectDir)
 
-	// 1) Parse declared deps from the chosen manifest (if any)
+	// 1) Parse declared deps from the highest-priority matching driver, if any
 	var declared []utils.Dependency
 	var err error
-	switch manifest {
-	case "pyproject.toml":
-		declared, err = ParsePyProject(filepath.Join(projectDir, manifest))
-	case "setup.py":
-		declared, err = ParseSetupPy(filepath.Join(projectDir, manifest))
-	case "requirements.txt":
-		declared, err = ParseRequirements(filepath.Join(projectDir, manifest))
-	case "Pipfile", "Pipfile.lock":
-		// treat Pipfile.lock like Pipfile for declared dependencies (lock versions available)
-		declared, err = ParsePipfile(filepath.Join(projectDir, manifest))
-	case "environment.yml":
-		declared, err = ParseCondaEnv(filepath.Join(projectDir, manifest))
-	default:
+	var manifest string
+	if driver != nil {
+		manifest = driver.Name()
+		declared, err = driver.Parse(filepath.Join(projectDir, manifest))
+	} else {
 		declared = []utils.Dependency{}
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse manifest (%s): %v", manifest, err)
+		return nil, scanReport, fmt.Errorf("failed to parse manifest (%s): %v", manifest, err)
 	}
+	scanReport.RecordPhase("declared", len(declared))
+	scanLogger.Info("parsed manifest", utils.LogKeyFile, manifest, utils.LogKeyPhase, "declared", "deps", len(declared))
 
-	// 2) Collect imports from .py files
-	usedPkgs, err := CollectPythonImports(projectDir)
+	// 2) Collect imports from .py files. refsByRoot/conditionalByRoot carry
+	// each import root's provenance and try/except-ImportError status
+	// through the provides-registry renaming in 2a, so 4) can still log
+	// where a dependency came from and decide its Scope.
+	importRefs, err := CollectPythonImportRefs(projectDir)
+	if err != nil {
+		return nil, scanReport, fmt.Errorf("failed to scan .py files: %v", err)
+	}
+	refsByRoot := map[string]ImportRef{}
+	conditionalByRoot := map[string]bool{}
+	seenRoot := map[string]struct{}{}
+	for _, ref := range importRefs {
+		if _, ok := refsByRoot[ref.Module]; !ok {
+			refsByRoot[ref.Module] = ref
+			conditionalByRoot[ref.Module] = true
+		}
+		if !ref.Conditional {
+			conditionalByRoot[ref.Module] = false
+		}
+		seenRoot[ref.Module] = struct{}{}
+	}
+	usedPkgs := make([]string, 0, len(seenRoot))
+	for root := range seenRoot {
+		usedPkgs = append(usedPkgs, root)
+	}
+	sort.Strings(usedPkgs)
+	scanReport.RecordPhase("scanned", len(usedPkgs))
+	scanLogger.Info("scanned imports", utils.LogKeyPhase, "scanned", "deps", len(usedPkgs))
+
+	// 2a) Resolve each imported root to its actual PyPI distribution name
+	// via the provides registry, before consulting declaredMap/syftMap
+	// below -- otherwise "cv2", "PIL", "yaml" and the like would be
+	// treated as (nonexistent) PyPI distributions instead of being
+	// matched against opencv-python, Pillow, PyYAML, and so on.
+	providesMap, err := LoadProvidesMap(projectDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to scan .py files: %v", err)
+		return nil, scanReport, err
 	}
+	refsByPkg := map[string]ImportRef{}
+	conditionalByPkg := map[string]bool{}
+	for _, root := range usedPkgs {
+		pkg := root
+		if dist, ok := providesMap.Resolve(root); ok {
+			pkg = dist
+		} else if dist, ok := ResolvePyPIProvides(projectDir, root); ok {
+			pkg = dist
+		}
+		if pkg != root {
+			utils.AppendLog(projectDir, "[PythonHandler] Resolved import %q to PyPI distribution %q via provides registry", root, pkg)
+		}
+		if _, ok := refsByPkg[pkg]; !ok {
+			refsByPkg[pkg] = refsByRoot[root]
+			conditionalByPkg[pkg] = true
+		}
+		if !conditionalByRoot[root] {
+			conditionalByPkg[pkg] = false
+		}
+	}
+	usedPkgs = make([]string, 0, len(refsByPkg))
+	for pkg := range refsByPkg {
+		usedPkgs = append(usedPkgs, pkg)
+	}
+	sort.Strings(usedPkgs)
 
 	// 3) Build declared map and syft map
 	declaredMap := map[string]utils.Dependency{}
@@ -95,29 +145,76 @@ func (h *PythonHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 		if _, ok := finalMap[pkg]; ok {
 			continue // already declared
 		}
-		// resolve version: syft -> empty or "latest" depending on config
+		// resolve version: syft -> PyPI simple index -> empty (unpinned)
 		version := ""
 		if v, ok := syftMap[pkg]; ok && v != "" {
 			version = v
-		} else {
-			// fallback: leave empty (unpinned) instead of using utils.Config.NoLatestFallback
-			version = ""
+		} else if v, err := pypi.ResolveLatest(pkg, nil); err == nil && v != "" {
+			version = v
+			utils.AppendLog(projectDir, "[PythonHandler] Resolved %s to %s via PyPI simple index", pkg, v)
+		}
+
+		scope := "compile"
+		if conditionalByPkg[pkg] {
+			// Only ever imported inside a try/except ImportError (or
+			// bare except:) block -- an optional accelerator/fallback,
+			// not something the project unconditionally needs.
+			scope = "optional"
 		}
 
 		dep := utils.Dependency{
 			GroupID:    "pypi",
 			ArtifactID: pkg,
 			Version:    version,
-			Scope:      "compile",
+			Scope:      scope,
 			Key:        pkg,
 		}
 		finalMap[pkg] = dep
 
-		// log action
+		ref := refsByPkg[pkg]
+		origin := ""
+		if ref.File != "" {
+			origin = fmt.Sprintf(" (from %s:%d)", ref.File, ref.Line)
+		}
 		if version == "" {
-			utils.AppendLog(fmt.Sprintf("[PythonHandler] Added missing dependency: %s (version unknown)", pkg), "INFO")
+			utils.AppendLog(projectDir, "[PythonHandler] Added missing dependency: %s (version unknown, scope %s)%s", pkg, scope, origin)
 		} else {
-			utils.AppendLog(fmt.Sprintf("[PythonHandler] Added missing dependency: %s %s (from Syft or fallback)", pkg, version), "INFO")
+			utils.AppendLog(projectDir, "[PythonHandler] Added missing dependency: %s %s (from Syft or fallback, scope %s)%s", pkg, version, scope, origin)
+		}
+	}
+
+	// 4b) Resolve the transitive runtime closure of every final dependency,
+	// so the recovery file WriteRequirements/WritePyProject/WritePipfile
+	// produce is lockfile-quality rather than direct-deps-only. Opt out
+	// with --no-transitive for just the declared/scanned set.
+	if !NoTransitive() {
+		roots := make([]utils.Dependency, 0, len(finalMap))
+		for _, d := range finalMap {
+			roots = append(roots, d)
+		}
+		transitive, order, err := transitiveClosure(projectDir, roots)
+		if err != nil {
+			return nil, scanReport, err
+		}
+		for _, d := range transitive {
+			finalMap[d.ArtifactID] = d
+		}
+		scanReport.RecordPhase("transitive", len(transitive))
+		scanLogger.Info("resolved transitive closure", utils.LogKeyPhase, "transitive", "deps", len(transitive))
+		utils.AppendLog(projectDir, "[PythonHandler] Resolved %d transitive dependencies (install order: %s)", len(transitive), strings.Join(order, ", "))
+	}
+
+	// 4c) Fetch each final dependency's PyPI file-integrity hashes, so
+	// WriteRequirements/WritePyProject/WritePipfile can emit pinned,
+	// hash-checked output. Best-effort: a lookup failure just leaves
+	// Hashes empty rather than failing the whole scan.
+	for name, d := range finalMap {
+		if d.Version == "" {
+			continue
+		}
+		if hashes, err := FetchHashes(projectDir, d.ArtifactID, d.Version); err == nil && len(hashes) > 0 {
+			d.Hashes = hashes
+			finalMap[name] = d
 		}
 	}
 
@@ -133,26 +230,41 @@ func (h *PythonHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 	// 6) Optionally merge with syft and apply curations via utils
 	// MergeDependencies can be used if you want syft-added extras integrated:
 	// merged := utils.MergeDependencies(final, nil) // final already contains syft-derived versions
-	final, err = utils.ApplyCurations(final, "configs/master_curations.yml")
+	final, applied, err := utils.ApplyCurations(final, "configs/master_curations.yml")
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
 	}
+	scanReport.CurationsApplied = applied
 
 	// 7) If no manifest was present but we discovered imports, log creation intention
 	if manifest == "" && len(usedPkgs) > 0 {
 		utils.AppendLog(fmt.Sprintf("[PythonHandler] No manifest found; will create requirements.txt with %d deps", len(usedPkgs)), "INFO")
 	}
 
-	return final, nil
+	if err := policy.Apply(h.Name(), "pypi", projectDir, final); err != nil {
+		return nil, scanReport, err
+	}
+
+	scanReport.RecordEcosystem("pypi", len(final))
+	scanLogger.Info("scan complete", utils.LogKeyPhase, "ecosystem", utils.LogKeySource, "pypi", "deps", len(final))
+	return final, scanReport, nil
 }
 
 // GenerateRecoveryFile: backup and write the correct manifest format
 func (h *PythonHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir, backupDir string) error {
-	manifest := h.detectManifest(projectDir)
-	if manifest == "" {
-		// default to requirements.txt if none exists
-		manifest = "requirements.txt"
+	if err := VerifyPyPISignatures(projectDir, deps); err != nil {
+		utils.AppendLog(projectDir, "[PythonHandler] PyPI signature verification failed: %v", err)
+		if !AllowUnverified() {
+			return err
+		}
+	}
+
+	driver := driverFor(projectDir)
+	if driver == nil {
+		// default to requirements.txt if no manifest was detected
+		driver = requirementsDriver{}
 	}
+	manifest := driver.Name()
 
 	targetPath := filepath.Join(projectDir, manifest)
 
@@ -165,30 +277,8 @@ func (h *PythonHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir
 		utils.AppendLog(fmt.Sprintf("[PythonHandler] Backed up %s -> %s", targetPath, backupPath), "INFO")
 	}
 
-	// write in native format
-	switch manifest {
-	case "pyproject.toml":
-		if err := WritePyProject(targetPath, deps); err != nil {
-			return err
-		}
-	case "setup.py":
-		if err := WriteSetupPy(targetPath, deps); err != nil {
-			return err
-		}
-	case "requirements.txt":
-		if err := WriteRequirements(targetPath, deps); err != nil {
-			return err
-		}
-	case "Pipfile", "Pipfile.lock":
-		if err := WritePipfile(targetPath, deps); err != nil {
-			return err
-		}
-	case "environment.yml":
-		if err := WriteCondaEnv(targetPath, deps); err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("unsupported manifest: %s", manifest)
+	if err := driver.Write(targetPath, deps); err != nil {
+		return err
 	}
 
 	utils.AppendLog(fmt.Sprintf("[PythonHandler] Wrote updated %s", manifest), "INFO")
@@ -199,66 +289,29 @@ func (h *PythonHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir
 // Helpers & parsers/writers
 // ---------------------------
 
-// detectManifest: return first found manifest in priority order
+// detectManifest returns the Name() of the highest-priority registered
+// ManifestDriver whose Detect returns true for projectDir, or "" if none
+// match.
 func (h *PythonHandler) detectManifest(projectDir string) string {
-	order := []string{"pyproject.toml", "setup.py", "requirements.txt", "Pipfile", "Pipfile.lock", "environment.yml"}
-	for _, f := range order {
-		if _, err := os.Stat(filepath.Join(projectDir, f)); err == nil {
-			return f
-		}
+	if driver := driverFor(projectDir); driver != nil {
+		return driver.Name()
 	}
 	return ""
 }
 
-// CollectPythonImports scans .py files and extracts top-level package names (unique, sorted)
-// heuristically ignores stdlib
+// CollectPythonImports scans .py files and extracts top-level package names
+// (unique, sorted), heuristically ignoring stdlib. It's a thin wrapper
+// over CollectPythonImportRefs for callers that don't need per-import
+// provenance; see that function for how imports are actually parsed.
 func CollectPythonImports(projectDir string) ([]string, error) {
-	imports := map[string]struct{}{}
-
-	importRe := regexp.MustCompile(`^\s*import\s+([a-zA-Z0-9_\.]+)`)
-	fromRe := regexp.MustCompile(`^\s*from\s+([a-zA-Z0-9_\.]+)\s+import\s+`)
-
-	err := filepath.Walk(projectDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		// skip virtual env folders
-		if info.IsDir() && (strings.HasPrefix(info.Name(), "venv") || info.Name() == ".venv" || info.Name() == "env") {
-			return filepath.SkipDir
-		}
-		if info.IsDir() {
-			return nil
-		}
-		if !strings.HasSuffix(path, ".py") {
-			return nil
-		}
-		f, err := os.Open(path)
-		if err != nil {
-			return nil // ignore unreadable files
-		}
-		defer f.Close()
-
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if m := importRe.FindStringSubmatch(line); len(m) > 1 {
-				root := packageRoot(m[1])
-				if isExternalPyPackage(root) {
-					imports[root] = struct{}{}
-				}
-			}
-			if m := fromRe.FindStringSubmatch(line); len(m) > 1 {
-				root := packageRoot(m[1])
-				if isExternalPyPackage(root) {
-					imports[root] = struct{}{}
-				}
-			}
-		}
-		return nil
-	})
+	refs, err := CollectPythonImportRefs(projectDir)
 	if err != nil {
 		return nil, err
 	}
+	imports := map[string]struct{}{}
+	for _, ref := range refs {
+		imports[ref.Module] = struct{}{}
+	}
 	var list []string
 	for k := range imports {
 		list = append(list, k)
@@ -277,7 +330,7 @@ var stdlibCommon = map[string]struct{}{
 	"sys": {}, "os": {}, "re": {}, "json": {}, "math": {}, "time": {}, "logging": {}, "itertools": {},
 	"functools": {}, "typing": {}, "pathlib": {}, "subprocess": {}, "collections": {}, "concurrent": {},
 	"threading": {}, "http": {}, "email": {}, "xml": {}, "asyncio": {}, "unittest": {}, "pkgutil": {},
-	"inspect": {},
+	"inspect": {}, "importlib": {},
 }
 
 func isExternalPyPackage(name string) bool {
@@ -291,323 +344,14 @@ func isExternalPyPackage(name string) bool {
 	return true
 }
 
-// ---------- requirements.txt ----------
-func ParseRequirements(path string) ([]utils.Dependency, error) {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return []utils.Dependency{}, nil
-	}
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	var deps []utils.Dependency
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		name, ver := splitReqLine(line)
-		deps = append(deps, utils.Dependency{
-			GroupID:    "pypi",
-			ArtifactID: name,
-			Version:    ver,
-			Scope:      "compile",
-			Key:        name,
-		})
-	}
-	return deps, nil
-}
-
-func WriteRequirements(path string, deps []utils.Dependency) error {
-	f, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	for _, d := range deps {
-		if d.Version == "" || d.Version == "latest" {
-			// write unpinned if version empty or intentionally latest is used (user opted)
-			_, _ = f.WriteString(d.ArtifactID + "\n")
-		} else {
-			_, _ = f.WriteString(fmt.Sprintf("%s==%s\n", d.ArtifactID, d.Version))
-		}
-	}
-	return nil
-}
-
-func splitReqLine(line string) (string, string) {
-	// support "pkg==1.2.3", "pkg>=1.2", "pkg"
-	ops := []string{"==", ">=", "<=", "!=", ">", "<", "~="}
-	for _, op := range ops {
-		if strings.Contains(line, op) {
-			parts := strings.SplitN(line, op, 2)
-			return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
-		}
-	}
-	// fallback: if contains "=", handle as key=value (rare)
-	if strings.Contains(line, "=") && !strings.Contains(line, "==") {
-		parts := strings.SplitN(line, "=", 2)
-		return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
-	}
-	return line, ""
-}
-
-// ---------- pyproject.toml ----------
-func ParsePyProject(path string) ([]utils.Dependency, error) {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return []utils.Dependency{}, nil
-	}
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	tree := map[string]any{}
-	if err := toml.Unmarshal(data, &tree); err != nil {
-		return nil, err
-	}
-	// try PEP 621: [project] dependencies (array)
-	if project, ok := tree["project"].(map[string]any); ok {
-		if deps, ok := project["dependencies"].([]any); ok {
-			return depsFromTomlArray(deps), nil
-		}
-	}
-	// try poetry: [tool.poetry.dependencies] (table)
-	if tool, ok := tree["tool"].(map[string]any); ok {
-		if poetry, ok := tool["poetry"].(map[string]any); ok {
-			if depTable, ok := poetry["dependencies"].(map[string]any); ok {
-				return depsFromTomlTable(depTable), nil
-			}
-		}
-	}
-	return []utils.Dependency{}, nil
-}
-
-func depsFromTomlArray(arr []any) []utils.Dependency {
-	var deps []utils.Dependency
-	for _, it := range arr {
-		if s, ok := it.(string); ok {
-			name, ver := splitReqLine(s)
-			deps = append(deps, utils.Dependency{
-				GroupID:    "pypi",
-				ArtifactID: name,
-				Version:    ver,
-				Scope:      "compile",
-				Key:        name,
-			})
-		}
-	}
-	return deps
-}
-
-func depsFromTomlTable(tbl map[string]any) []utils.Dependency {
-	var deps []utils.Dependency
-	for k, v := range tbl {
-		if k == "python" {
-			continue
-		}
-		switch val := v.(type) {
-		case string:
-			name, ver := k, strings.Trim(val, `"' `)
-			deps = append(deps, utils.Dependency{
-				GroupID:    "pypi",
-				ArtifactID: name,
-				Version:    ver,
-				Scope:      "compile",
-				Key:        name,
-			})
-		case map[string]any:
-			// poetry can specify { version = "^1.0" }
-			if verRaw, ok := val["version"]; ok {
-				if vs, ok := verRaw.(string); ok {
-					deps = append(deps, utils.Dependency{
-						GroupID:    "pypi",
-						ArtifactID: k,
-						Version:    strings.Trim(vs, `"' `),
-						Scope:      "compile",
-						Key:        k,
-					})
-				}
-			}
-		}
-	}
-	return deps
-}
-
-func WritePyProject(path string, deps []utils.Dependency) error {
-	// minimal pyproject writer: [project] dependencies = [...]
-	lines := []string{"[project]", "dependencies = ["}
-	for _, d := range deps {
-		if d.Version == "" || d.Version == "latest" {
-			lines = append(lines, fmt.Sprintf("  \"%s\",", d.ArtifactID))
-		} else {
-			lines = append(lines, fmt.Sprintf("  \"%s==%s\",", d.ArtifactID, d.Version))
-		}
-	}
-	lines = append(lines, "]")
-	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
-}
-
-// ---------- setup.py (conservative parse/write) ----------
-func ParseSetupPy(path string) ([]utils.Dependency, error) {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return []utils.Dependency{}, nil
-	}
-	b, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	content := string(b)
-	deps := []utils.Dependency{}
-	// simple heuristic: find install_requires = [ ... ]
-	idx := strings.Index(content, "install_requires")
-	if idx == -1 {
-		return deps, nil
-	}
-	blockStart := strings.Index(content[idx:], "[")
-	blockEnd := strings.Index(content[idx:], "]")
-	if blockStart == -1 || blockEnd == -1 || blockEnd <= blockStart {
-		return deps, nil
-	}
-	block := content[idx+blockStart+1 : idx+blockEnd]
-	for _, line := range strings.Split(block, ",") {
-		line = strings.TrimSpace(line)
-		line = strings.Trim(line, `"' `)
-		if line == "" {
-			continue
-		}
-		name, ver := splitReqLine(line)
-		deps = append(deps, utils.Dependency{
-			GroupID:    "pypi",
-			ArtifactID: name,
-			Version:    ver,
-			Scope:      "compile",
-			Key:        name,
-		})
-	}
-	return deps, nil
-}
-
-func WriteSetupPy(path string, deps []utils.Dependency) error {
-	lines := []string{"from setuptools import setup", "", "setup(", "    install_requires=["}
-	for _, d := range deps {
-		if d.Version == "" || d.Version == "latest" {
-			lines = append(lines, fmt.Sprintf("        \"%s\",", d.ArtifactID))
-		} else {
-			lines = append(lines, fmt.Sprintf("        \"%s==%s\",", d.ArtifactID, d.Version))
-		}
-	}
-	lines = append(lines, "    ]", ")")
-	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
-}
-
-// ---------- Pipfile (TOML) ----------
-func ParsePipfile(path string) ([]utils.Dependency, error) {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return []utils.Dependency{}, nil
-	}
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	tree := map[string]any{}
-	if err := toml.Unmarshal(data, &tree); err != nil {
-		return nil, err
-	}
-	deps := []utils.Dependency{}
-	if pkgs, ok := tree["packages"].(map[string]any); ok {
-		for k, v := range pkgs {
-			switch vv := v.(type) {
-			case string:
-				deps = append(deps, utils.Dependency{
-					GroupID:    "pypi",
-					ArtifactID: k,
-					Version:    strings.Trim(vv, `"' `),
-					Scope:      "compile",
-					Key:        k,
-				})
-			case map[string]any:
-				if verRaw, ok := vv["version"]; ok {
-					if vs, ok := verRaw.(string); ok {
-						deps = append(deps, utils.Dependency{
-							GroupID:    "pypi",
-							ArtifactID: k,
-							Version:    strings.Trim(vs, `"' `),
-							Scope:      "compile",
-							Key:        k,
-						})
-					}
-				}
-			}
-		}
-	}
-	return deps, nil
-}
-
-func WritePipfile(path string, deps []utils.Dependency) error {
-	lines := []string{"[packages]"}
-	for _, d := range deps {
-		if d.Version == "" || d.Version == "latest" {
-			lines = append(lines, fmt.Sprintf("%s = \"*\"", d.ArtifactID))
-		} else {
-			lines = append(lines, fmt.Sprintf("%s = \"%s\"", d.ArtifactID, d.Version))
-		}
-	}
-	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+// EmitSBOM writes deps to w as an SBOM in the given format ("cyclonedx" or
+// "spdx"); see utils/sbom.
+func (h *PythonHandler) EmitSBOM(deps []utils.Dependency, w io.Writer, format string) error {
+	return sbom.Emit(deps, w, format)
 }
 
-// ---------- environment.yml (conda) ----------
-type CondaEnv struct {
-	Dependencies []interface{} `yaml:"dependencies"`
-}
-
-func ParseCondaEnv(path string) ([]utils.Dependency, error) {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return []utils.Dependency{}, nil
-	}
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	var env CondaEnv
-	if err := yaml.Unmarshal(data, &env); err != nil {
-		return nil, err
-	}
-	deps := []utils.Dependency{}
-	for _, it := range env.Dependencies {
-		if s, ok := it.(string); ok {
-			parts := strings.SplitN(s, "=", 2)
-			name := parts[0]
-			ver := ""
-			if len(parts) > 1 {
-				ver = parts[1]
-			}
-			deps = append(deps, utils.Dependency{
-				GroupID:    "pypi",
-				ArtifactID: name,
-				Version:    ver,
-				Scope:      "compile",
-				Key:        name,
-			})
-		}
-	}
-	return deps, nil
-}
-
-func WriteCondaEnv(path string, deps []utils.Dependency) error {
-	env := CondaEnv{Dependencies: []interface{}{}}
-	for _, d := range deps {
-		entry := d.ArtifactID
-		if d.Version != "" && d.Version != "latest" {
-			entry += "=" + d.Version
-		}
-		env.Dependencies = append(env.Dependencies, entry)
-	}
-	out, err := yaml.Marshal(&env)
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(path, out, 0644)
+// ExportSBOM writes deps to outPath as an SBOM in the given format
+// ("cyclonedx" or "spdx"); see utils/sbom.ExportToFile.
+func (h *PythonHandler) ExportSBOM(deps []utils.Dependency, format, outPath, projectDir string) error {
+	return sbom.ExportToFile(deps, format, outPath, projectDir)
 }
@@ -0,0 +1,93 @@
+package pythonhandler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// hashesCacheDir and hashesCacheFile locate the per-project file-hash
+// cache, keyed by "name@version", so repeated scans don't re-fetch a
+// release's digests PyPI has already reported (see transitiveCache/
+// pypiProvidesCache for the same convention).
+const hashesCacheDir = ".ort-recovery"
+const hashesCacheFile = "python-hashes-cache.json"
+
+type hashesCacheEntry struct {
+	Hashes []string `json:"hashes"`
+}
+
+type hashesCache struct {
+	Versions map[string]hashesCacheEntry `json:"versions"`
+}
+
+func loadHashesCache(projectDir string) *hashesCache {
+	c := &hashesCache{Versions: map[string]hashesCacheEntry{}}
+	data, err := os.ReadFile(filepath.Join(projectDir, hashesCacheDir, hashesCacheFile))
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return &hashesCache{Versions: map[string]hashesCacheEntry{}}
+	}
+	if c.Versions == nil {
+		c.Versions = map[string]hashesCacheEntry{}
+	}
+	return c
+}
+
+func (c *hashesCache) save(projectDir string) {
+	dir := filepath.Join(projectDir, hashesCacheDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(dir, hashesCacheFile), data, 0644)
+}
+
+// releaseFiles returns the distribution files (wheels and sdists) PyPI
+// published for name@version, via the same JSON API project-info
+// endpoint fetchPyPIProjectInfo already uses for ResolvePyPIProvides.
+func releaseFiles(name, version string) ([]pypiReleaseFile, error) {
+	info, err := fetchPyPIProjectInfo(name)
+	if err != nil || info == nil {
+		return nil, err
+	}
+	return info.Releases[version], nil
+}
+
+// FetchHashes returns the "algo:hexdigest" integrity hash of every
+// distribution file PyPI published for name@version (a wheel and an
+// sdist typically both have one), sorted for a stable recovery-file
+// diff. Results, including a miss, are cached on disk under
+// projectDir/.ort-recovery so a project's already-resolved releases
+// aren't re-fetched on every scan.
+func FetchHashes(projectDir, name, version string) ([]string, error) {
+	key := name + "@" + version
+	cache := loadHashesCache(projectDir)
+	if entry, ok := cache.Versions[key]; ok {
+		return entry.Hashes, nil
+	}
+	if pypiOfflineMode {
+		return nil, nil
+	}
+
+	files, err := releaseFiles(name, version)
+	if err != nil {
+		return nil, err
+	}
+	var hashes []string
+	for _, f := range files {
+		if sha, ok := f.Digests["sha256"]; ok && sha != "" {
+			hashes = append(hashes, "sha256:"+sha)
+		}
+	}
+	sort.Strings(hashes)
+	cache.Versions[key] = hashesCacheEntry{Hashes: hashes}
+	cache.save(projectDir)
+	return hashes, nil
+}
@@ -0,0 +1,52 @@
+package pythonhandler
+
+import (
+	"testing"
+)
+
+func TestFetchHashesCacheHit(t *testing.T) {
+	dir := t.TempDir()
+	cache := loadHashesCache(dir)
+	cache.Versions["cachedpkg@1.0.0"] = hashesCacheEntry{Hashes: []string{"sha256:deadbeef"}}
+	cache.save(dir)
+
+	hashes, err := FetchHashes(dir, "cachedpkg", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchHashes: %v", err)
+	}
+	if len(hashes) != 1 || hashes[0] != "sha256:deadbeef" {
+		t.Fatalf("expected cached hash to be returned, got %+v", hashes)
+	}
+}
+
+func TestFetchHashesOfflineModeMiss(t *testing.T) {
+	prev := pypiOfflineMode
+	pypiOfflineMode = true
+	defer func() { pypiOfflineMode = prev }()
+
+	dir := t.TempDir()
+	hashes, err := FetchHashes(dir, "uncached-offline-pkg", "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchHashes in offline mode should not error, got: %v", err)
+	}
+	if hashes != nil {
+		t.Fatalf("expected nil hashes for an uncached lookup in offline mode, got %+v", hashes)
+	}
+}
+
+func TestLoadHashesCacheRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	cache := loadHashesCache(dir)
+	if len(cache.Versions) != 0 {
+		t.Fatalf("expected empty cache for a fresh project dir, got %+v", cache.Versions)
+	}
+
+	cache.Versions["pkg@2.0.0"] = hashesCacheEntry{Hashes: []string{"sha256:abc123"}}
+	cache.save(dir)
+
+	reloaded := loadHashesCache(dir)
+	entry, ok := reloaded.Versions["pkg@2.0.0"]
+	if !ok || len(entry.Hashes) != 1 || entry.Hashes[0] != "sha256:abc123" {
+		t.Fatalf("expected reloaded cache to contain pkg@2.0.0, got %+v", reloaded.Versions)
+	}
+}
@@ -0,0 +1,105 @@
+package pythonhandler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PythonProvidesFileName is the conventional override file name, read
+// from projectDir/configs (mirroring cpp's cpp-header-map.yaml
+// convention) or $ORT_RECOVERY_HOME/configs.
+const PythonProvidesFileName = "python_provides.yml"
+
+// ProvidesMap resolves a top-level import name to the PyPI distribution
+// name that actually provides it, the same idea as yay's Provides config
+// key letting an AUR package satisfy a dependency under a different
+// name. Builtin entries cover well-known mismatches; user overrides (see
+// LoadProvidesMap) take precedence, so a project can redefine or extend
+// them.
+type ProvidesMap struct {
+	entries map[string]string
+}
+
+// builtinProvides maps an import's top-level package name to the PyPI
+// distribution name that installs it, for the common cases where the two
+// differ (a compiled extension's module name, a trademark-driven rename,
+// a distribution that predates PEP 503 normalization, ...).
+var builtinProvides = map[string]string{
+	"cv2":        "opencv-python",
+	"PIL":        "Pillow",
+	"sklearn":    "scikit-learn",
+	"skimage":    "scikit-image",
+	"bs4":        "beautifulsoup4",
+	"yaml":       "PyYAML",
+	"Crypto":     "pycryptodome",
+	"Cryptodome": "pycryptodomex",
+	"dateutil":   "python-dateutil",
+	"jwt":        "PyJWT",
+	"OpenSSL":    "pyOpenSSL",
+	"serial":     "pyserial",
+	"usb":        "pyusb",
+	"docx":       "python-docx",
+	"pptx":       "python-pptx",
+	"magic":      "python-magic",
+	"dotenv":     "python-dotenv",
+	"slugify":    "python-slugify",
+	"jose":       "python-jose",
+	"nacl":       "PyNaCl",
+	"zmq":        "pyzmq",
+	"markdown":   "Markdown",
+	"attr":       "attrs",
+	"dns":        "dnspython",
+	"git":        "GitPython",
+	"telebot":    "pyTelegramBotAPI",
+	"pymysql":    "PyMySQL",
+	"MySQLdb":    "mysqlclient",
+}
+
+// LoadProvidesMap builds a ProvidesMap from the built-in table plus an
+// optional configs/python_provides.yml, checked first at projectDir and
+// then at $ORT_RECOVERY_HOME. A missing override file is not an error —
+// the builtin table covers the common mismatches on its own.
+func LoadProvidesMap(projectDir string) (*ProvidesMap, error) {
+	entries := make(map[string]string, len(builtinProvides))
+	for k, v := range builtinProvides {
+		entries[k] = v
+	}
+
+	overridePath := filepath.Join(projectDir, "configs", PythonProvidesFileName)
+	if _, err := os.Stat(overridePath); os.IsNotExist(err) {
+		overridePath = ""
+		if home := os.Getenv("ORT_RECOVERY_HOME"); home != "" {
+			candidate := filepath.Join(home, "configs", PythonProvidesFileName)
+			if _, err := os.Stat(candidate); err == nil {
+				overridePath = candidate
+			}
+		}
+	}
+
+	if overridePath != "" {
+		data, err := os.ReadFile(overridePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", overridePath, err)
+		}
+		var overrides map[string]string
+		if err := yaml.Unmarshal(data, &overrides); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", overridePath, err)
+		}
+		for k, v := range overrides {
+			entries[k] = v
+		}
+	}
+
+	return &ProvidesMap{entries: entries}, nil
+}
+
+// Resolve returns the PyPI distribution name that provides importRoot,
+// or ok=false if neither the builtin table nor the project's override
+// file has an entry for it.
+func (m *ProvidesMap) Resolve(importRoot string) (string, bool) {
+	dist, ok := m.entries[importRoot]
+	return dist, ok
+}
@@ -0,0 +1,225 @@
+package pythonhandler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// pypiOfflineMode disables every network call this file makes, mirroring
+// utils/osv.go's offline mode for deterministic CI runs.
+var pypiOfflineMode = os.Getenv("ORT_RECOVERY_OFFLINE") == "1"
+
+// pypiCacheDir and pypiCacheFile locate the per-project reverse-resolution
+// cache, keyed by import root, so a project's unresolvable (or
+// already-resolved) imports aren't re-queried on every scan (see
+// utils/osv.go's OSV result cache for the same convention).
+const pypiCacheDir = ".ort-recovery"
+const pypiCacheFile = "python-provides-cache.json"
+
+// pypiHTTPClient is the client ResolvePyPIProvides issues requests
+// through; a package var so it can be swapped out in isolation.
+var pypiHTTPClient = &http.Client{Timeout: 20 * time.Second}
+
+// pypiProvidesCache maps an import root to the distribution name PyPI
+// resolved it to, or "" if the lookup was tried and came up empty.
+type pypiProvidesCache struct {
+	Resolved map[string]string `json:"resolved"`
+}
+
+func loadPyPIProvidesCache(projectDir string) *pypiProvidesCache {
+	c := &pypiProvidesCache{Resolved: map[string]string{}}
+	data, err := os.ReadFile(filepath.Join(projectDir, pypiCacheDir, pypiCacheFile))
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return &pypiProvidesCache{Resolved: map[string]string{}}
+	}
+	if c.Resolved == nil {
+		c.Resolved = map[string]string{}
+	}
+	return c
+}
+
+func (c *pypiProvidesCache) save(projectDir string) {
+	dir := filepath.Join(projectDir, pypiCacheDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(dir, pypiCacheFile), data, 0644)
+}
+
+// pypiCandidates returns the distribution names ResolvePyPIProvides tries,
+// in order, for an import root that's in neither the builtin nor the
+// user's ProvidesMap: the root itself (covers distributions CollectPython
+// Imports just hasn't matched against declaredMap/syftMap yet), then a
+// handful of conventional renames (underscores to hyphens, a "py"/
+// "python-" affix) that cover most of PyPI's remaining import/distribution
+// mismatches without resorting to a full reverse search.
+func pypiCandidates(importRoot string) []string {
+	seen := map[string]struct{}{}
+	var out []string
+	add := func(s string) {
+		if s == "" {
+			return
+		}
+		if _, ok := seen[s]; ok {
+			return
+		}
+		seen[s] = struct{}{}
+		out = append(out, s)
+	}
+	add(importRoot)
+	add(strings.ReplaceAll(importRoot, "_", "-"))
+	add("py" + importRoot)
+	add("python-" + importRoot)
+	add(importRoot + "-python")
+	return out
+}
+
+// pypiProjectInfo is the subset of PyPI's JSON API response
+// (https://pypi.org/pypi/<name>/json) ResolvePyPIProvides needs: the
+// release files for the currently-published version, so it can find a
+// wheel to pull top_level.txt from.
+type pypiProjectInfo struct {
+	Releases map[string][]pypiReleaseFile `json:"releases"`
+	Info     struct {
+		Version string `json:"version"`
+	} `json:"info"`
+}
+
+type pypiReleaseFile struct {
+	PackageType string            `json:"packagetype"`
+	URL         string            `json:"url"`
+	Filename    string            `json:"filename"`
+	Digests     map[string]string `json:"digests"`
+	HasSig      bool              `json:"has_sig"`
+}
+
+// ResolvePyPIProvides is the last-resort fallback for an import root
+// that's in neither the builtin nor user-configured ProvidesMap: it
+// tries a handful of conventional candidate distribution names (see
+// pypiCandidates) against the PyPI JSON API, downloads the first
+// candidate's current wheel, and confirms the match against the wheel's
+// top_level.txt — the same metadata file pip itself consults to know
+// what importable packages a distribution installs. Results, including
+// negative ones, are cached on disk under projectDir/.ort-recovery so a
+// project's unresolvable imports aren't re-downloaded on every scan.
+func ResolvePyPIProvides(projectDir, importRoot string) (string, bool) {
+	cache := loadPyPIProvidesCache(projectDir)
+	if dist, ok := cache.Resolved[importRoot]; ok {
+		return dist, dist != ""
+	}
+	if pypiOfflineMode {
+		return "", false
+	}
+
+	dist := resolvePyPIProvidesUncached(importRoot)
+	cache.Resolved[importRoot] = dist
+	cache.save(projectDir)
+	return dist, dist != ""
+}
+
+func resolvePyPIProvidesUncached(importRoot string) string {
+	for _, candidate := range pypiCandidates(importRoot) {
+		info, err := fetchPyPIProjectInfo(candidate)
+		if err != nil || info == nil {
+			continue
+		}
+		wheelURL := latestWheelURL(info)
+		if wheelURL == "" {
+			continue
+		}
+		topLevel, err := fetchWheelTopLevel(wheelURL)
+		if err != nil {
+			continue
+		}
+		for _, pkg := range topLevel {
+			if pkg == importRoot {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+func fetchPyPIProjectInfo(name string) (*pypiProjectInfo, error) {
+	resp, err := pypiHTTPClient.Get(fmt.Sprintf("https://pypi.org/pypi/%s/json", name))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("pypi: unexpected status %d for %s", resp.StatusCode, name)
+	}
+	var info pypiProjectInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("pypi: decoding response for %s: %v", name, err)
+	}
+	return &info, nil
+}
+
+func latestWheelURL(info *pypiProjectInfo) string {
+	for _, f := range info.Releases[info.Info.Version] {
+		if f.PackageType == "bdist_wheel" {
+			return f.URL
+		}
+	}
+	return ""
+}
+
+// fetchWheelTopLevel downloads the wheel at wheelURL and returns the
+// non-empty lines of its "*.dist-info/top_level.txt" entry.
+func fetchWheelTopLevel(wheelURL string) ([]string, error) {
+	resp, err := pypiHTTPClient.Get(wheelURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("pypi: not a zip: %v", err)
+	}
+	for _, f := range zr.File {
+		if !strings.HasSuffix(f.Name, ".dist-info/top_level.txt") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		var lines []string
+		for _, l := range strings.Split(string(content), "\n") {
+			if l = strings.TrimSpace(l); l != "" {
+				lines = append(lines, l)
+			}
+		}
+		return lines, nil
+	}
+	return nil, fmt.Errorf("pypi: no top_level.txt in wheel")
+}
@@ -0,0 +1,202 @@
+package pythonhandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/openpgp"
+
+	"ort-recovery/utils"
+)
+
+// allowUnverifiedEnabled lets GenerateRecoveryFile proceed past a failed
+// PyPI signature check instead of aborting, wired to the
+// --allow-unverified flag or the ORT_RECOVERY_ALLOW_UNVERIFIED=1 env
+// var, mirroring dotnetresolver's AllowPrerelease toggle.
+var allowUnverifiedEnabled = os.Getenv("ORT_RECOVERY_ALLOW_UNVERIFIED") == "1"
+
+// SetAllowUnverified opts GenerateRecoveryFile out of aborting when a
+// PyPI release's .asc signature fails to verify.
+func SetAllowUnverified(v bool) { allowUnverifiedEnabled = v }
+
+// AllowUnverified reports whether SetAllowUnverified has been enabled.
+func AllowUnverified() bool { return allowUnverifiedEnabled }
+
+// pypiKeyringPath is the maintainer-provided OpenPGP public keyring
+// VerifyPyPISignatures checks release signatures against, following
+// ApplyCurations' convention of a literal path relative to the current
+// working directory rather than projectDir.
+const pypiKeyringPath = "configs/pypi_keyring.gpg"
+
+// signatureCacheDir and signatureCacheFile locate the per-project
+// signature-verification cache, keyed by "name@version", so a release
+// already checked isn't re-downloaded and re-verified on every scan.
+const signatureCacheDir = ".ort-recovery"
+const signatureCacheFile = "python-signature-cache.json"
+
+// Status values a signatureCacheEntry can hold. "unsigned" and
+// "no-keyring" both mean "nothing to check" -- PyPI deprecated
+// upload-time signing in 2023, so most releases have no .asc file at
+// all, and that isn't itself suspicious. Only "failed" blocks
+// GenerateRecoveryFile.
+const (
+	sigVerified  = "verified"
+	sigFailed    = "failed"
+	sigUnsigned  = "unsigned"
+	sigNoKeyring = "no-keyring"
+)
+
+type signatureCacheEntry struct {
+	Status string `json:"status"`
+}
+
+type signatureCache struct {
+	Versions map[string]signatureCacheEntry `json:"versions"`
+}
+
+func loadSignatureCache(projectDir string) *signatureCache {
+	c := &signatureCache{Versions: map[string]signatureCacheEntry{}}
+	data, err := os.ReadFile(filepath.Join(projectDir, signatureCacheDir, signatureCacheFile))
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return &signatureCache{Versions: map[string]signatureCacheEntry{}}
+	}
+	if c.Versions == nil {
+		c.Versions = map[string]signatureCacheEntry{}
+	}
+	return c
+}
+
+func (c *signatureCache) save(projectDir string) {
+	dir := filepath.Join(projectDir, signatureCacheDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(dir, signatureCacheFile), data, 0644)
+}
+
+// VerifyPyPISignatures checks every dep's PyPI release files against
+// pypiKeyringPath's keyring, returning the first genuine verification
+// failure it finds (wrapped with %w). A release with no .asc file, or a
+// project with no keyring configured at all, isn't a failure -- there is
+// nothing to check -- so this only ever blocks on a signature that's
+// actually present and actually doesn't verify. Results are cached on
+// disk under projectDir/.ort-recovery, same as FetchHashes.
+func VerifyPyPISignatures(projectDir string, deps []utils.Dependency) error {
+	if pypiOfflineMode {
+		return nil
+	}
+
+	keyring, err := loadKeyring()
+	if err != nil {
+		return fmt.Errorf("python signature verification: loading %s: %w", pypiKeyringPath, err)
+	}
+
+	cache := loadSignatureCache(projectDir)
+	dirty := false
+	for _, d := range deps {
+		if d.Version == "" {
+			continue
+		}
+		key := d.ArtifactID + "@" + d.Version
+		entry, ok := cache.Versions[key]
+		if !ok {
+			status, err := verifyOne(keyring, d.ArtifactID, d.Version)
+			if err != nil {
+				return fmt.Errorf("python signature verification: %s %s: %w", d.ArtifactID, d.Version, err)
+			}
+			entry = signatureCacheEntry{Status: status}
+			cache.Versions[key] = entry
+			dirty = true
+		}
+		if entry.Status == sigFailed {
+			return fmt.Errorf("python signature verification: %s %s: signature does not verify against %s", d.ArtifactID, d.Version, pypiKeyringPath)
+		}
+	}
+	if dirty {
+		cache.save(projectDir)
+	}
+	return nil
+}
+
+// loadKeyring reads pypiKeyringPath, returning (nil, nil) when it's
+// absent -- a project that hasn't configured a keyring has nothing to
+// verify signatures against, which verifyOne reports as sigNoKeyring
+// rather than a failure.
+func loadKeyring() (openpgp.EntityList, error) {
+	f, err := os.Open(pypiKeyringPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return openpgp.ReadKeyRing(f)
+}
+
+// verifyOne checks name@version's release files for a detached .asc
+// signature and, if one is present and keyring is configured, verifies
+// it; it returns sigUnsigned/sigNoKeyring/sigVerified/sigFailed.
+func verifyOne(keyring openpgp.EntityList, name, version string) (string, error) {
+	files, err := releaseFiles(name, version)
+	if err != nil {
+		return "", err
+	}
+
+	signed := false
+	for _, f := range files {
+		if !f.HasSig {
+			continue
+		}
+		signed = true
+		if keyring == nil {
+			continue
+		}
+
+		data, err := fetchBytes(f.URL)
+		if err != nil {
+			return "", err
+		}
+		sig, err := fetchBytes(f.URL + ".asc")
+		if err != nil {
+			return "", err
+		}
+		if _, err := openpgp.CheckDetachedSignature(keyring, bytes.NewReader(data), bytes.NewReader(sig)); err != nil {
+			return sigFailed, nil
+		}
+	}
+
+	if keyring == nil {
+		return sigNoKeyring, nil
+	}
+	if !signed {
+		return sigUnsigned, nil
+	}
+	return sigVerified, nil
+}
+
+// fetchBytes downloads url's full body, used for both a release file and
+// its detached .asc signature.
+func fetchBytes(url string) ([]byte, error) {
+	resp, err := pypiHTTPClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("pypi: unexpected status %d for %s", resp.StatusCode, url)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,103 @@
+package pythonhandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ort-recovery/utils"
+)
+
+func TestVerifyPyPISignaturesOfflineMode(t *testing.T) {
+	prev := pypiOfflineMode
+	pypiOfflineMode = true
+	defer func() { pypiOfflineMode = prev }()
+
+	dir := t.TempDir()
+	deps := []utils.Dependency{{ArtifactID: "somepkg", Version: "1.0.0"}}
+	if err := VerifyPyPISignatures(dir, deps); err != nil {
+		t.Fatalf("VerifyPyPISignatures in offline mode should short-circuit to nil, got: %v", err)
+	}
+}
+
+func TestVerifyPyPISignaturesCachedFailure(t *testing.T) {
+	prev := pypiOfflineMode
+	pypiOfflineMode = false
+	defer func() { pypiOfflineMode = prev }()
+
+	dir := t.TempDir()
+	cache := &signatureCache{Versions: map[string]signatureCacheEntry{
+		"badpkg@1.2.3": {Status: sigFailed},
+	}}
+	cache.save(dir)
+
+	deps := []utils.Dependency{{ArtifactID: "badpkg", Version: "1.2.3"}}
+	err := VerifyPyPISignatures(dir, deps)
+	if err == nil {
+		t.Fatal("expected error for a cached failed signature, got nil")
+	}
+}
+
+func TestVerifyPyPISignaturesCachedVerified(t *testing.T) {
+	prev := pypiOfflineMode
+	pypiOfflineMode = false
+	defer func() { pypiOfflineMode = prev }()
+
+	dir := t.TempDir()
+	cache := &signatureCache{Versions: map[string]signatureCacheEntry{
+		"goodpkg@2.0.0": {Status: sigVerified},
+	}}
+	cache.save(dir)
+
+	deps := []utils.Dependency{{ArtifactID: "goodpkg", Version: "2.0.0"}}
+	if err := VerifyPyPISignatures(dir, deps); err != nil {
+		t.Fatalf("expected no error for a cached verified signature, got: %v", err)
+	}
+}
+
+func TestVerifyPyPISignaturesSkipsEmptyVersion(t *testing.T) {
+	prev := pypiOfflineMode
+	pypiOfflineMode = false
+	defer func() { pypiOfflineMode = prev }()
+
+	dir := t.TempDir()
+	deps := []utils.Dependency{{ArtifactID: "noversionpkg", Version: ""}}
+	if err := VerifyPyPISignatures(dir, deps); err != nil {
+		t.Fatalf("a dep with no version should be skipped entirely, got: %v", err)
+	}
+}
+
+func TestLoadSignatureCacheRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	cache := loadSignatureCache(dir)
+	if len(cache.Versions) != 0 {
+		t.Fatalf("expected empty cache for a fresh project dir, got %+v", cache.Versions)
+	}
+
+	cache.Versions["pkg@1.0.0"] = signatureCacheEntry{Status: sigUnsigned}
+	cache.save(dir)
+
+	if _, err := os.Stat(filepath.Join(dir, signatureCacheDir, signatureCacheFile)); err != nil {
+		t.Fatalf("expected cache file to be written: %v", err)
+	}
+
+	reloaded := loadSignatureCache(dir)
+	entry, ok := reloaded.Versions["pkg@1.0.0"]
+	if !ok || entry.Status != sigUnsigned {
+		t.Fatalf("expected reloaded cache to contain pkg@1.0.0=unsigned, got %+v", reloaded.Versions)
+	}
+}
+
+func TestAllowUnverified(t *testing.T) {
+	prev := AllowUnverified()
+	defer SetAllowUnverified(prev)
+
+	SetAllowUnverified(true)
+	if !AllowUnverified() {
+		t.Fatal("expected AllowUnverified() to report true after SetAllowUnverified(true)")
+	}
+	SetAllowUnverified(false)
+	if AllowUnverified() {
+		t.Fatal("expected AllowUnverified() to report false after SetAllowUnverified(false)")
+	}
+}
@@ -3,12 +3,15 @@ package rubyhandler
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
 	"ort-recovery/utils"
+	"ort-recovery/utils/policy"
+	"ort-recovery/utils/sbom"
 )
 
 // ---------------------------
@@ -40,62 +43,104 @@ func (h *RubyHandler) Detect(projectDir string) bool {
 }
 
 // Scan parses declared files + Syft, compares with .rb requires
-func (h *RubyHandler) Scan(projectDir string) ([]utils.Dependency, error) {
+func (h *RubyHandler) Scan(projectDir string) ([]utils.Dependency, utils.ScanReport, error) {
+	scanReport := utils.NewScanReport()
+	scanLogger := utils.NewScanLogger(projectDir).With(utils.LogKeyHandler, h.Name())
+
 	var declaredDeps []utils.Dependency
+	var unknowns []utils.Unknown
 
 	// Parse Gemfile
 	if _, err := os.Stat(filepath.Join(projectDir, "Gemfile")); err == nil {
 		utils.AppendLog(projectDir, "Parsing Gemfile...")
-		d, _ := ParseGemfile(filepath.Join(projectDir, "Gemfile"))
-		declaredDeps = append(declaredDeps, d...)
+		d, u, _ := ParseGemfile(filepath.Join(projectDir, "Gemfile"))
+		scanLogger.Info("parsed manifest", utils.LogKeyFile, "Gemfile", utils.LogKeyPhase, "declared", "deps", len(d))
+		declaredDeps = append(declaredDeps, utils.TagOrigin(d, "declared")...)
+		unknowns = append(unknowns, u...)
+		scanReport.RecordPhase("declared", len(d))
 	}
 
 	// Parse Gemfile.lock
 	if _, err := os.Stat(filepath.Join(projectDir, "Gemfile.lock")); err == nil {
 		utils.AppendLog(projectDir, "Parsing Gemfile.lock...")
-		d, _ := ParseGemfileLock(filepath.Join(projectDir, "Gemfile.lock"))
-		declaredDeps = append(declaredDeps, d...)
+		d, u, _ := ParseGemfileLock(filepath.Join(projectDir, "Gemfile.lock"))
+		scanLogger.Info("parsed lockfile", utils.LogKeyFile, "Gemfile.lock", utils.LogKeyPhase, "lockfile", "deps", len(d))
+		declaredDeps = append(declaredDeps, utils.TagOrigin(d, "lockfile")...)
+		unknowns = append(unknowns, u...)
+		scanReport.RecordPhase("lockfile", len(d))
 	}
 
 	// Parse .gemspec
 	matches, _ := filepath.Glob(filepath.Join(projectDir, "*.gemspec"))
 	for _, gemspec := range matches {
 		utils.AppendLog(projectDir, fmt.Sprintf("Parsing gemspec: %s", gemspec))
-		d, _ := ParseGemspec(gemspec)
-		declaredDeps = append(declaredDeps, d...)
+		d, u, _ := ParseGemspec(gemspec)
+		scanLogger.Info("parsed gemspec", utils.LogKeyFile, gemspec, utils.LogKeyPhase, "declared", "deps", len(d))
+		declaredDeps = append(declaredDeps, utils.TagOrigin(d, "declared")...)
+		unknowns = append(unknowns, u...)
+		scanReport.RecordPhase("declared", len(d))
 	}
 
-	// Parse Syft output
-	syftData, err := os.ReadFile(filepath.Join(projectDir, "syft.json"))
-	if err != nil {
-		utils.AppendLog(projectDir, "Failed to read syft.json")
-		return nil, fmt.Errorf("failed to read syft.json: %v", err)
-	}
-	syftDeps, err := utils.ParseSyftJSON(syftData, "ruby")
-	if err != nil {
-		utils.AppendLog(projectDir, "Failed to parse syft.json")
-		return nil, err
+	// Parse Syft output, if a SourceAdapter produced one (see main.go
+	// "-source"); native ingestion leaves no syft.json, and that's fine.
+	var syftDeps []utils.Dependency
+	if syftData, err := os.ReadFile(filepath.Join(projectDir, "syft.json")); err == nil {
+		syftDeps, err = utils.ParseSyftJSON(syftData, "ruby")
+		if err != nil {
+			utils.AppendLog(projectDir, "Failed to parse syft.json")
+			return nil, scanReport, err
+		}
+		utils.TagOrigin(syftDeps, "syft")
+		scanReport.RecordPhase("syft", len(syftDeps))
+	} else {
+		utils.AppendLog(projectDir, "No syft.json found; continuing with declared + scanned dependencies only")
 	}
 
 	// Scan .rb files for `require`
-	usedDeps, err := ScanRubyFiles(projectDir)
+	usedDeps, usedUnknowns, err := ScanRubyFiles(projectDir)
 	if err != nil {
 		utils.AppendLog(projectDir, "Failed to scan Ruby files")
-		return nil, err
+		return nil, scanReport, err
+	}
+	unknowns = append(unknowns, usedUnknowns...)
+	utils.TagOrigin(usedDeps, "scanned")
+	scanReport.RecordPhase("scanned", len(usedDeps))
+
+	if err := utils.WriteUnknowns(projectDir, unknowns); err != nil {
+		utils.AppendLog(projectDir, fmt.Sprintf("Failed to write unknowns.json: %v", err))
 	}
+	scanReport.Unknowns = len(unknowns)
+	scanLogger.Info("unknowns written", utils.LogKeyPhase, "unknowns", "count", len(unknowns))
 
 	// Merge all sources before reconciliation
 	allDeps := append(declaredDeps, usedDeps...)
 	allDeps = append(allDeps, syftDeps...)
 
 	// Reconcile with new function signature
-	finalDeps, err := utils.ReconcileDependencies(allDeps)
+	finalDeps, reconcileReport, err := utils.ReconcileDependencies(allDeps)
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
 	}
 
 	utils.AppendLog(projectDir, fmt.Sprintf("Scan complete: %d dependencies found", len(finalDeps)))
-	return finalDeps, nil
+	if len(reconcileReport.Conflicts) > 0 {
+		utils.AppendLog(projectDir, fmt.Sprintf("Reconcile resolved %d version conflicts", len(reconcileReport.Conflicts)))
+	}
+	scanReport.RecordEcosystem("rubygems", len(finalDeps))
+
+	// Enrich with known OSV vulnerabilities (opt-in, see Config.EnableOSV).
+	finalDeps, err = utils.EnrichVulnerabilities(finalDeps, "rubygems", projectDir)
+	if err != nil {
+		return nil, scanReport, err
+	}
+	scanReport.OSVHits = utils.CountOSVHits(finalDeps)
+	scanLogger.Info("osv enrichment complete", utils.LogKeyPhase, "osv", "hits", scanReport.OSVHits)
+
+	if err := policy.Apply(h.Name(), "rubygems", projectDir, finalDeps); err != nil {
+		return nil, scanReport, err
+	}
+
+	return finalDeps, scanReport, nil
 }
 
 // GenerateRecoveryFile updates Gemfile (main declaration) and backup
@@ -120,19 +165,25 @@ func (h *RubyHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir,
 // Helpers
 // ---------------------------
 
-// ParseGemfile extracts gem declarations
-func ParseGemfile(path string) ([]utils.Dependency, error) {
+// ParseGemfile extracts gem declarations. Lines that start with the `gem`
+// keyword but don't match the expected `gem "name", "version"` shape are
+// reported as Unknowns instead of being dropped silently.
+func ParseGemfile(path string) ([]utils.Dependency, []utils.Unknown, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		utils.AppendLog("", fmt.Sprintf("Failed to open Gemfile: %v", err))
-		return nil, err
+		return nil, nil, err
 	}
 	defer file.Close()
 
 	var deps []utils.Dependency
+	var unknowns []utils.Unknown
 	re := regexp.MustCompile(`gem ["']([^"']+)["'](,\s*["']([^"']+)["'])?`)
+	gemLine := regexp.MustCompile(`^\s*gem\b`)
 	scanner := bufio.NewScanner(file)
+	lineNum := 0
 	for scanner.Scan() {
+		lineNum++
 		line := strings.TrimSpace(scanner.Text())
 		if m := re.FindStringSubmatch(line); m != nil {
 			deps = append(deps, utils.Dependency{
@@ -142,60 +193,101 @@ func ParseGemfile(path string) ([]utils.Dependency, error) {
 				Scope:      "compile",
 				Key:        m[1],
 			})
+		} else if gemLine.MatchString(line) {
+			unknowns = append(unknowns, utils.Unknown{
+				File:    path,
+				Line:    lineNum,
+				Reason:  "unmatched gem declaration",
+				RawText: line,
+			})
 		}
 	}
-	return deps, scanner.Err()
+	return deps, unknowns, scanner.Err()
 }
 
-// ParseGemfileLock parses Gemfile.lock specs
-func ParseGemfileLock(path string) ([]utils.Dependency, error) {
+// ParseGemfileLock parses Gemfile.lock specs. A line inside the `specs:`
+// block that doesn't split into a "name (version)" pair is reported as an
+// Unknown.
+func ParseGemfileLock(path string) ([]utils.Dependency, []utils.Unknown, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		utils.AppendLog("", fmt.Sprintf("Failed to open Gemfile.lock: %v", err))
-		return nil, err
+		return nil, nil, err
 	}
 	defer file.Close()
 
 	var deps []utils.Dependency
+	var unknowns []utils.Unknown
 	inSpecs := false
 	scanner := bufio.NewScanner(file)
+	lineNum := 0
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+		lineNum++
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
 		if line == "specs:" {
 			inSpecs = true
 			continue
 		}
-		if inSpecs {
-			if strings.Contains(line, " ") {
-				parts := strings.Split(line, " ")
-				name := strings.TrimSpace(parts[0])
-				version := strings.Trim(parts[1], "() ")
-				deps = append(deps, utils.Dependency{
-					GroupID:    "rubygems",
-					ArtifactID: name,
-					Version:    version,
-					Scope:      "compile",
-					Key:        name,
+		if !inSpecs || line == "" {
+			continue
+		}
+		if !strings.HasPrefix(raw, "    ") {
+			// Dedented past the "specs:" block's spec entries.
+			inSpecs = false
+			continue
+		}
+		if strings.Contains(line, " ") {
+			parts := strings.Split(line, " ")
+			name := strings.TrimSpace(parts[0])
+			version := strings.Trim(parts[1], "() ")
+			if version == "" {
+				unknowns = append(unknowns, utils.Unknown{
+					File:    path,
+					Line:    lineNum,
+					Reason:  "spec entry missing version",
+					RawText: line,
 				})
+				continue
 			}
+			deps = append(deps, utils.Dependency{
+				GroupID:    "rubygems",
+				ArtifactID: name,
+				Version:    version,
+				Scope:      "compile",
+				Key:        name,
+			})
+		} else {
+			unknowns = append(unknowns, utils.Unknown{
+				File:    path,
+				Line:    lineNum,
+				Reason:  "could not split spec entry into name and version",
+				RawText: line,
+			})
 		}
 	}
-	return deps, scanner.Err()
+	return deps, unknowns, scanner.Err()
 }
 
-// ParseGemspec parses .gemspec dependencies
-func ParseGemspec(path string) ([]utils.Dependency, error) {
+// ParseGemspec parses .gemspec dependencies. A line that calls
+// `add_dependency`/`add_runtime_dependency` but doesn't match the expected
+// quoted-argument shape is reported as an Unknown.
+func ParseGemspec(path string) ([]utils.Dependency, []utils.Unknown, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		utils.AppendLog("", fmt.Sprintf("Failed to open gemspec: %v", err))
-		return nil, err
+		return nil, nil, err
 	}
 	defer file.Close()
 
 	var deps []utils.Dependency
+	var unknowns []utils.Unknown
 	re := regexp.MustCompile(`add_dependency ["']([^"']+)["'](,\s*["']([^"']+)["'])?`)
+	depLine := regexp.MustCompile(`add_(runtime_)?dependency\b`)
 	scanner := bufio.NewScanner(file)
+	lineNum := 0
 	for scanner.Scan() {
+		lineNum++
 		line := strings.TrimSpace(scanner.Text())
 		if m := re.FindStringSubmatch(line); m != nil {
 			deps = append(deps, utils.Dependency{
@@ -205,21 +297,31 @@ func ParseGemspec(path string) ([]utils.Dependency, error) {
 				Scope:      "compile",
 				Key:        m[1],
 			})
+		} else if depLine.MatchString(line) {
+			unknowns = append(unknowns, utils.Unknown{
+				File:    path,
+				Line:    lineNum,
+				Reason:  "unmatched add_dependency line",
+				RawText: line,
+			})
 		}
 	}
-	return deps, scanner.Err()
+	return deps, unknowns, scanner.Err()
 }
 
-// ScanRubyFiles finds `require "x"`
-func ScanRubyFiles(projectDir string) ([]utils.Dependency, error) {
+// ScanRubyFiles finds `require "x"`. A `require`/`require_relative` line
+// that doesn't resolve to a bareword or quoted argument is reported as an
+// Unknown rather than being skipped.
+func ScanRubyFiles(projectDir string) ([]utils.Dependency, []utils.Unknown, error) {
 	var deps []utils.Dependency
+	var unknowns []utils.Unknown
 	err := filepath.Walk(projectDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".rb") {
 			return nil
 		}
 		data, _ := os.ReadFile(path)
 		lines := strings.Split(string(data), "\n")
-		for _, line := range lines {
+		for i, line := range lines {
 			line = strings.TrimSpace(line)
 			if strings.HasPrefix(line, "require") {
 				parts := strings.Fields(line)
@@ -232,6 +334,13 @@ func ScanRubyFiles(projectDir string) ([]utils.Dependency, error) {
 						Scope:      "compile",
 						Key:        name,
 					})
+				} else {
+					unknowns = append(unknowns, utils.Unknown{
+						File:    path,
+						Line:    i + 1,
+						Reason:  "require statement missing an argument",
+						RawText: line,
+					})
 				}
 			}
 		}
@@ -240,10 +349,14 @@ func ScanRubyFiles(projectDir string) ([]utils.Dependency, error) {
 	if err != nil {
 		utils.AppendLog(projectDir, "Error scanning Ruby files")
 	}
-	return deps, err
+	return deps, unknowns, err
 }
 
-// WriteGemfile regenerates Gemfile
+// WriteGemfile regenerates Gemfile. Packages matching a "rubygems" rule
+// in configs/mirrors.yml (see utils.LoadMirrorRules) are redirected: a
+// VCS rule emits `gem "x", git: "..."`, a registry rule wraps the gem in
+// its own `source "<replacement>" do ... end` block so only that gem's
+// lookups go to the mirror.
 func WriteGemfile(path string, deps []utils.Dependency) error {
 	file, err := os.Create(path)
 	if err != nil {
@@ -252,13 +365,28 @@ func WriteGemfile(path string, deps []utils.Dependency) error {
 	}
 	defer file.Close()
 
+	rules, err := utils.LoadMirrorRules(utils.MirrorsFileName)
+	if err != nil {
+		utils.AppendLog("", fmt.Sprintf("Failed to load mirrors file: %v", err))
+		return err
+	}
+
 	for _, d := range deps {
-		version := d.Version
-		if version == "" {
+		rule, mirrored := utils.MatchMirror(rules, "rubygems", d.ArtifactID)
+		switch {
+		case mirrored && rule.VCS:
+			_, err = file.WriteString(fmt.Sprintf("gem \"%s\", git: \"%s\"\n", d.ArtifactID, rule.Replacement))
+		case mirrored:
+			if d.Version == "" {
+				_, err = file.WriteString(fmt.Sprintf("source \"%s\" do\n  gem \"%s\"\nend\n", rule.Replacement, d.ArtifactID))
+			} else {
+				_, err = file.WriteString(fmt.Sprintf("source \"%s\" do\n  gem \"%s\", \"%s\"\nend\n", rule.Replacement, d.ArtifactID, d.Version))
+			}
+		case d.Version == "":
 			// leave empty, ORT will treat as unknown
 			_, err = file.WriteString(fmt.Sprintf("gem \"%s\"\n", d.ArtifactID))
-		} else {
-			_, err = file.WriteString(fmt.Sprintf("gem \"%s\", \"%s\"\n", d.ArtifactID, version))
+		default:
+			_, err = file.WriteString(fmt.Sprintf("gem \"%s\", \"%s\"\n", d.ArtifactID, d.Version))
 		}
 		if err != nil {
 			utils.AppendLog("", fmt.Sprintf("Failed to write Gemfile entry for %s", d.ArtifactID))
@@ -268,3 +396,15 @@ func WriteGemfile(path string, deps []utils.Dependency) error {
 	utils.AppendLog("", "Gemfile written successfully")
 	return nil
 }
+
+// EmitSBOM writes deps to w as an SBOM in the given format ("cyclonedx" or
+// "spdx"); see utils/sbom.
+func (h *RubyHandler) EmitSBOM(deps []utils.Dependency, w io.Writer, format string) error {
+	return sbom.Emit(deps, w, format)
+}
+
+// ExportSBOM writes deps to outPath as an SBOM in the given format
+// ("cyclonedx" or "spdx"); see utils/sbom.ExportToFile.
+func (h *RubyHandler) ExportSBOM(deps []utils.Dependency, format, outPath, projectDir string) error {
+	return sbom.ExportToFile(deps, format, outPath, projectDir)
+}
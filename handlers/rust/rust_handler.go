@@ -1,14 +1,19 @@
 package rusthandler
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
+	toml "github.com/pelletier/go-toml/v2"
+
 	"ort-recovery/utils"
+	"ort-recovery/utils/policy"
+	"ort-recovery/utils/sbom"
 )
 
 // ---------------------------
@@ -33,56 +38,77 @@ func (h *RustHandler) Detect(projectDir string) bool {
 }
 
 // Scan parses Cargo.toml, Cargo.lock, Syft, and .rs files
-func (h *RustHandler) Scan(projectDir string) ([]utils.Dependency, error) {
+func (h *RustHandler) Scan(projectDir string) ([]utils.Dependency, utils.ScanReport, error) {
+	scanReport := utils.NewScanReport()
+	scanLogger := utils.NewScanLogger(projectDir).With(utils.LogKeyHandler, h.Name())
 	var declaredDeps []utils.Dependency
 
 	// Parse Cargo.toml
 	if _, err := os.Stat(filepath.Join(projectDir, "Cargo.toml")); err == nil {
 		d, _ := ParseCargoToml(filepath.Join(projectDir, "Cargo.toml"))
-		declaredDeps = append(declaredDeps, d...)
+		declaredDeps = append(declaredDeps, utils.TagOrigin(d, "declared")...)
 		utils.AppendLog(projectDir, "[RustHandler] Parsed Cargo.toml, found %d dependencies", len(d))
+		scanLogger.Info("parsed manifest", utils.LogKeyFile, "Cargo.toml", utils.LogKeyPhase, "declared", "deps", len(d))
 	}
 
 	// Parse Cargo.lock
 	if _, err := os.Stat(filepath.Join(projectDir, "Cargo.lock")); err == nil {
 		d, _ := ParseCargoLock(filepath.Join(projectDir, "Cargo.lock"))
-		declaredDeps = append(declaredDeps, d...)
+		declaredDeps = append(declaredDeps, utils.TagOrigin(d, "lockfile")...)
 		utils.AppendLog(projectDir, "[RustHandler] Parsed Cargo.lock, found %d dependencies", len(d))
+		scanLogger.Info("parsed lockfile", utils.LogKeyFile, "Cargo.lock", utils.LogKeyPhase, "lockfile", "deps", len(d))
 	}
 
-	// Parse Syft
-	syftData, err := os.ReadFile(filepath.Join(projectDir, "syft.json"))
-	if err != nil {
-		utils.AppendLog(projectDir, "[RustHandler] ERROR: failed to read syft.json: %v", err)
-		return nil, fmt.Errorf("failed to read syft.json: %v", err)
-	}
-	syftDeps, err := utils.ParseSyftJSON(syftData, "rust")
-	if err != nil {
-		utils.AppendLog(projectDir, "[RustHandler] ERROR: failed to parse syft.json: %v", err)
-		return nil, err
+	// Parse Syft output, if a SourceAdapter produced one (see main.go
+	// "-source"); native ingestion leaves no syft.json, and that's fine.
+	var syftDeps []utils.Dependency
+	if syftData, err := os.ReadFile(filepath.Join(projectDir, "syft.json")); err == nil {
+		syftDeps, err = utils.ParseSyftJSON(syftData, "rust")
+		if err != nil {
+			utils.AppendLog(projectDir, "[RustHandler] ERROR: failed to parse syft.json: %v", err)
+			return nil, scanReport, err
+		}
+		utils.TagOrigin(syftDeps, "syft")
+		utils.AppendLog(projectDir, "[RustHandler] Parsed syft.json, found %d dependencies", len(syftDeps))
+	} else {
+		utils.AppendLog(projectDir, "[RustHandler] No syft.json found; continuing with declared + scanned dependencies only")
 	}
-	utils.AppendLog(projectDir, "[RustHandler] Parsed syft.json, found %d dependencies", len(syftDeps))
 
 	// Scan .rs files
 	usedDeps, err := ScanRustFiles(projectDir)
 	if err != nil {
 		utils.AppendLog(projectDir, "[RustHandler] ERROR: failed to scan Rust files: %v", err)
-		return nil, err
+		return nil, scanReport, err
 	}
 	utils.AppendLog(projectDir, "[RustHandler] Scanned .rs files, found %d dependencies", len(usedDeps))
+	scanLogger.Info("scanned imports", utils.LogKeyPhase, "scanned", "deps", len(usedDeps))
+	utils.TagOrigin(usedDeps, "scanned")
 
 	// Merge all sources before reconciliation
 	allDeps := append(declaredDeps, usedDeps...)
 	allDeps = append(allDeps, syftDeps...)
 
 	// Reconcile with new function signature
-	finalDeps, err := utils.ReconcileDependencies(allDeps)
+	finalDeps, report, err := utils.ReconcileDependencies(allDeps)
 	if err != nil {
-		return nil, err
+		return nil, scanReport, err
 	}
 
 	utils.AppendLog(projectDir, "[RustHandler] Reconciled dependencies, final count: %d", len(finalDeps))
-	return finalDeps, nil
+	if len(report.Conflicts) > 0 {
+		utils.AppendLog(projectDir, "[RustHandler] Reconcile resolved %d version conflicts", len(report.Conflicts))
+	}
+
+	scanReport.RecordPhase("declared", len(declaredDeps))
+	scanReport.RecordPhase("scanned", len(usedDeps))
+
+	if err := policy.Apply(h.Name(), "crates", projectDir, finalDeps); err != nil {
+		return nil, scanReport, err
+	}
+
+	scanReport.RecordEcosystem("crates", len(finalDeps))
+	scanLogger.Info("scan complete", utils.LogKeyPhase, "ecosystem", utils.LogKeySource, "crates", "deps", len(finalDeps))
+	return finalDeps, scanReport, nil
 }
 
 // GenerateRecoveryFile updates Cargo.toml and backup
@@ -112,107 +138,222 @@ func (h *RustHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir,
 // Helpers
 // ---------------------------
 
-// ParseCargoToml reads Cargo.toml
+// cargoManifest models the subset of Cargo.toml we care about. Dependency
+// tables are decoded as map[string]any because entries are polymorphic:
+// a bare string ("1.0"), or an inline table ({ version = "1.0", features = [...] }).
+type cargoManifest struct {
+	Package struct {
+		Name string `toml:"name"`
+	} `toml:"package"`
+	Dependencies      map[string]any            `toml:"dependencies"`
+	DevDependencies   map[string]any            `toml:"dev-dependencies"`
+	BuildDependencies map[string]any            `toml:"build-dependencies"`
+	Target            map[string]cargoTargetTbl `toml:"target"`
+	Workspace         struct {
+		Dependencies map[string]any `toml:"dependencies"`
+	} `toml:"workspace"`
+}
+
+type cargoTargetTbl struct {
+	Dependencies      map[string]any `toml:"dependencies"`
+	DevDependencies   map[string]any `toml:"dev-dependencies"`
+	BuildDependencies map[string]any `toml:"build-dependencies"`
+}
+
+// ParseCargoToml reads Cargo.toml with a real TOML decoder, understanding
+// inline tables, target-specific dependency tables, and workspace
+// inheritance (`dep.workspace = true` resolved against the workspace root's
+// [workspace.dependencies]).
 func ParseCargoToml(path string) ([]utils.Dependency, error) {
-	file, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+
+	var manifest cargoManifest
+	if err := toml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid Cargo.toml %s: %v", path, err)
+	}
+
+	workspaceDeps := manifest.Workspace.Dependencies
+	if workspaceRoot := findWorkspaceRoot(path); workspaceRoot != "" {
+		if wsData, err := os.ReadFile(workspaceRoot); err == nil {
+			var wsManifest cargoManifest
+			if err := toml.Unmarshal(wsData, &wsManifest); err == nil && len(wsManifest.Workspace.Dependencies) > 0 {
+				workspaceDeps = wsManifest.Workspace.Dependencies
+			}
+		}
+	}
 
 	var deps []utils.Dependency
-	section := ""
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+	deps = append(deps, cargoDepsFromTable(manifest.Dependencies, "compile", "", workspaceDeps)...)
+	deps = append(deps, cargoDepsFromTable(manifest.DevDependencies, "test", "", workspaceDeps)...)
+	deps = append(deps, cargoDepsFromTable(manifest.BuildDependencies, "build", "", workspaceDeps)...)
+
+	// Sort target keys for deterministic output across runs.
+	targetKeys := make([]string, 0, len(manifest.Target))
+	for k := range manifest.Target {
+		targetKeys = append(targetKeys, k)
+	}
+	sort.Strings(targetKeys)
+	for _, cfgExpr := range targetKeys {
+		tgt := manifest.Target[cfgExpr]
+		deps = append(deps, cargoDepsFromTable(tgt.Dependencies, "compile", cfgExpr, workspaceDeps)...)
+		deps = append(deps, cargoDepsFromTable(tgt.DevDependencies, "test", cfgExpr, workspaceDeps)...)
+		deps = append(deps, cargoDepsFromTable(tgt.BuildDependencies, "build", cfgExpr, workspaceDeps)...)
+	}
+
+	return deps, nil
+}
+
+// cargoDepsFromTable converts one [dependencies]-style table into Dependency
+// values, resolving inline tables, renames, and workspace = true entries.
+func cargoDepsFromTable(table map[string]any, scope, target string, workspaceDeps map[string]any) []utils.Dependency {
+	names := make([]string, 0, len(table))
+	for name := range table {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var deps []utils.Dependency
+	for _, name := range names {
+		dep := cargoDepFromSpec(name, table[name], workspaceDeps)
+		dep.Scope = scope
+		dep.Target = target
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+// cargoDepFromSpec decodes a single dependency entry, which may be a bare
+// version string or an inline table with version/features/optional/package/
+// path/git/workspace keys.
+func cargoDepFromSpec(name string, spec any, workspaceDeps map[string]any) utils.Dependency {
+	dep := utils.Dependency{
+		GroupID:    "crates",
+		ArtifactID: name,
+		Key:        name,
+	}
+
+	switch v := spec.(type) {
+	case string:
+		dep.Version = v
+		dep.Source = "registry"
+		return dep
+
+	case map[string]any:
+		if ws, ok := v["workspace"].(bool); ok && ws {
+			dep.Source = "workspace"
+			if wsSpec, ok := workspaceDeps[name]; ok {
+				resolved := cargoDepFromSpec(name, wsSpec, nil)
+				dep.Version = resolved.Version
+				dep.Features = resolved.Features
+				dep.Optional = resolved.Optional
+				if resolved.Source != "" && resolved.Source != "registry" {
+					dep.Source = resolved.Source
+				}
+			}
+			return dep
 		}
 
-		if strings.HasPrefix(line, "[dependencies]") {
-			section = "compile"
-			continue
-		} else if strings.HasPrefix(line, "[dev-dependencies]") {
-			section = "test"
-			continue
-		} else if strings.HasPrefix(line, "[build-dependencies]") {
-			section = "build"
-			continue
-		} else if strings.HasPrefix(line, "[") {
-			section = ""
+		if ver, ok := v["version"].(string); ok {
+			dep.Version = ver
+		}
+		if feats, ok := v["features"].([]any); ok {
+			for _, f := range feats {
+				if fs, ok := f.(string); ok {
+					dep.Features = append(dep.Features, fs)
+				}
+			}
+		}
+		if opt, ok := v["optional"].(bool); ok {
+			dep.Optional = opt
+		}
+		if pkg, ok := v["package"].(string); ok && pkg != "" {
+			dep.RenamedFrom = pkg
+			dep.ArtifactID = pkg
+			dep.Key = pkg
 		}
+		switch {
+		case v["git"] != nil:
+			dep.Source = "git"
+		case v["path"] != nil:
+			dep.Source = "path"
+		default:
+			dep.Source = "registry"
+		}
+		return dep
+
+	default:
+		return dep
+	}
+}
 
-		if section != "" && strings.Contains(line, "=") {
-			parts := strings.Split(line, "=")
-			if len(parts) == 2 {
-				name := strings.TrimSpace(parts[0])
-				version := strings.Trim(strings.TrimSpace(parts[1]), `"`)
-				deps = append(deps, utils.Dependency{
-					GroupID:    "crates",
-					ArtifactID: name,
-					Version:    version,
-					Scope:      section,
-					Key:        name,
-				})
+// findWorkspaceRoot walks parent directories looking for a Cargo.toml
+// declaring a [workspace] table, returning its path (or "" if none found).
+func findWorkspaceRoot(cargoTomlPath string) string {
+	dir := filepath.Dir(filepath.Dir(cargoTomlPath)) // start above the crate's own dir
+	for {
+		candidate := filepath.Join(dir, "Cargo.toml")
+		if candidate != cargoTomlPath {
+			if data, err := os.ReadFile(candidate); err == nil {
+				var m cargoManifest
+				if err := toml.Unmarshal(data, &m); err == nil && len(m.Workspace.Dependencies) > 0 {
+					return candidate
+				}
 			}
 		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
 	}
+}
 
-	return deps, scanner.Err()
+// cargoLockFile mirrors the [[package]] blocks Cargo writes into Cargo.lock.
+type cargoLockFile struct {
+	Package []struct {
+		Name    string `toml:"name"`
+		Version string `toml:"version"`
+		Source  string `toml:"source"`
+	} `toml:"package"`
 }
 
-// ParseCargoLock reads Cargo.lock for resolved versions
+// ParseCargoLock reads Cargo.lock for resolved versions using a TOML decoder
+// rather than string prefix matching.
 func ParseCargoLock(path string) ([]utils.Dependency, error) {
-	file, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+
+	var lock cargoLockFile
+	if err := toml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("invalid Cargo.lock %s: %v", path, err)
+	}
 
 	var deps []utils.Dependency
-	inPkg := false
-	var name, version string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		if line == "[[package]]" {
-			if name != "" {
-				deps = append(deps, utils.Dependency{
-					GroupID:    "crates",
-					ArtifactID: name,
-					Version:    version,
-					Scope:      "compile",
-					Key:        name,
-				})
-			}
-			inPkg = true
-			name, version = "", ""
+	for _, pkg := range lock.Package {
+		if pkg.Name == "" {
 			continue
 		}
-
-		if inPkg {
-			if strings.HasPrefix(line, "name =") {
-				name = strings.Trim(strings.Split(line, "=")[1], ` "`)
-			}
-			if strings.HasPrefix(line, "version =") {
-				version = strings.Trim(strings.Split(line, "=")[1], ` "`)
-			}
+		source := "registry"
+		if strings.HasPrefix(pkg.Source, "git+") {
+			source = "git"
+		} else if pkg.Source == "" {
+			source = "path"
 		}
-	}
-
-	// Append last package
-	if name != "" {
 		deps = append(deps, utils.Dependency{
 			GroupID:    "crates",
-			ArtifactID: name,
-			Version:    version,
+			ArtifactID: pkg.Name,
+			Version:    pkg.Version,
 			Scope:      "compile",
-			Key:        name,
+			Key:        pkg.Name,
+			Source:     source,
 		})
 	}
-
-	return deps, scanner.Err()
+	return deps, nil
 }
 
 // ScanRustFiles extracts extern crate / use statements
@@ -258,36 +399,95 @@ func ScanRustFiles(projectDir string) ([]utils.Dependency, error) {
 	return deps, err
 }
 
-// WriteCargoToml regenerates Cargo.toml
+// WriteCargoToml regenerates Cargo.toml, round-tripping inline tables
+// (features/optional/rename) and target-specific dependency sections
+// instead of collapsing everything into a flat [dependencies] block.
 func WriteCargoToml(path string, deps []utils.Dependency) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return err
+	var b strings.Builder
+	b.WriteString("[package]\nname = \"generated\"\nversion = \"0.1.0\"\nedition = \"2021\"\n\n")
+
+	byTarget := map[string][]utils.Dependency{}
+	for _, d := range deps {
+		byTarget[d.Target] = append(byTarget[d.Target], d)
 	}
-	defer file.Close()
 
-	_, err = file.WriteString("[package]\nname = \"generated\"\nversion = \"0.1.0\"\nedition = \"2021\"\n\n")
-	if err != nil {
-		return err
+	writeSection := func(header string, section []utils.Dependency, scope string) {
+		var lines []string
+		for _, d := range section {
+			if d.Scope != scope {
+				continue
+			}
+			lines = append(lines, cargoDepLine(d))
+		}
+		if len(lines) == 0 {
+			return
+		}
+		b.WriteString(header + "\n")
+		for _, l := range lines {
+			b.WriteString(l + "\n")
+		}
+		b.WriteString("\n")
 	}
 
-	_, err = file.WriteString("[dependencies]\n")
-	if err != nil {
-		return err
+	writeSection("[dependencies]", byTarget[""], "compile")
+	writeSection("[dev-dependencies]", byTarget[""], "test")
+	writeSection("[build-dependencies]", byTarget[""], "build")
+
+	targets := make([]string, 0, len(byTarget))
+	for t := range byTarget {
+		if t != "" {
+			targets = append(targets, t)
+		}
+	}
+	sort.Strings(targets)
+	for _, t := range targets {
+		writeSection(fmt.Sprintf("[target.'%s'.dependencies]", t), byTarget[t], "compile")
+		writeSection(fmt.Sprintf("[target.'%s'.dev-dependencies]", t), byTarget[t], "test")
+		writeSection(fmt.Sprintf("[target.'%s'.build-dependencies]", t), byTarget[t], "build")
 	}
 
-	for _, d := range deps {
-		if d.Scope == "compile" {
-			if d.Version == "" {
-				_, err = file.WriteString(fmt.Sprintf("%s = \"*\"\n", d.ArtifactID))
-			} else {
-				_, err = file.WriteString(fmt.Sprintf("%s = \"%s\"\n", d.ArtifactID, d.Version))
-			}
-			if err != nil {
-				return err
-			}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// cargoDepLine renders one dependency as a bare version string, or an
+// inline table when it carries features, an optional flag, or a rename.
+func cargoDepLine(d utils.Dependency) string {
+	name := d.ArtifactID
+	version := d.Version
+	if version == "" {
+		version = "*"
+	}
+
+	if len(d.Features) == 0 && !d.Optional && d.RenamedFrom == "" {
+		return fmt.Sprintf("%s = \"%s\"", name, version)
+	}
+
+	var attrs []string
+	attrs = append(attrs, fmt.Sprintf("version = \"%s\"", version))
+	if d.RenamedFrom != "" {
+		attrs = append(attrs, fmt.Sprintf("package = \"%s\"", d.RenamedFrom))
+	}
+	if len(d.Features) > 0 {
+		quoted := make([]string, len(d.Features))
+		for i, f := range d.Features {
+			quoted[i] = fmt.Sprintf("\"%s\"", f)
 		}
+		attrs = append(attrs, fmt.Sprintf("features = [%s]", strings.Join(quoted, ", ")))
 	}
+	if d.Optional {
+		attrs = append(attrs, "optional = true")
+	}
+	return fmt.Sprintf("%s = { %s }", name, strings.Join(attrs, ", "))
+}
 
-	return nil
+// EmitSBOM writes deps to w as an SBOM in the given format ("cyclonedx" or
+// "spdx"); see utils/sbom.
+func (h *RustHandler) EmitSBOM(deps []utils.Dependency, w io.Writer, format string) error {
+	return sbom.Emit(deps, w, format)
+}
+
+// ExportSBOM writes deps to outPath as an SBOM in the given format
+// ("cyclonedx" or "spdx"); see utils/sbom.ExportToFile.
+func (h *RustHandler) ExportSBOM(deps []utils.Dependency, format, outPath, projectDir string) error {
+	return sbom.ExportToFile(deps, format, outPath, projectDir)
 }
@@ -0,0 +1,124 @@
+package rusthandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCargoToml(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `
+[package]
+name = "demo"
+version = "0.1.0"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"], optional = true }
+log = "0.4"
+renamed = { package = "actual-crate", version = "2.0" }
+gitdep = { git = "https://example.com/gitdep.git" }
+
+[dev-dependencies]
+criterion = "0.5"
+
+[target.'cfg(unix)'.dependencies]
+libc = "0.2"
+`
+	path := filepath.Join(dir, "Cargo.toml")
+	if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	deps, err := ParseCargoToml(path)
+	if err != nil {
+		t.Fatalf("ParseCargoToml: %v", err)
+	}
+
+	byKey := make(map[string]int)
+	for i, d := range deps {
+		byKey[d.ArtifactID] = i
+	}
+
+	serde := deps[byKey["serde"]]
+	if serde.Version != "1.0" || !serde.Optional || len(serde.Features) != 1 || serde.Features[0] != "derive" {
+		t.Errorf("serde dep parsed wrong: %+v", serde)
+	}
+	if serde.Source != "registry" {
+		t.Errorf("serde source = %q, want registry", serde.Source)
+	}
+
+	renamed, ok := byKey["actual-crate"]
+	if !ok {
+		t.Fatalf("expected renamed dep keyed under actual-crate, got: %+v", byKey)
+	}
+	if deps[renamed].RenamedFrom != "actual-crate" || deps[renamed].Version != "2.0" {
+		t.Errorf("renamed dep parsed wrong: %+v", deps[renamed])
+	}
+
+	gitdep := deps[byKey["gitdep"]]
+	if gitdep.Source != "git" {
+		t.Errorf("gitdep source = %q, want git", gitdep.Source)
+	}
+
+	criterion := deps[byKey["criterion"]]
+	if criterion.Scope != "test" {
+		t.Errorf("criterion scope = %q, want test", criterion.Scope)
+	}
+
+	libc := deps[byKey["libc"]]
+	if libc.Target != "cfg(unix)" {
+		t.Errorf("libc target = %q, want cfg(unix)", libc.Target)
+	}
+}
+
+func TestParseCargoTomlWorkspaceInheritance(t *testing.T) {
+	dir := t.TempDir()
+	wsDir := filepath.Join(dir, "ws")
+	crateDir := filepath.Join(wsDir, "crate-a")
+	if err := os.MkdirAll(crateDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	wsManifest := `
+[workspace]
+members = ["crate-a"]
+
+[workspace.dependencies]
+tokio = { version = "1.38", features = ["rt"] }
+`
+	if err := os.WriteFile(filepath.Join(wsDir, "Cargo.toml"), []byte(wsManifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	crateManifest := `
+[package]
+name = "crate-a"
+version = "0.1.0"
+
+[dependencies]
+tokio = { workspace = true }
+`
+	cratePath := filepath.Join(crateDir, "Cargo.toml")
+	if err := os.WriteFile(cratePath, []byte(crateManifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	deps, err := ParseCargoToml(cratePath)
+	if err != nil {
+		t.Fatalf("ParseCargoToml: %v", err)
+	}
+	if len(deps) != 1 {
+		t.Fatalf("expected 1 dep, got %d: %+v", len(deps), deps)
+	}
+	tokio := deps[0]
+	if tokio.Source != "workspace" {
+		t.Errorf("tokio source = %q, want workspace", tokio.Source)
+	}
+	if tokio.Version != "1.38" {
+		t.Errorf("tokio version = %q, want 1.38 (inherited from workspace)", tokio.Version)
+	}
+	if len(tokio.Features) != 1 || tokio.Features[0] != "rt" {
+		t.Errorf("tokio features not inherited: %+v", tokio.Features)
+	}
+}
@@ -3,12 +3,15 @@ package swifthandler
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
 	"ort-recovery/utils"
+	"ort-recovery/utils/policy"
+	"ort-recovery/utils/sbom"
 )
 
 // ---------------------------
@@ -34,20 +37,22 @@ func (h *SwiftHandler) Detect(projectDir string) bool {
 }
 
 // Scan parses Swift dependency files + Syft + source
-func (h *SwiftHandler) Scan(projectDir string) ([]utils.Dependency, error) {
+func (h *SwiftHandler) Scan(projectDir string) ([]utils.Dependency, utils.ScanReport, error) {
+	scanReport := utils.NewScanReport()
+	scanLogger := utils.NewScanLogger(projectDir).With(utils.LogKeyHandler, h.Name())
 	var declaredDeps []utils.Dependency
 
 	// Parse Package.swift
 	if _, err := os.Stat(filepath.Join(projectDir, "Package.swift")); err == nil {
 		d, _ := ParsePackageSwift(filepath.Join(projectDir, "Package.swift"))
-		declaredDeps = append(declaredDeps, d...)
+		declaredDeps = append(declaredDeps, utils.TagOrigin(d, "declared")...)
 		utils.AppendLog(projectDir, "[SwiftHandler] Parsed Package.swift, found %d dependencies", len(d))
 	}
 
 	// Parse Package.resolved
 	if _, err := os.Stat(filepath.Join(projectDir, "Package.resolved")); err == nil {
 		d, _ := ParsePackageResolved(filepath.Join(projectDir, "Package.resolved"))
-		declaredDeps = append(declaredDeps, d...)
+		declaredDeps = append(declaredDeps, utils.TagOrigin(d, "lockfile")...)
 		utils.AppendLog(projectDir, "[SwiftHandler] Parsed Package.resolved, found %d dependencies", len(d))
 	}
 
@@ -55,47 +60,64 @@ func (h *SwiftHandler) Scan(projectDir string) ([]utils.Dependency, error) {
 	files, _ := filepath.Glob(filepath.Join(projectDir, "*.podspec"))
 	for _, f := range files {
 		d, _ := ParsePodspec(f)
-		declaredDeps = append(declaredDeps, d...)
+		declaredDeps = append(declaredDeps, utils.TagOrigin(d, "declared")...)
 		utils.AppendLog(projectDir, "[SwiftHandler] Parsed %s, found %d dependencies", filepath.Base(f), len(d))
 	}
 
 	// Parse Cartfile
 	if _, err := os.Stat(filepath.Join(projectDir, "Cartfile")); err == nil {
 		d, _ := ParseCartfile(filepath.Join(projectDir, "Cartfile"))
-		declaredDeps = append(declaredDeps, d...)
+		declaredDeps = append(declaredDeps, utils.TagOrigin(d, "declared")...)
 		utils.AppendLog(projectDir, "[SwiftHandler] Parsed Cartfile, found %d dependencies", len(d))
 	}
 
-	// Parse Syft
-	syftData, err := os.ReadFile(filepath.Join(projectDir, "syft.json"))
-	if err != nil {
-		utils.AppendLog(projectDir, "[SwiftHandler] ERROR: failed to read syft.json: %v", err)
-		return nil, fmt.Errorf("failed to read syft.json: %v", err)
-	}
-	syftDeps, err := utils.ParseSyftJSON(syftData, "swift")
-	if err != nil {
-		utils.AppendLog(projectDir, "[SwiftHandler] ERROR: failed to parse syft.json: %v", err)
-		return nil, err
+	// Parse Syft output, if a SourceAdapter produced one (see main.go
+	// "-source"); native ingestion leaves no syft.json, and that's fine.
+	var syftDeps []utils.Dependency
+	if syftData, err := os.ReadFile(filepath.Join(projectDir, "syft.json")); err == nil {
+		syftDeps, err = utils.ParseSyftJSON(syftData, "swift")
+		if err != nil {
+			utils.AppendLog(projectDir, "[SwiftHandler] ERROR: failed to parse syft.json: %v", err)
+			return nil, scanReport, err
+		}
+		utils.TagOrigin(syftDeps, "syft")
+		utils.AppendLog(projectDir, "[SwiftHandler] Parsed syft.json, found %d dependencies", len(syftDeps))
+	} else {
+		utils.AppendLog(projectDir, "[SwiftHandler] No syft.json found; continuing with declared + scanned dependencies only")
 	}
-	utils.AppendLog(projectDir, "[SwiftHandler] Parsed syft.json, found %d dependencies", len(syftDeps))
 
 	// Scan .swift files
 	usedDeps, err := ScanSwiftFiles(projectDir)
 	if err != nil {
 		utils.AppendLog(projectDir, "[SwiftHandler] ERROR: failed to scan Swift files: %v", err)
-		return nil, err
+		return nil, scanReport, err
 	}
 	utils.AppendLog(projectDir, "[SwiftHandler] Scanned .swift files, found %d dependencies", len(usedDeps))
+	utils.TagOrigin(usedDeps, "scanned")
 
 	// Reconcile declared + used + syft
 	combined := append(append(declaredDeps, usedDeps...), syftDeps...)
-	finalDeps, err := utils.ReconcileDependencies(combined)
+	finalDeps, report, err := utils.ReconcileDependencies(combined)
 	if err != nil {
 		utils.AppendLog(projectDir, "[SwiftHandler] ERROR: failed to reconcile dependencies: %v", err)
-		return nil, err
+		return nil, scanReport, err
+	}
+	if len(report.Conflicts) > 0 {
+		utils.AppendLog(projectDir, "[SwiftHandler] Reconcile resolved %d version conflicts", len(report.Conflicts))
 	}
 
-	return finalDeps, nil
+	scanReport.RecordPhase("declared", len(declaredDeps))
+	scanLogger.Info("parsed manifest", utils.LogKeyFile, "Package.swift", utils.LogKeyPhase, "declared", "deps", len(declaredDeps))
+	scanReport.RecordPhase("scanned", len(usedDeps))
+	scanLogger.Info("scanned imports", utils.LogKeyPhase, "scanned", "deps", len(usedDeps))
+
+	if err := policy.Apply(h.Name(), "swift", projectDir, finalDeps); err != nil {
+		return nil, scanReport, err
+	}
+
+	scanReport.RecordEcosystem("swift", len(finalDeps))
+	scanLogger.Info("scan complete", utils.LogKeyPhase, "ecosystem", utils.LogKeySource, "swift", "deps", len(finalDeps))
+	return finalDeps, scanReport, nil
 }
 
 // GenerateRecoveryFile writes Package.swift and backup
@@ -125,32 +147,357 @@ func (h *SwiftHandler) GenerateRecoveryFile(deps []utils.Dependency, projectDir,
 // Helpers
 // ---------------------------
 
-// ParsePackageSwift
+// ParsePackageSwift parses the manifest's top-level Package(...) call with a
+// small tokenizer instead of a single-line regex, so it handles multi-line
+// `.package(...)` declarations, every PackageDescription requirement form
+// (from/upToNextMajor/upToNextMinor/exact/branch/revision/range), and local
+// path dependencies. Target scopes (compile vs test) are then resolved by
+// walking `targets: [...]` and matching `.product(name:package:)` edges (and
+// bare product-name strings) back to the packages declared above.
 func ParsePackageSwift(path string) ([]utils.Dependency, error) {
-	file, err := os.Open(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	src := stripSwiftComments(string(data))
+
+	pkgCallIdx := strings.Index(src, "Package(")
+	if pkgCallIdx < 0 {
+		return nil, fmt.Errorf("no Package(...) declaration found in %s", path)
+	}
+	openParen := pkgCallIdx + len("Package(") - 1
+	pkgBody, _ := extractBalanced(src, openParen, '(', ')')
+
+	deps := parsePackageDependencies(pkgBody)
+	scopes := parseTargetScopes(pkgBody)
+	for i := range deps {
+		if scope, ok := scopes[deps[i].ArtifactID]; ok {
+			deps[i].Scope = scope
+		}
+	}
+	return deps, nil
+}
+
+// stripSwiftComments removes "//" and "/* */" comments, leaving string
+// literals (where "//" or "/*" could legitimately appear, e.g. in a URL)
+// untouched.
+func stripSwiftComments(src string) string {
+	var b strings.Builder
+	inString := false
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if inString {
+			b.WriteByte(c)
+			if c == '\\' && i+1 < len(src) {
+				i++
+				b.WriteByte(src[i])
+				continue
+			}
+			if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		if c == '"' {
+			inString = true
+			b.WriteByte(c)
+			continue
+		}
+		if c == '/' && i+1 < len(src) && src[i+1] == '/' {
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			if i < len(src) {
+				b.WriteByte('\n')
+			}
+			continue
+		}
+		if c == '/' && i+1 < len(src) && src[i+1] == '*' {
+			i += 2
+			for i+1 < len(src) && !(src[i] == '*' && src[i+1] == '/') {
+				i++
+			}
+			i++
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// extractBalanced returns the text strictly between the matching open/close
+// pair starting at src[openIdx] (which must hold an open byte), plus the
+// index just past the matching close byte. String literals are skipped so
+// brackets inside them don't confuse the depth count.
+func extractBalanced(src string, openIdx int, open, close byte) (string, int) {
+	depth := 0
+	inString := false
+	for i := openIdx; i < len(src); i++ {
+		c := src[i]
+		if inString {
+			if c == '\\' {
+				i++
+				continue
+			}
+			if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return src[openIdx+1 : i], i + 1
+			}
+		}
+	}
+	return src[openIdx+1:], len(src)
+}
+
+// splitTopLevel splits content on commas that sit at bracket depth 0,
+// so a nested `.upToNextMajor(from: "1,2,3")`-style argument (or any
+// parenthesized/bracketed sub-expression) doesn't get split apart.
+func splitTopLevel(content string) []string {
+	var parts []string
+	depth := 0
+	inString := false
+	last := 0
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if inString {
+			if c == '\\' {
+				i++
+				continue
+			}
+			if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, content[last:i])
+				last = i + 1
+			}
+		}
+	}
+	if strings.TrimSpace(content[last:]) != "" {
+		parts = append(parts, content[last:])
+	}
+	return parts
+}
+
+// findKeywordAtDepth0 returns the index of the first occurrence of keyword
+// that sits at bracket depth 0 relative to the start of content, so e.g. the
+// package-level "dependencies:" key isn't confused with the "dependencies:"
+// key nested inside a later targets: [...] entry.
+func findKeywordAtDepth0(content, keyword string) int {
+	depth := 0
+	inString := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if inString {
+			if c == '\\' {
+				i++
+				continue
+			}
+			if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		}
+		if depth == 0 && strings.HasPrefix(content[i:], keyword) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parsePackageDependencies extracts the package-level dependencies: [...]
+// array out of a Package(...) call body and parses each .package(...) entry.
+func parsePackageDependencies(pkgBody string) []utils.Dependency {
+	depsIdx := findKeywordAtDepth0(pkgBody, "dependencies:")
+	if depsIdx < 0 {
+		return nil
+	}
+	bracketIdx := strings.Index(pkgBody[depsIdx:], "[")
+	if bracketIdx < 0 {
+		return nil
+	}
+	arrayBody, _ := extractBalanced(pkgBody, depsIdx+bracketIdx, '[', ']')
 
 	var deps []utils.Dependency
-	scanner := bufio.NewScanner(file)
-	re := regexp.MustCompile(`\.package\s*\(\s*url:\s*\"([^\"]+)\".*from:\s*\"([^\"]+)\"`)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if m := re.FindStringSubmatch(line); len(m) == 3 {
-			url, version := m[1], m[2]
-			name := filepath.Base(strings.TrimSuffix(url, ".git"))
-			deps = append(deps, utils.Dependency{
-				GroupID:    "swiftpm",
-				ArtifactID: name,
-				Version:    version,
-				Scope:      "compile",
-				Key:        name,
-			})
+	for _, entry := range splitTopLevel(arrayBody) {
+		if dep, ok := parsePackageEntry(strings.TrimSpace(entry)); ok {
+			deps = append(deps, dep)
 		}
 	}
-	return deps, scanner.Err()
+	return deps
+}
+
+var (
+	packageURLRe  = regexp.MustCompile(`url:\s*"([^"]+)"`)
+	packagePathRe = regexp.MustCompile(`path:\s*"([^"]+)"`)
+	fromRe        = regexp.MustCompile(`(?:^|[^.\w])from:\s*"([^"]+)"`)
+	upToMajorRe   = regexp.MustCompile(`\.upToNextMajor\s*\(\s*from:\s*"([^"]+)"\s*\)`)
+	upToMinorRe   = regexp.MustCompile(`\.upToNextMinor\s*\(\s*from:\s*"([^"]+)"\s*\)`)
+	exactRe       = regexp.MustCompile(`\.exact\s*\(\s*"([^"]+)"\s*\)`)
+	branchRe      = regexp.MustCompile(`\.branch\s*\(\s*"([^"]+)"\s*\)`)
+	revisionRe    = regexp.MustCompile(`\.revision\s*\(\s*"([^"]+)"\s*\)`)
+	rangeRe       = regexp.MustCompile(`"([^"]+)"\s*\.\.[<.]\s*"([^"]+)"`)
+	productRe     = regexp.MustCompile(`\.product\s*\(\s*name:\s*"([^"]+)"\s*,\s*package:\s*"([^"]+)"\s*\)`)
+)
+
+// parsePackageEntry parses a single ".package(...)" call (already isolated
+// by splitTopLevel) into a Dependency, recognizing every PackageDescription
+// requirement form plus local path: dependencies.
+func parsePackageEntry(entry string) (utils.Dependency, bool) {
+	if !strings.HasPrefix(entry, ".package") {
+		return utils.Dependency{}, false
+	}
+
+	if m := packageURLRe.FindStringSubmatch(entry); m != nil {
+		url := m[1]
+		name := filepath.Base(strings.TrimSuffix(url, ".git"))
+		dep := utils.Dependency{
+			GroupID:    "swiftpm",
+			ArtifactID: name,
+			ImportPath: url,
+			Scope:      "compile",
+			Key:        name,
+		}
+		switch {
+		case upToMajorRe.MatchString(entry):
+			m := upToMajorRe.FindStringSubmatch(entry)
+			dep.Constraint, dep.Version = "upToNextMajor", m[1]
+		case upToMinorRe.MatchString(entry):
+			m := upToMinorRe.FindStringSubmatch(entry)
+			dep.Constraint, dep.Version = "upToNextMinor", m[1]
+		case exactRe.MatchString(entry):
+			m := exactRe.FindStringSubmatch(entry)
+			dep.Constraint, dep.Version = "exact", m[1]
+		case branchRe.MatchString(entry):
+			m := branchRe.FindStringSubmatch(entry)
+			dep.Constraint, dep.Version = "branch", m[1]
+		case revisionRe.MatchString(entry):
+			m := revisionRe.FindStringSubmatch(entry)
+			dep.Constraint, dep.Version = "revision", m[1]
+		case rangeRe.MatchString(entry):
+			m := rangeRe.FindStringSubmatch(entry)
+			dep.Constraint, dep.Version = "range", m[1]+"..<"+m[2]
+		case fromRe.MatchString(entry):
+			m := fromRe.FindStringSubmatch(entry)
+			dep.Constraint, dep.Version = "from", m[1]
+		}
+		return dep, true
+	}
+
+	if m := packagePathRe.FindStringSubmatch(entry); m != nil {
+		localPath := m[1]
+		name := filepath.Base(localPath)
+		return utils.Dependency{
+			GroupID:    "swiftpm",
+			ArtifactID: name,
+			ImportPath: localPath,
+			Source:     "path",
+			Scope:      "compile",
+			Key:        name,
+		}, true
+	}
+
+	return utils.Dependency{}, false
+}
+
+// parseTargetScopes walks targets: [.target(...), .testTarget(...)] and
+// returns, for each referenced package name, "test" if it's only ever
+// required by test targets or "compile" otherwise.
+func parseTargetScopes(pkgBody string) map[string]string {
+	scopes := make(map[string]string)
+
+	targetsIdx := findKeywordAtDepth0(pkgBody, "targets:")
+	if targetsIdx < 0 {
+		return scopes
+	}
+	bracketIdx := strings.Index(pkgBody[targetsIdx:], "[")
+	if bracketIdx < 0 {
+		return scopes
+	}
+	targetsBody, _ := extractBalanced(pkgBody, targetsIdx+bracketIdx, '[', ']')
+
+	for _, entry := range splitTopLevel(targetsBody) {
+		entry = strings.TrimSpace(entry)
+		isTest := strings.HasPrefix(entry, ".testTarget")
+		if !isTest && !strings.HasPrefix(entry, ".target") {
+			continue
+		}
+		scope := "compile"
+		if isTest {
+			scope = "test"
+		}
+
+		openParen := strings.Index(entry, "(")
+		if openParen < 0 {
+			continue
+		}
+		targetBody, _ := extractBalanced(entry, openParen, '(', ')')
+
+		depsIdx := findKeywordAtDepth0(targetBody, "dependencies:")
+		if depsIdx < 0 {
+			continue
+		}
+		db := strings.Index(targetBody[depsIdx:], "[")
+		if db < 0 {
+			continue
+		}
+		depsArray, _ := extractBalanced(targetBody, depsIdx+db, '[', ']')
+
+		for _, item := range splitTopLevel(depsArray) {
+			name := targetDependencyPackageName(strings.TrimSpace(item))
+			if name == "" {
+				continue
+			}
+			if existing, ok := scopes[name]; !ok || (existing == "test" && scope == "compile") {
+				scopes[name] = scope
+			}
+		}
+	}
+	return scopes
+}
+
+// targetDependencyPackageName returns the package name a single element of
+// a target's dependencies: [...] array resolves to, or "" for an internal
+// `.target(name: ...)` dependency that doesn't name an external package.
+func targetDependencyPackageName(item string) string {
+	if m := productRe.FindStringSubmatch(item); m != nil {
+		return m[2]
+	}
+	if strings.HasPrefix(item, `"`) {
+		var name string
+		if _, err := fmt.Sscanf(item, "%q", &name); err == nil {
+			return name
+		}
+	}
+	return ""
 }
 
 // ParsePackageResolved (JSON-like lockfile)
@@ -251,7 +598,11 @@ func ScanSwiftFiles(projectDir string) ([]utils.Dependency, error) {
 	return deps, err
 }
 
-// WritePackageSwift regenerates Package.swift
+// WritePackageSwift regenerates Package.swift. It round-trips whatever
+// requirement form ParsePackageSwift recorded in d.Constraint (falling back
+// to "from:" for dependencies discovered only via source scanning, which
+// carry no requirement) and reuses the dependency's real ImportPath/URL
+// instead of guessing a GitHub owner.
 func WritePackageSwift(path string, deps []utils.Dependency) error {
 	file, err := os.Create(path)
 	if err != nil {
@@ -272,12 +623,18 @@ let package = Package(
 	}
 
 	for _, d := range deps {
-		version := d.Version
-		if version == "" {
-			version = "*"
+		var line string
+		switch {
+		case d.Source == "path" && d.ImportPath != "":
+			line = fmt.Sprintf("        .package(path: %q),\n", d.ImportPath)
+		case d.ImportPath != "":
+			line = fmt.Sprintf("        .package(url: %q, %s),\n", d.ImportPath, packageRequirementSyntax(d))
+		default:
+			// No URL was recovered (e.g. a dependency discovered only via a
+			// source "import Foo" scan); best effort only.
+			url := fmt.Sprintf("https://github.com/unknown/%s.git", d.ArtifactID)
+			line = fmt.Sprintf("        .package(url: %q, %s),\n", url, packageRequirementSyntax(d))
 		}
-		line := fmt.Sprintf(`        .package(url: "https://github.com/%s/%s.git", from: "%s"),`+"\n",
-			"org", d.ArtifactID, version)
 		if _, err := writer.WriteString(line); err != nil {
 			return err
 		}
@@ -291,3 +648,43 @@ let package = Package(
 
 	return writer.Flush()
 }
+
+// packageRequirementSyntax renders d's version requirement back into the
+// PackageDescription form it was parsed from.
+func packageRequirementSyntax(d utils.Dependency) string {
+	version := d.Version
+	if version == "" {
+		version = "*"
+	}
+	switch d.Constraint {
+	case "upToNextMajor":
+		return fmt.Sprintf(".upToNextMajor(from: %q)", version)
+	case "upToNextMinor":
+		return fmt.Sprintf(".upToNextMinor(from: %q)", version)
+	case "exact":
+		return fmt.Sprintf(".exact(%q)", version)
+	case "branch":
+		return fmt.Sprintf(".branch(%q)", version)
+	case "revision":
+		return fmt.Sprintf(".revision(%q)", version)
+	case "range":
+		if lower, upper, ok := strings.Cut(version, "..<"); ok {
+			return fmt.Sprintf("%q..<%q", lower, upper)
+		}
+		return fmt.Sprintf("from: %q", version)
+	default:
+		return fmt.Sprintf("from: %q", version)
+	}
+}
+
+// EmitSBOM writes deps to w as an SBOM in the given format ("cyclonedx" or
+// "spdx"); see utils/sbom.
+func (h *SwiftHandler) EmitSBOM(deps []utils.Dependency, w io.Writer, format string) error {
+	return sbom.Emit(deps, w, format)
+}
+
+// ExportSBOM writes deps to outPath as an SBOM in the given format
+// ("cyclonedx" or "spdx"); see utils/sbom.ExportToFile.
+func (h *SwiftHandler) ExportSBOM(deps []utils.Dependency, format, outPath, projectDir string) error {
+	return sbom.ExportToFile(deps, format, outPath, projectDir)
+}
@@ -0,0 +1,84 @@
+// Package i18n provides gettext-style message translation for
+// ort-recovery's user-facing log and summary output. Catalogs are compiled
+// MO files embedded from po/ (one per locale, e.g. po/es.mo), built from
+// the po/*.po sources via the Makefile's "pot"/"mo" targets. T is safe to
+// call with no catalog loaded — a msgid with no translation (or no catalog
+// at all) is used verbatim as the format string, so call sites work
+// correctly whether or not a locale has been selected yet.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+//go:embed po/*.mo
+var catalogFS embed.FS
+
+var (
+	mu      sync.RWMutex
+	current map[string]string // msgid -> msgstr; nil means "no translation loaded"
+)
+
+// SetLang selects the active catalog by locale (e.g. "es", "es_ES.UTF-8",
+// "pt-BR"). An empty lang falls back to the LANG environment variable, then
+// to English. A locale with no matching po/<code>.mo catalog falls back to
+// English silently, since enforcing a hard failure over a missing
+// translation would defeat the point of a graceful i18n layer.
+func SetLang(lang string) {
+	if lang == "" {
+		lang = os.Getenv("LANG")
+	}
+	code := primarySubtag(lang)
+	if code == "" || code == "c" || code == "posix" {
+		code = "en"
+	}
+
+	cat, err := loadCatalog(code)
+	if err != nil {
+		cat = nil
+	}
+
+	mu.Lock()
+	current = cat
+	mu.Unlock()
+}
+
+// primarySubtag extracts the lowercase language code from a locale string
+// like "es_ES.UTF-8" or "pt-BR", dropping any territory, encoding, or
+// modifier suffix.
+func primarySubtag(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	lang = strings.SplitN(lang, ".", 2)[0]
+	lang = strings.SplitN(lang, "_", 2)[0]
+	lang = strings.SplitN(lang, "-", 2)[0]
+	return lang
+}
+
+// T looks up msgid in the active catalog and formats the result with args
+// the same way fmt.Sprintf would. Call sites pass their existing
+// Printf-style format string as msgid, so wiring a message through i18n
+// never changes its signature.
+func T(msgid string, args ...any) string {
+	mu.RLock()
+	msgstr, ok := current[msgid]
+	mu.RUnlock()
+	if !ok || msgstr == "" {
+		msgstr = msgid
+	}
+	if len(args) == 0 {
+		return msgstr
+	}
+	return fmt.Sprintf(msgstr, args...)
+}
+
+func loadCatalog(code string) (map[string]string, error) {
+	data, err := catalogFS.ReadFile("po/" + code + ".mo")
+	if err != nil {
+		return nil, err
+	}
+	return parseMO(data)
+}
@@ -0,0 +1,51 @@
+package i18n
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// parseMO decodes a gettext MO catalog (format revision 0, as produced by
+// msgfmt) into a flat msgid -> msgstr map. Plural forms and the msgid ""
+// metadata header aren't meaningful for this tool's plain Sprintf-style
+// messages, so they're skipped rather than interpreted.
+func parseMO(data []byte) (map[string]string, error) {
+	if len(data) < 28 {
+		return nil, fmt.Errorf("i18n: truncated MO file (%d bytes)", len(data))
+	}
+
+	var order binary.ByteOrder
+	switch binary.LittleEndian.Uint32(data[0:4]) {
+	case 0x950412de:
+		order = binary.LittleEndian
+	case 0xde120495:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("i18n: not a gettext MO file")
+	}
+
+	u32 := func(off uint32) uint32 { return order.Uint32(data[off : off+4]) }
+
+	count := u32(8)
+	origTableOff := u32(12)
+	transTableOff := u32(16)
+
+	catalog := make(map[string]string, count)
+	for i := uint32(0); i < count; i++ {
+		oEntry := origTableOff + i*8
+		tEntry := transTableOff + i*8
+		oLen, oOff := u32(oEntry), u32(oEntry+4)
+		tLen, tOff := u32(tEntry), u32(tEntry+4)
+
+		if uint32(len(data)) < oOff+oLen || uint32(len(data)) < tOff+tLen {
+			return nil, fmt.Errorf("i18n: MO string table entry %d out of bounds", i)
+		}
+
+		msgid := string(data[oOff : oOff+oLen])
+		if msgid == "" {
+			continue // the empty msgid carries document metadata, not a message
+		}
+		catalog[msgid] = string(data[tOff : tOff+tLen])
+	}
+	return catalog, nil
+}
@@ -7,10 +7,19 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"sync"
 	"time"
 
 	"ort-recovery/handlers"
+	dotnetresolver "ort-recovery/handlers/dotnet/nugetresolver"
+	javahandler "ort-recovery/handlers/java"
+	pythonhandler "ort-recovery/handlers/python"
+	"ort-recovery/handlers/python/pypi"
+	"ort-recovery/i18n"
 	"ort-recovery/utils"
+	"ort-recovery/utils/ingest"
+	"ort-recovery/utils/policy"
 )
 
 // RepoEntry describes an input repo entry
@@ -20,38 +29,24 @@ type RepoEntry struct {
 	Product string `json:"product,omitempty"`
 }
 
-// ---------------------------
-// Run Syft (v1.32.0+)
-// ---------------------------
-func runSyft(logger *utils.Logger, syftPath, projectDir string, verbose bool) error {
-	syftJSON := filepath.Join(projectDir, "syft.json")
-
-	// Always remove stale syft.json before scanning
-	if _, err := os.Stat(syftJSON); err == nil {
-		if verbose {
-			logger.Infof("Removing stale %s before new scan", syftJSON)
-		}
-		_ = os.Remove(syftJSON)
-	}
-
-	args := []string{"scan", projectDir, "-o", fmt.Sprintf("json=%s", syftJSON)}
-	if verbose {
-		logger.Infof("Running: %s %v", syftPath, args)
-	}
-
-	cmd := exec.Command(syftPath, args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// Cleanup if syft failed
-		_ = os.Remove(syftJSON)
-		return fmt.Errorf("syft execution failed: %v\nOutput:\n%s", err, string(output))
-	}
+// indexedRepoEntry pairs a RepoEntry with its position in repoList so -jobs
+// workers can write their ProjectReport into the right slot without a mutex.
+type indexedRepoEntry struct {
+	RepoEntry
+	index int
+}
 
-	if _, err := os.Stat(syftJSON); os.IsNotExist(err) {
-		return fmt.Errorf("syft ran but syft.json not found in %s\nOutput:\n%s", projectDir, string(output))
+// binaryFor returns the external binary the given adapter shells out to, or
+// "" for adapters (like "native") that don't need one.
+func binaryFor(adapter utils.SourceAdapter, syftPath string) string {
+	switch adapter.Name() {
+	case "syft":
+		return syftPath
+	case "trivy":
+		return "trivy"
+	default:
+		return ""
 	}
-
-	return nil
 }
 
 // ---------------------------
@@ -110,17 +105,86 @@ func cloneRepo(repoURL, branch, tempRoot string) (string, error) {
 // ---------------------------
 // Process project
 // ---------------------------
-func processProject(logger *utils.Logger, syftPath, projectDir, backupDir string, verbose bool) {
+
+// HandlerResult is one language handler's outcome for a single project,
+// ready to be embedded in the aggregated JSON report -jobs>1 produces.
+type HandlerResult struct {
+	Name    string           `json:"name"`
+	Found   bool             `json:"found"`
+	Added   int              `json:"added"`
+	Errored bool             `json:"errored"`
+	Err     string           `json:"error,omitempty"`
+	Report  utils.ScanReport `json:"report,omitempty"`
+}
+
+// ProjectReport is one repo's outcome, ready to be embedded in the
+// aggregated JSON report written after a -jobs run.
+type ProjectReport struct {
+	Repo     string          `json:"repo"`
+	Branch   string          `json:"branch,omitempty"`
+	Product  string          `json:"product,omitempty"`
+	Err      string          `json:"error,omitempty"`
+	Handlers []HandlerResult `json:"handlers,omitempty"`
+}
+
+// processRepo clones r into its own directory under tempRoot and runs
+// processProject against it, producing that repo's ProjectReport. Each repo
+// gets its own clone directory and its own *utils.Logger-independent call to
+// processProject, so concurrent invocations from a -jobs worker pool don't
+// share any per-project state.
+func processRepo(logger *utils.Logger, adapter utils.SourceAdapter, r RepoEntry, tempRoot, backupDir string, verbose bool, sbomFormat string, langReport bool) ProjectReport {
+	report := ProjectReport{Repo: r.Repo, Branch: r.Branch, Product: r.Product}
+
+	branch := r.Branch
+	if branch == "" {
+		branch = "main"
+	}
+	clonePath, err := cloneRepo(r.Repo, branch, tempRoot)
+	if err != nil {
+		logger.Errorf("Failed to clone repo %s: %v", r.Repo, err)
+		report.Err = err.Error()
+		return report
+	}
+	if verbose {
+		logger.Infof("Repo cloned to %s", clonePath)
+	}
+
+	report.Handlers = processProject(logger, adapter, clonePath, backupDir, verbose, sbomFormat, langReport)
+	return report
+}
+
+// printLanguageReport runs utils.EstimateLanguages against projectDir and
+// prints its per-language byte-weighted breakdown for the "-langReport"
+// flag, ordered by Percentage descending.
+func printLanguageReport(logger *utils.Logger, projectDir string) {
+	detections, err := utils.EstimateLanguages(projectDir)
+	if err != nil {
+		logger.Errorf("Language detection failed for %s: %v", projectDir, err)
+		return
+	}
+	sort.Slice(detections, func(i, j int) bool { return detections[i].Bytes > detections[j].Bytes })
+
+	fmt.Println(i18n.T("----- Language Report: %s -----", projectDir))
+	for _, d := range detections {
+		fmt.Println(i18n.T("- %s: %.1f%% (%d bytes, %d files, confidence=%s)", d.Language, d.Percentage, d.Bytes, len(d.Files), d.Confidence))
+	}
+}
+
+func processProject(logger *utils.Logger, adapter utils.SourceAdapter, projectDir, backupDir string, verbose bool, sbomFormat string, langReport bool) []HandlerResult {
 	absProject, err := filepath.Abs(projectDir)
 	if err != nil {
 		logger.Errorf("failed to resolve project path: %v", err)
-		return
+		return nil
 	}
 
 	if verbose {
 		logger.Infof("Processing project: %s", absProject)
 	}
 
+	if langReport {
+		printLanguageReport(logger, absProject)
+	}
+
 	// ✅ Run go mod tidy first (only if Go project)
 	goModPath := filepath.Join(absProject, "go.mod")
 	if _, err := os.Stat(goModPath); err == nil {
@@ -137,28 +201,27 @@ func processProject(logger *utils.Logger, syftPath, projectDir, backupDir string
 		}
 	}
 
-	// Run Syft
+	// Ingest third-party dependency data via the selected SourceAdapter
+	// (syft, trivy, or native; see "-source"), falling back to native
+	// (manifest/lockfile parsing only, no external binary) if it fails.
 	if verbose {
-		logger.Infof("Running Syft in %s", absProject)
+		logger.Infof("Ingesting dependencies for %s via %s", absProject, adapter.Name())
 	}
-	if err := runSyft(logger, syftPath, absProject, verbose); err != nil {
-		logger.Errorf("Syft failed for %s: %v", absProject, err)
+	if _, err := adapter.Ingest(absProject); err != nil {
+		logger.Errorf("%s ingestion failed for %s: %v; falling back to native ingestion", adapter.Name(), absProject, err)
+		if _, err := (&ingest.NativeAdapter{}).Ingest(absProject); err != nil {
+			logger.Errorf("native ingestion also failed for %s: %v", absProject, err)
+		}
 	}
 
+	// GetHandlers is safe to call from every worker: see its doc comment.
 	handlersList := handlers.GetHandlers()
 	if len(handlersList) == 0 {
 		logger.Infof("No language handlers registered.")
-		return
+		return nil
 	}
 
-	type result struct {
-		Name    string
-		Found   bool
-		Added   int
-		Errored bool
-		Err     error
-	}
-	var summary []result
+	var summary []HandlerResult
 
 	for _, h := range handlersList {
 		name := h.Name()
@@ -170,7 +233,7 @@ func processProject(logger *utils.Logger, syftPath, projectDir, backupDir string
 			if verbose {
 				logger.Infof("Handler %s: not detected in project", name)
 			}
-			summary = append(summary, result{Name: name, Found: false})
+			summary = append(summary, HandlerResult{Name: name, Found: false})
 			continue
 		}
 
@@ -178,18 +241,21 @@ func processProject(logger *utils.Logger, syftPath, projectDir, backupDir string
 			logger.Infof("Handler %s: detected. Scanning...", name)
 		}
 
-		deps, err := h.Scan(absProject)
+		deps, report, err := h.Scan(absProject)
 		if err != nil {
 			logger.Errorf("Handler %s: scan error: %v", name, err)
-			summary = append(summary, result{Name: name, Found: true, Errored: true, Err: err})
+			summary = append(summary, HandlerResult{Name: name, Found: true, Errored: true, Err: err.Error()})
 			continue
 		}
+		if verbose {
+			logger.Infof("Handler %s: scan report: %s", name, report.Render())
+		}
 
 		if len(deps) == 0 {
 			if verbose {
 				logger.Infof("Handler %s: no dependencies found.", name)
 			}
-			summary = append(summary, result{Name: name, Found: true, Added: 0})
+			summary = append(summary, HandlerResult{Name: name, Found: true, Added: 0, Report: report})
 			continue
 		}
 
@@ -206,12 +272,25 @@ func processProject(logger *utils.Logger, syftPath, projectDir, backupDir string
 
 		if err := h.GenerateRecoveryFile(deps, absProject, repoBackupDir); err != nil {
 			logger.Errorf("Handler %s: error generating recovery file: %v", name, err)
-			summary = append(summary, result{Name: name, Found: true, Errored: true, Err: err})
+			summary = append(summary, HandlerResult{Name: name, Found: true, Errored: true, Err: err.Error()})
 			continue
 		}
 
-		added := utils.GetAddedCountForLastHandler(name)
-		summary = append(summary, result{Name: name, Found: true, Added: added})
+		if sbomFormat != "" {
+			sbomPath := filepath.Join(repoBackupDir, fmt.Sprintf("sbom.%s.json", sbomFormat))
+			if err := h.ExportSBOM(deps, sbomFormat, sbomPath, absProject); err != nil {
+				logger.Errorf("Handler %s: error exporting %s SBOM: %v", name, sbomFormat, err)
+			} else if verbose {
+				logger.Infof("Handler %s: wrote %s SBOM to %s", name, sbomFormat, sbomPath)
+			}
+		}
+
+		// Added is just len(deps): -jobs>1 runs multiple projects'
+		// processProject concurrently, and utils.SetAddedCount/
+		// GetAddedCountForLastHandler key on handler name alone, so two
+		// projects scanning the same ecosystem at once would stomp on
+		// each other's count through that global.
+		summary = append(summary, HandlerResult{Name: name, Found: true, Added: len(deps), Report: report})
 	}
 
 	// Cleanup syft.json after handlers finish
@@ -224,17 +303,19 @@ func processProject(logger *utils.Logger, syftPath, projectDir, backupDir string
 	}
 
 	// Final summary
-	fmt.Println("----- ORT Recovery Summary -----")
+	fmt.Println(i18n.T("----- ORT Recovery Summary -----"))
 	for _, s := range summary {
-		status := "skipped"
+		status := i18n.T("skipped")
 		if s.Found && !s.Errored {
-			status = fmt.Sprintf("processed (added=%d)", s.Added)
+			status = i18n.T("processed (added=%d)", s.Added)
 		}
 		if s.Errored {
-			status = fmt.Sprintf("error: %v", s.Err)
+			status = i18n.T("error: %v", s.Err)
 		}
-		fmt.Printf("- %s: %s\n", s.Name, status)
+		fmt.Println(i18n.T("- %s: %s", s.Name, status))
 	}
+
+	return summary
 }
 
 // ---------------------------
@@ -246,8 +327,38 @@ func main() {
 	syftPath := flag.String("syftPath", "syft", "Path to syft binary (default assumes syft is on PATH)")
 	verbose := flag.Bool("v", false, "Verbose logging")
 	keepTemp := flag.Bool("keep-temp", false, "Keep cloned repo directories for debugging")
+	policyMode := flag.String("policy-mode", "warn", "How to handle dependency policy violations: warn or error")
+	online := flag.Bool("online", false, "Allow handlers to resolve dependencies via remote lookups (e.g. Maven Central/Artifactory) beyond Syft; can also be set via ORT_RECOVERY_ONLINE=1")
+	allowPrerelease := flag.Bool("allow-prerelease", false, "Allow the .NET handler to resolve a 'latest' version sentinel to a prerelease NuGet version when no stable version exists; can also be set via ORT_RECOVERY_ALLOW_PRERELEASE=1")
+	noTransitive := flag.Bool("no-transitive", false, "Skip the Python handler's transitive dependency closure walk, keeping recovered manifests to direct dependencies only; can also be set via ORT_RECOVERY_NO_TRANSITIVE=1")
+	offline := flag.Bool("offline", false, "Disable network calls and rely solely on each handler's on-disk cache (e.g. the Python handler's PyPI simple-index cache); can also be set via ORT_RECOVERY_OFFLINE=1")
+	allowUnverified := flag.Bool("allow-unverified", false, "Let the Python handler write a recovery file even when a PyPI release's signature fails verification against configs/pypi_keyring.gpg; can also be set via ORT_RECOVERY_ALLOW_UNVERIFIED=1")
+	sbomFormat := flag.String("sbom", "", "Write an SBOM alongside each project's recovery files in the given format (cyclonedx or spdx); disabled by default")
+	source := flag.String("source", "syft", "Dependency ingestion source: syft, trivy, or native (no external binary; relies on each handler's own manifest/lockfile parsing)")
+	lang := flag.String("lang", "", "Locale for translated output, e.g. 'es' (default: the LANG environment variable, falling back to English)")
+	jobs := flag.Int("jobs", 1, "Number of repos to clone and process concurrently")
+	report := flag.String("report", "", "Write an aggregated JSON report of every repo's handler results to this path; disabled by default")
+	langReport := flag.Bool("langReport", false, "Print a byte-weighted language breakdown for each project before handlers run")
 	flag.Parse()
 
+	i18n.SetLang(*lang)
+	policy.SetMode(policy.Mode(*policyMode))
+	if *online {
+		javahandler.SetOnline(true)
+	}
+	if *allowPrerelease {
+		dotnetresolver.SetAllowPrerelease(true)
+	}
+	if *noTransitive {
+		pythonhandler.SetNoTransitive(true)
+	}
+	if *offline {
+		pypi.SetOffline(true)
+	}
+	if *allowUnverified {
+		pythonhandler.SetAllowUnverified(true)
+	}
+
 	logger, err := utils.NewLogger()
 	if err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
@@ -265,12 +376,20 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Check syft availability
-	if _, err := exec.LookPath(*syftPath); err != nil {
-		logger.Errorf("Syft not found at %s (install it or adjust -syftPath)", *syftPath)
+	adapter, err := ingest.ByName(*source, *syftPath)
+	if err != nil {
+		logger.Errorf("%v", err)
 		os.Exit(1)
 	}
 
+	// Best-effort availability check: log a warning up front rather than
+	// exiting, since Ingest falls back to native per-project anyway.
+	if bin := binaryFor(adapter, *syftPath); bin != "" {
+		if _, err := exec.LookPath(bin); err != nil {
+			logger.Infof("%s binary %q not found; projects will fall back to native ingestion (install it or pass -source native to skip this check)", adapter.Name(), bin)
+		}
+	}
+
 	start := time.Now()
 
 	data, err := os.ReadFile(*repoFile)
@@ -296,25 +415,48 @@ func main() {
 		logger.Infof("Keeping temp clone directories in: %s", tempRoot)
 	}
 
-	for _, r := range repoList {
-		branch := r.Branch
-		if branch == "" {
-			branch = "main"
-		}
-		clonePath, err := cloneRepo(r.Repo, branch, tempRoot)
+	// Clone and process repos through a bounded worker pool: -jobs controls
+	// how many run concurrently, each in its own clone directory under
+	// tempRoot (see processRepo), so one repo's failure or slow handler
+	// can't block the others.
+	numJobs := *jobs
+	if numJobs < 1 {
+		numJobs = 1
+	}
+
+	jobsCh := make(chan indexedRepoEntry)
+	reports := make([]ProjectReport, len(repoList))
+
+	var wg sync.WaitGroup
+	for w := 0; w < numJobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobsCh {
+				idx := job.index
+				reports[idx] = processRepo(logger, adapter, job.RepoEntry, tempRoot, *backupDir, *verbose, *sbomFormat, *langReport)
+			}
+		}()
+	}
+	for i, r := range repoList {
+		jobsCh <- indexedRepoEntry{RepoEntry: r, index: i}
+	}
+	close(jobsCh)
+	wg.Wait()
+
+	if *report != "" {
+		data, err := json.MarshalIndent(reports, "", "  ")
 		if err != nil {
-			logger.Errorf("Failed to clone repo %s: %v", r.Repo, err)
-			continue
+			logger.Errorf("Failed to marshal report: %v", err)
+		} else if err := os.WriteFile(*report, data, 0o644); err != nil {
+			logger.Errorf("Failed to write report to %s: %v", *report, err)
+		} else if *verbose {
+			logger.Infof("Wrote aggregated report to %s", *report)
 		}
-		if *verbose {
-			logger.Infof("Repo cloned to %s", clonePath)
-		}
-
-		processProject(logger, *syftPath, clonePath, *backupDir, *verbose)
 	}
 
 	elapsed := time.Since(start)
-	fmt.Printf("Total elapsed time: %s\n", elapsed)
+	fmt.Println(i18n.T("Total elapsed time: %s", elapsed))
 	logger.Infof("Total elapsed time: %s", elapsed)
 
 	// Explicit close & exit to avoid hanging
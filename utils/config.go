@@ -1,8 +1,48 @@
 package utils
 
-// Config is used by some handlers (like Python) to store recovery settings
+// Config is used by some handlers (like Python) to store recovery settings,
+// and to carry process-wide tuning knobs like ReconcileWorkers.
 type Config struct {
 	ProjectDir       string       // Path to the project being processed
 	Dependencies     []Dependency // Dependencies found or reconciled
 	NoLatestFallback bool         // If true, don't fallback to "latest" when version is missing
+	ReconcileWorkers int          // Concurrency limit for ReconcileDependencies' worker pool
+	EnableOSV        bool         // If true, handlers enrich Dependency.Vulnerabilities via OSV
+	PreferStructured bool         // If true, handlers with a structured backend require it over regex fallbacks
+}
+
+// currentConfig holds the process-wide Config. SetReconcileWorkers and
+// SetEnableOSV mutate it the same way SetLevel overrides the logger's
+// currentLevel.
+var currentConfig = Config{ReconcileWorkers: 4}
+
+// SetReconcileWorkers overrides how many goroutines ReconcileDependencies
+// uses to resolve dependency groups concurrently. Values below 1 are
+// ignored; ReconcileDependencies itself also floors at 1.
+func SetReconcileWorkers(n int) {
+	if n >= 1 {
+		currentConfig.ReconcileWorkers = n
+	}
+}
+
+// SetEnableOSV turns on OSV vulnerability enrichment (see EnrichVulnerabilities)
+// for handlers that support it. Off by default: it costs a network round
+// trip per scan and most callers just want a recovery file.
+func SetEnableOSV(enabled bool) {
+	currentConfig.EnableOSV = enabled
+}
+
+// SetPreferStructured forces handlers that have both a structured backend
+// (e.g. CMake's File API or compile_commands.json) and a regex-based
+// fallback to trust the structured backend's result as-is, even if it
+// finds nothing, instead of falling back to the heuristic path. Off by
+// default, since most projects don't have a configured build tree to read
+// a backend from.
+func SetPreferStructured(enabled bool) {
+	currentConfig.PreferStructured = enabled
+}
+
+// PreferStructured reports whether SetPreferStructured has been enabled.
+func PreferStructured() bool {
+	return currentConfig.PreferStructured
 }
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConflictError reports two or more requirements on the same dependency
+// resolving to incompatible version specifiers, discovered while walking
+// a transitive dependency graph (e.g. pythonhandler's DepTree). Handlers
+// that don't detect conflicts simply never construct one.
+type ConflictError struct {
+	// Name is the dependency every conflicting requirement is on.
+	Name string
+	// Specifiers are the conflicting version specifiers, one per entry in
+	// RequiredBy.
+	Specifiers []string
+	// RequiredBy names the dependency that required each corresponding
+	// entry in Specifiers.
+	RequiredBy []string
+}
+
+func (e *ConflictError) Error() string {
+	var parts []string
+	for i, spec := range e.Specifiers {
+		by := "?"
+		if i < len(e.RequiredBy) {
+			by = e.RequiredBy[i]
+		}
+		parts = append(parts, fmt.Sprintf("%s (via %s)", spec, by))
+	}
+	return fmt.Sprintf("conflicting version requirements for %s: %s", e.Name, strings.Join(parts, " vs "))
+}
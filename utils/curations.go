@@ -15,18 +15,21 @@ type CurationRule struct {
 	Artifact    string `yaml:"artifact"`    // optional override for artifactID
 	Group       string `yaml:"group"`       // optional override for groupID
 	Proprietary bool   `yaml:"proprietary"` // optional flag for proprietary packages
+	AutoPatch   bool   `yaml:"autoPatch"`   // if true, auto-pin to the first OSV-reported fixed version
 }
 
-// ApplyCurations applies master_curations.yml rules to the list of dependencies
-func ApplyCurations(deps []Dependency, curationFile string) ([]Dependency, error) {
+// ApplyCurations applies master_curations.yml rules to the list of
+// dependencies. The returned int is how many deps matched a rule, for
+// ScanReport.CurationsApplied.
+func ApplyCurations(deps []Dependency, curationFile string) ([]Dependency, int, error) {
 	data, err := os.ReadFile(curationFile) // ✅ replaces ioutil.ReadFile
 	if err != nil {
-		return nil, fmt.Errorf("failed to read curation file: %v", err)
+		return nil, 0, fmt.Errorf("failed to read curation file: %v", err)
 	}
 
 	var rules []CurationRule
 	if err := yaml.Unmarshal(data, &rules); err != nil {
-		return nil, fmt.Errorf("failed to parse curation file: %v", err)
+		return nil, 0, fmt.Errorf("failed to parse curation file: %v", err)
 	}
 
 	// Map rules by key for quick lookup
@@ -36,8 +39,10 @@ func ApplyCurations(deps []Dependency, curationFile string) ([]Dependency, error
 	}
 
 	// Apply rules
+	applied := 0
 	for i, d := range deps {
 		if rule, ok := ruleMap[d.Key]; ok {
+			applied++
 			if rule.Version != "" {
 				deps[i].Version = rule.Version
 			}
@@ -51,8 +56,24 @@ func ApplyCurations(deps []Dependency, curationFile string) ([]Dependency, error
 				deps[i].Scope = rule.Scope
 			}
 			// Optional: handle Proprietary flag
+			if rule.AutoPatch {
+				if fixed := firstFixedVersion(d.Vulnerabilities); fixed != "" {
+					deps[i].Version = fixed
+				}
+			}
 		}
 	}
 
-	return deps, nil
+	return deps, applied, nil
+}
+
+// firstFixedVersion returns the FixedVersion of the first vuln in vulns
+// that reports one, or "" if none do.
+func firstFixedVersion(vulns []OSVVuln) string {
+	for _, v := range vulns {
+		if v.FixedVersion != "" {
+			return v.FixedVersion
+		}
+	}
+	return ""
 }
@@ -18,6 +18,92 @@ type Dependency struct {
 	ArtifactID string `json:"artifactId,omitempty"` // For Java/Maven, crates, etc.
 	Scope      string `json:"scope,omitempty"`      // e.g., compile, test, runtime, build
 	Language   string `json:"language,omitempty"`   // e.g., go, java, cpp, rust, swift
+
+	// Cargo-specific fields (populated by RustHandler, safe to ignore elsewhere)
+	Features    []string `json:"features,omitempty"`    // Enabled Cargo features, e.g. ["derive"]
+	Optional    bool     `json:"optional,omitempty"`    // True for optional Cargo dependencies
+	Source      string   `json:"source,omitempty"`      // registry, git, path, or workspace
+	RenamedFrom string   `json:"renamedFrom,omitempty"` // Original crate name when `package = "..."` renames it
+	Target      string   `json:"target,omitempty"`      // cfg(...) target expression this dependency is scoped to
+
+	// Constraint records how a version requirement was originally expressed
+	// when that shape can't be inferred from Version alone (populated by
+	// SwiftHandler): "from", "upToNextMajor", "upToNextMinor", "exact",
+	// "branch", "revision", or "range" (Version holds "lower..<upper").
+	Constraint string `json:"constraint,omitempty"`
+
+	// Origin tags which parsing stage produced this entry before
+	// reconciliation: "lockfile", "syft", "declared", "scanned", or
+	// "binary" (see TagOrigin). ReconcileDependencies uses it to pick a
+	// winning version when the same Key is reported by more than one
+	// source. Left blank by handlers that don't distinguish stages.
+	Origin string `json:"origin,omitempty"`
+
+	// Checksum is a lockfile-provided integrity hash (npm "integrity",
+	// pnpm "resolution.integrity", pip "--hash", etc.), kept verbatim.
+	Checksum string `json:"checksum,omitempty"`
+
+	// SourceFile is the path (relative to the project root) of the file a
+	// code-scanned dependency was discovered in, e.g. an import or require
+	// site. Empty for dependencies read from a manifest or lockfile.
+	SourceFile string `json:"sourceFile,omitempty"`
+
+	// Pinned marks a dependency whose Version was recovered from a
+	// lockfile's exact resolution (e.g. Conan's conan.lock, vcpkg's
+	// overrides/vcpkg-lock.json) rather than a manifest range. Handlers
+	// that support it use this to decide whether a recovery file should
+	// emit a full name/version pin or just a bare name.
+	Pinned bool `json:"pinned,omitempty"`
+
+	// Indirect marks a dependency pulled in transitively rather than
+	// required directly by the project (Go's go.mod "// indirect" marker).
+	Indirect bool `json:"indirect,omitempty"`
+
+	// Requires lists the ArtifactID/Key of other dependencies in the same
+	// scan that this one requires directly, when known. It's best-effort
+	// (populated only where a handler can cheaply derive it, e.g. GoHandler
+	// from the module proxy) and is used to render a dependency graph in
+	// SBOM output; see utils/sbom.
+	Requires []string `json:"requires,omitempty"`
+
+	// Vulnerabilities lists known OSV advisories affecting this exact
+	// Name@Version, populated by EnrichVulnerabilities when
+	// Config.EnableOSV is set. Nil for handlers/ecosystems that don't
+	// opt in, or when the query found nothing.
+	Vulnerabilities []OSVVuln `json:"vulnerabilities,omitempty"`
+
+	// C++-specific fields (populated by CppHandler via its HeaderMap, safe
+	// to ignore elsewhere)
+	VcpkgName string `json:"vcpkgName,omitempty"` // vcpkg port name, when it differs from ArtifactID
+	ConanName string `json:"conanName,omitempty"` // Conan reference name, when it differs from ArtifactID
+	PURL      string `json:"purl,omitempty"`      // package URL for the resolved artifact, when known
+
+	// RuntimeSoname is the raw DT_NEEDED/DT_SONAME entry a binary-scanned
+	// dependency was resolved from (e.g. "libssl.so.3"), kept verbatim so
+	// downstream ORT output can distinguish a declared "openssl/3.0.0"
+	// from a runtime-linked "libssl.so.3". Populated by
+	// cpp.ScanBinaries; empty for dependencies from any other source.
+	RuntimeSoname string `json:"runtimeSoname,omitempty"`
+
+	// Rpath is the colon-joined DT_RPATH/DT_RUNPATH search path recorded
+	// alongside a binary-scanned dependency, when the binary has one.
+	Rpath string `json:"rpath,omitempty"`
+
+	// SPDXID is a stable SPDX element identifier derived from PURL (e.g.
+	// "SPDXRef-cpp-<sha1(purl)>" via cpp.BuildCppSPDXID), so the same
+	// dependency resolves to the same id across scans. Empty for
+	// ecosystems that don't populate it; SBOM output falls back to
+	// deriving one from name/version when blank.
+	SPDXID string `json:"spdxId,omitempty"`
+
+	// Hashes lists "algo:hexdigest" file integrity hashes for this exact
+	// Name@Version -- one entry per distribution file PyPI published for
+	// that release (a wheel and an sdist typically both have one),
+	// populated by pythonhandler.Scan via the PyPI JSON API. Unlike
+	// Checksum (a single lockfile-provided digest), a dependency can have
+	// several of these; pythonhandler.WriteRequirements emits one
+	// "--hash=algo:hexdigest" per entry.
+	Hashes []string `json:"hashes,omitempty"`
 }
 
 // Sanitize ensures the dependency data is valid for its ecosystem
@@ -55,14 +141,26 @@ func (d *Dependency) Sanitize() *Dependency {
 
 		// Return sanitized copy
 		return &Dependency{
-			Name:       d.Name,
-			Version:    version,
-			ImportPath: path,
-			Key:        d.Key,
-			GroupID:    d.GroupID,
-			ArtifactID: d.ArtifactID,
-			Scope:      d.Scope,
-			Language:   d.Language,
+			Name:            d.Name,
+			Version:         version,
+			ImportPath:      path,
+			Key:             d.Key,
+			GroupID:         d.GroupID,
+			ArtifactID:      d.ArtifactID,
+			Scope:           d.Scope,
+			Language:        d.Language,
+			Features:        d.Features,
+			Optional:        d.Optional,
+			Source:          d.Source,
+			RenamedFrom:     d.RenamedFrom,
+			Target:          d.Target,
+			Constraint:      d.Constraint,
+			Origin:          d.Origin,
+			Checksum:        d.Checksum,
+			SourceFile:      d.SourceFile,
+			Indirect:        d.Indirect,
+			Requires:        d.Requires,
+			Vulnerabilities: d.Vulnerabilities,
 		}
 	}
 
@@ -3,13 +3,17 @@ package utils
 import (
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
+// LanguageDetection is one detected language's footprint in a repo.
 type LanguageDetection struct {
 	Language   string
 	Confidence string   // High, Medium, Low
 	Files      []string // Evidence files
+	Bytes      int64    // Total size of files attributed to this language
+	Percentage float64  // Bytes as a percentage of all classified bytes
 }
 
 var ignoreDirs = map[string]bool{
@@ -28,10 +32,39 @@ var ignoreDirs = map[string]bool{
 	".vscode":      true,
 }
 
-// EstimateLanguages walks the repo and detects supported languages.
+// shebangLangs maps an interpreter named on a "#!" line (the last path
+// component, e.g. "python3" out of "/usr/bin/env python3") to a language.
+var shebangLangs = map[string]string{
+	"python":  "python",
+	"python2": "python",
+	"python3": "python",
+	"ruby":    "ruby",
+	"node":    "nodejs",
+	"nodejs":  "nodejs",
+	"bash":    "shell",
+	"sh":      "shell",
+	"zsh":     "shell",
+	"perl":    "perl",
+}
+
+// shebangContentBytes is how much of a file EstimateLanguages reads to look
+// for a "#!" interpreter line on extension-less scripts.
+const shebangContentBytes = 8192
+
+var (
+	objcInterfaceRe = regexp.MustCompile(`@interface|@implementation|@property|#import\s+<Foundation`)
+	cppClassRe      = regexp.MustCompile(`\bclass\s+\w+|\bnamespace\s+\w+|\bstd::|\btemplate\s*<`)
+)
+
+// EstimateLanguages walks the repo and classifies files by language, using
+// manifests and unambiguous extensions first (High/Medium confidence like
+// before), then falling back to content-based heuristics for shebang-only
+// scripts and extensions that are ambiguous on their own (.h, .m). Results
+// are weighted by file size rather than file count, so a repo with one
+// pom.xml and 900 generated .js files still reports Java as primary.
 func EstimateLanguages(repoPath string) ([]LanguageDetection, error) {
-	var detections []LanguageDetection
 	langMap := make(map[string]*LanguageDetection)
+	var totalBytes int64
 
 	err := filepath.Walk(repoPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -51,77 +84,166 @@ func EstimateLanguages(repoPath string) ([]LanguageDetection, error) {
 		// Detect manifests first (High confidence)
 		switch strings.ToLower(info.Name()) {
 		case "pom.xml":
-			addDetection(langMap, "java", "High", path)
+			addDetection(langMap, &totalBytes, "java", "High", path, info.Size())
 		case "requirements.txt":
-			addDetection(langMap, "python", "High", path)
+			addDetection(langMap, &totalBytes, "python", "High", path, info.Size())
 		case "package.json":
-			addDetection(langMap, "nodejs", "High", path)
+			addDetection(langMap, &totalBytes, "nodejs", "High", path, info.Size())
 		case "go.mod":
-			addDetection(langMap, "go", "High", path)
+			addDetection(langMap, &totalBytes, "go", "High", path, info.Size())
 		case "cargo.toml":
-			addDetection(langMap, "rust", "High", path)
+			addDetection(langMap, &totalBytes, "rust", "High", path, info.Size())
 		case "gemfile":
-			addDetection(langMap, "ruby", "High", path)
+			addDetection(langMap, &totalBytes, "ruby", "High", path, info.Size())
 		case "composer.json":
-			addDetection(langMap, "php", "High", path)
+			addDetection(langMap, &totalBytes, "php", "High", path, info.Size())
 		case "packages.config", "project.assets.json":
-			addDetection(langMap, "dotnet", "High", path)
+			addDetection(langMap, &totalBytes, "dotnet", "High", path, info.Size())
 		case "package.swift":
-			addDetection(langMap, "swift", "High", path)
+			addDetection(langMap, &totalBytes, "swift", "High", path, info.Size())
 		case "c++_deps.txt": // hypothetical, we might extend later
-			addDetection(langMap, "cpp", "High", path)
+			addDetection(langMap, &totalBytes, "cpp", "High", path, info.Size())
+		}
+
+		if info.IsDir() {
+			return nil
 		}
 
 		// Detect source files (Medium confidence if manifest not found)
-		if !info.IsDir() {
-			switch filepath.Ext(info.Name()) {
-			case ".java":
-				addDetection(langMap, "java", "Medium", path)
-			case ".py":
-				addDetection(langMap, "python", "Medium", path)
-			case ".js", ".ts":
-				addDetection(langMap, "nodejs", "Medium", path)
-			case ".go":
-				addDetection(langMap, "go", "Medium", path)
-			case ".rs":
-				addDetection(langMap, "rust", "Medium", path)
-			case ".rb":
-				addDetection(langMap, "ruby", "Medium", path)
-			case ".php":
-				addDetection(langMap, "php", "Medium", path)
-			case ".cs", ".vb":
-				addDetection(langMap, "dotnet", "Medium", path)
-			case ".cpp", ".cc", ".cxx", ".h", ".hpp":
-				addDetection(langMap, "cpp", "Medium", path)
-			case ".swift":
-				addDetection(langMap, "swift", "Medium", path)
+		ext := strings.ToLower(filepath.Ext(info.Name()))
+		switch ext {
+		case ".java":
+			addDetection(langMap, &totalBytes, "java", "Medium", path, info.Size())
+		case ".py":
+			addDetection(langMap, &totalBytes, "python", "Medium", path, info.Size())
+		case ".js", ".ts":
+			addDetection(langMap, &totalBytes, "nodejs", "Medium", path, info.Size())
+		case ".go":
+			addDetection(langMap, &totalBytes, "go", "Medium", path, info.Size())
+		case ".rs":
+			addDetection(langMap, &totalBytes, "rust", "Medium", path, info.Size())
+		case ".rb":
+			addDetection(langMap, &totalBytes, "ruby", "Medium", path, info.Size())
+		case ".php":
+			addDetection(langMap, &totalBytes, "php", "Medium", path, info.Size())
+		case ".cs", ".vb":
+			addDetection(langMap, &totalBytes, "dotnet", "Medium", path, info.Size())
+		case ".cc", ".cxx", ".hpp":
+			addDetection(langMap, &totalBytes, "cpp", "Medium", path, info.Size())
+		case ".swift":
+			addDetection(langMap, &totalBytes, "swift", "Medium", path, info.Size())
+		case ".kt", ".kts":
+			addDetection(langMap, &totalBytes, "kotlin", "Medium", path, info.Size())
+		case ".scala":
+			addDetection(langMap, &totalBytes, "scala", "Medium", path, info.Size())
+		case ".groovy", ".gradle":
+			addDetection(langMap, &totalBytes, "groovy", "Medium", path, info.Size())
+		case ".m":
+			addDetection(langMap, &totalBytes, "objc", "Medium", path, info.Size())
+		case ".mm":
+			addDetection(langMap, &totalBytes, "cpp", "Medium", path, info.Size())
+		case ".c":
+			addDetection(langMap, &totalBytes, "c", "Medium", path, info.Size())
+		case ".cpp":
+			addDetection(langMap, &totalBytes, "cpp", "Medium", path, info.Size())
+		case ".h":
+			// ".h" is shared by C, C++, and Objective-C; classify it from
+			// the first few KiB of content instead of guessing "cpp".
+			lang := classifyHeader(path)
+			addDetection(langMap, &totalBytes, lang, "Medium", path, info.Size())
+		case "":
+			if lang, ok := classifyShebang(path); ok {
+				addDetection(langMap, &totalBytes, lang, "Medium", path, info.Size())
 			}
 		}
 
 		return nil
 	})
 
-	// Collect results
+	var detections []LanguageDetection
 	for _, v := range langMap {
+		if totalBytes > 0 {
+			v.Percentage = float64(v.Bytes) / float64(totalBytes) * 100
+		}
 		detections = append(detections, *v)
 	}
 
 	return detections, err
 }
 
-// addDetection ensures we set confidence appropriately
-func addDetection(langMap map[string]*LanguageDetection, lang string, confidence string, file string) {
+// classifyHeader disambiguates a ".h" file by sniffing its content:
+// Objective-C markers ("@interface", "#import <Foundation...") win first,
+// then C++ markers ("class Foo", "namespace", "std::", "template<"),
+// otherwise it's plain C.
+func classifyHeader(path string) string {
+	data, err := readHead(path, shebangContentBytes)
+	if err != nil {
+		return "cpp"
+	}
+	switch {
+	case objcInterfaceRe.Match(data):
+		return "objc"
+	case cppClassRe.Match(data):
+		return "cpp"
+	default:
+		return "c"
+	}
+}
+
+// classifyShebang reads the first line of an extension-less file and, if it
+// starts with "#!", maps the named interpreter to a language.
+func classifyShebang(path string) (string, bool) {
+	data, err := readHead(path, shebangContentBytes)
+	if err != nil || !strings.HasPrefix(string(data), "#!") {
+		return "", false
+	}
+	line := string(data)
+	if idx := strings.IndexByte(line, '\n'); idx >= 0 {
+		line = line[:idx]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", false
+	}
+	interpreter := filepath.Base(fields[len(fields)-1])
+	lang, ok := shebangLangs[interpreter]
+	return lang, ok
+}
+
+// readHead reads up to n bytes from the start of path.
+func readHead(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, n)
+	read, err := f.Read(buf)
+	if err != nil && read == 0 {
+		return nil, err
+	}
+	return buf[:read], nil
+}
+
+// addDetection ensures we set confidence appropriately and accumulate the
+// file's size into both the language's total and the repo-wide total used
+// to normalize Percentage.
+func addDetection(langMap map[string]*LanguageDetection, totalBytes *int64, lang string, confidence string, file string, size int64) {
+	*totalBytes += size
 	if existing, ok := langMap[lang]; ok {
 		// Upgrade confidence if needed (High overrides Medium)
 		if confidence == "High" && existing.Confidence != "High" {
 			existing.Confidence = "High"
 		}
 		existing.Files = append(existing.Files, file)
+		existing.Bytes += size
 	} else {
 		langMap[lang] = &LanguageDetection{
 			Language:   lang,
 			Confidence: confidence,
 			Files:      []string{file},
+			Bytes:      size,
 		}
 	}
 }
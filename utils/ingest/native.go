@@ -0,0 +1,18 @@
+package ingest
+
+import "ort-recovery/utils"
+
+// NativeAdapter needs no external binary at all: it returns no extra
+// dependency data and leaves everything to each handler's own manifest and
+// lockfile parsing, which is already extensive (see e.g. the Node, Rust,
+// and Go handlers' lockfile readers). This is the adapter to select in
+// air-gapped CI where neither Syft nor Trivy can be installed; handlers
+// that read "syft.json" already treat it as optional and fall back to
+// their own declared/scanned dependencies when it's absent.
+type NativeAdapter struct{}
+
+func (a *NativeAdapter) Name() string { return "native" }
+
+func (a *NativeAdapter) Ingest(projectDir string) ([]utils.Dependency, error) {
+	return nil, nil
+}
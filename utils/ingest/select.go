@@ -0,0 +1,23 @@
+package ingest
+
+import (
+	"fmt"
+
+	"ort-recovery/utils"
+)
+
+// ByName resolves the "-source" flag value to a SourceAdapter. syftPath is
+// only used by the "syft" adapter (see SyftAdapter.BinaryPath) and ignored
+// for the others.
+func ByName(name, syftPath string) (utils.SourceAdapter, error) {
+	switch name {
+	case "", "syft":
+		return &SyftAdapter{BinaryPath: syftPath}, nil
+	case "trivy":
+		return &TrivyAdapter{}, nil
+	case "native":
+		return &NativeAdapter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown -source %q (want syft, trivy, or native)", name)
+	}
+}
@@ -0,0 +1,53 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"ort-recovery/utils"
+)
+
+// SyftAdapter shells out to Syft, the ingestion tool main.go used to invoke
+// directly. It writes syft.json into projectDir the same way the old
+// runSyft did, keeping it the file handlers already know how to read, and
+// parses that same file back for Ingest's return value.
+type SyftAdapter struct {
+	// BinaryPath is the syft executable to invoke; defaults to "syft" on PATH.
+	BinaryPath string
+}
+
+func (a *SyftAdapter) Name() string { return "syft" }
+
+func (a *SyftAdapter) Ingest(projectDir string) ([]utils.Dependency, error) {
+	bin := a.BinaryPath
+	if bin == "" {
+		bin = "syft"
+	}
+	if _, err := exec.LookPath(bin); err != nil {
+		return nil, fmt.Errorf("syft binary %q not found: %v", bin, err)
+	}
+
+	syftJSON := filepath.Join(projectDir, "syft.json")
+	_ = os.Remove(syftJSON)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	args := []string{"scan", projectDir, "-o", fmt.Sprintf("json=%s", syftJSON)}
+	cmd := exec.CommandContext(ctx, bin, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		_ = os.Remove(syftJSON)
+		return nil, fmt.Errorf("syft execution failed: %v\noutput:\n%s", err, string(output))
+	}
+
+	data, err := os.ReadFile(syftJSON)
+	if err != nil {
+		return nil, fmt.Errorf("syft ran but %s could not be read: %v", syftJSON, err)
+	}
+	return utils.ParseSyftJSON(data, "")
+}
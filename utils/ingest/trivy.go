@@ -0,0 +1,59 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"ort-recovery/utils"
+	"ort-recovery/utils/sbom"
+)
+
+// TrivyAdapter shells out to `trivy fs --format cyclonedx-json`, an
+// alternative to Syft that many CI images (including air-gapped ones that
+// can't reach Syft's install script) already ship. Its output is written as
+// "bom.json" in projectDir and read back via the same CycloneDXSource the
+// sbom ingestion layer (see utils/sbom) uses for project-shipped SBOMs, then
+// re-encoded as "syft.json" so handlers keep reading the one file they
+// already know about regardless of which adapter produced it.
+type TrivyAdapter struct {
+	// BinaryPath is the trivy executable to invoke; defaults to "trivy" on PATH.
+	BinaryPath string
+}
+
+func (a *TrivyAdapter) Name() string { return "trivy" }
+
+func (a *TrivyAdapter) Ingest(projectDir string) ([]utils.Dependency, error) {
+	bin := a.BinaryPath
+	if bin == "" {
+		bin = "trivy"
+	}
+	if _, err := exec.LookPath(bin); err != nil {
+		return nil, fmt.Errorf("trivy binary %q not found: %v", bin, err)
+	}
+
+	bomPath := filepath.Join(projectDir, "bom.json")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	args := []string{"fs", "--format", "cyclonedx-json", "--output", bomPath, "--quiet", projectDir}
+	cmd := exec.CommandContext(ctx, bin, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("trivy execution failed: %v\noutput:\n%s", err, string(output))
+	}
+
+	deps, err := (&sbom.CycloneDXSource{}).Load(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("trivy wrote %s but it could not be parsed as CycloneDX: %v", bomPath, err)
+	}
+
+	syftJSON := filepath.Join(projectDir, "syft.json")
+	if err := utils.WriteSyftCompatJSON(deps, syftJSON); err != nil {
+		return nil, fmt.Errorf("failed to normalize trivy output into %s: %v", syftJSON, err)
+	}
+	return deps, nil
+}
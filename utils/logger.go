@@ -1,19 +1,94 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
+
+	"ort-recovery/i18n"
+)
+
+// Level is a log severity, ordered from most to least verbose.
+type Level int
+
+const (
+	Trace Level = iota
+	Debug
+	Info
+	Warn
+	Error
 )
 
-// Logger wraps Go's standard log with file and console output
+// String renders a Level the way it appears in log lines, e.g. "WARN".
+func (lv Level) String() string {
+	switch lv {
+	case Trace:
+		return "TRACE"
+	case Debug:
+		return "DEBUG"
+	case Info:
+		return "INFO"
+	case Warn:
+		return "WARN"
+	case Error:
+		return "ERROR"
+	default:
+		return "INFO"
+	}
+}
+
+// ParseLevel maps a level name (any case) to a Level, defaulting to Info
+// for unrecognized values.
+func ParseLevel(s string) Level {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "TRACE":
+		return Trace
+	case "DEBUG":
+		return Debug
+	case "WARN", "WARNING":
+		return Warn
+	case "ERROR":
+		return Error
+	default:
+		return Info
+	}
+}
+
+// currentLevel is the process-wide minimum level that gets emitted.
+// It is set at startup from ORT_LOG_LEVEL and can be overridden via SetLevel.
+var currentLevel = Info
+
+// jsonFormat controls whether log lines are emitted as JSON objects
+// (one per line) instead of the default plain-text format.
+var jsonFormat = strings.EqualFold(os.Getenv("ORT_LOG_FORMAT"), "json")
+
+func init() {
+	if lvl := os.Getenv("ORT_LOG_LEVEL"); lvl != "" {
+		currentLevel = ParseLevel(lvl)
+	}
+}
+
+// SetLevel overrides the process-wide minimum log level, taking precedence
+// over ORT_LOG_LEVEL for the remainder of the run.
+func SetLevel(lv Level) {
+	currentLevel = lv
+}
+
+// Logger wraps Go's standard log with file and console output, plus
+// leveled, structured logging via With().
 type Logger struct {
 	Info  *log.Logger
 	Error *log.Logger
 	File  *os.File
 	Path  string
+
+	fields map[string]any
 }
 
 // NewLogger initializes loggers for info and error messages
@@ -40,18 +115,94 @@ func NewLogger() (*Logger, error) {
 	}, nil
 }
 
-// Infof logs informational messages (console + file)
-func (l *Logger) Infof(format string, v ...interface{}) {
-	log.Printf("[INFO] "+format, v...) // Console
-	l.Info.Printf(format, v...)        // File
+// With returns a copy of the logger carrying additional contextual fields
+// (e.g. handler name, project path, phase) that get attached to every
+// subsequent log line. Pass alternating key/value pairs, e.g.
+// logger.With("handler", "Node.js", "project", projectDir).
+func (l *Logger) With(kv ...any) *Logger {
+	merged := make(map[string]any, len(l.fields)+len(kv)/2)
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			continue
+		}
+		merged[key] = kv[i+1]
+	}
+	return &Logger{Info: l.Info, Error: l.Error, File: l.File, Path: l.Path, fields: merged}
 }
 
-// Errorf logs error messages (console + file)
-func (l *Logger) Errorf(format string, v ...interface{}) {
-	log.Printf("[ERROR] "+format, v...) // Console
-	l.Error.Printf(format, v...)        // File
+// emit writes one log line at the given level, honoring currentLevel and
+// jsonFormat, to both console and the logger's file. format is looked up
+// as a msgid in the active i18n catalog (see package i18n) before being
+// used as the Sprintf template, so every Logger call is translatable by
+// adding its format string to a po/<lang>.po catalog.
+func (l *Logger) emit(lv Level, format string, v ...interface{}) {
+	if lv < currentLevel {
+		return
+	}
+	msg := i18n.T(format, v...)
+	line := formatLogLine(lv, msg, l.fields)
+
+	fmt.Println(line)
+	if lv >= Error && l.Error != nil {
+		l.Error.Print(msg)
+	} else if l.Info != nil {
+		l.Info.Print(msg)
+	}
+}
+
+// formatLogLine renders a single log line as JSON or plain text depending
+// on jsonFormat.
+func formatLogLine(lv Level, msg string, fields map[string]any) string {
+	if jsonFormat {
+		entry := map[string]any{
+			"level": lv.String(),
+			"msg":   msg,
+			"time":  time.Now().Format(time.RFC3339),
+		}
+		for k, v := range fields {
+			entry[k] = v
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Sprintf("[%s] %s", lv.String(), msg)
+		}
+		return string(data)
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("[%s] %s", lv.String(), msg))
+	if len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			b.WriteString(fmt.Sprintf(" %s=%v", k, fields[k]))
+		}
+	}
+	return b.String()
 }
 
+// Tracef logs at Trace level (console + file)
+func (l *Logger) Tracef(format string, v ...interface{}) { l.emit(Trace, format, v...) }
+
+// Debugf logs at Debug level (console + file)
+func (l *Logger) Debugf(format string, v ...interface{}) { l.emit(Debug, format, v...) }
+
+// Infof logs informational messages (console + file)
+func (l *Logger) Infof(format string, v ...interface{}) { l.emit(Info, format, v...) }
+
+// Warnf logs at Warn level (console + file)
+func (l *Logger) Warnf(format string, v ...interface{}) { l.emit(Warn, format, v...) }
+
+// Errorf logs error messages (console + file)
+func (l *Logger) Errorf(format string, v ...interface{}) { l.emit(Error, format, v...) }
+
 // Close closes the log file when done
 func (l *Logger) Close() {
 	if l.File != nil {
@@ -59,11 +210,75 @@ func (l *Logger) Close() {
 	}
 }
 
-// AppendLog writes a single line to recovery.log inside a project directory
-// This is mainly for backward compatibility with handler calls
+// handlerTagRegexPrefix strips a leading "[Something]" tag off a log line
+// so it can be surfaced as a structured "handler" field instead.
+func splitHandlerTag(line string) (handler, rest string) {
+	if !strings.HasPrefix(line, "[") {
+		return "", line
+	}
+	end := strings.Index(line, "]")
+	if end < 0 {
+		return "", line
+	}
+	return line[1:end], strings.TrimSpace(line[end+1:])
+}
+
+// classifyLevel infers a Level from conventional markers handlers already
+// use in their AppendLog calls, e.g. "WARNING:" or "ERROR:".
+func classifyLevel(msg string) Level {
+	upper := strings.ToUpper(msg)
+	switch {
+	case strings.Contains(upper, "ERROR"):
+		return Error
+	case strings.Contains(upper, "WARNING") || strings.Contains(upper, "WARN"):
+		return Warn
+	default:
+		return Info
+	}
+}
+
+// appendLogMu guards a per-log-file mutex so concurrent AppendLog calls for
+// the same project (handlers running across a -jobs worker pool can log
+// from multiple goroutines) don't interleave partial lines; calls against
+// different projects' log files don't contend with each other.
+var appendLogMu sync.Map // map[string]*sync.Mutex
+
+func appendLogMutex(path string) *sync.Mutex {
+	v, _ := appendLogMu.LoadOrStore(path, &sync.Mutex{})
+	return v.(*sync.Mutex)
+}
+
+// AppendLog writes a single line to recovery.log inside a project directory,
+// and mirrors it through the leveled logger with structured `handler=` and
+// `file=`-style fields inferred from the message. This is mainly for
+// backward compatibility with handler calls that predate the leveled logger.
+// Like Logger.emit, format is translated via the active i18n catalog (see
+// package i18n) before args are substituted in. Safe to call concurrently,
+// including from multiple goroutines writing to the same projectDir.
 func AppendLog(projectDir, format string, args ...interface{}) error {
+	msg := i18n.T(format, args...)
+
+	handler, rest := splitHandlerTag(msg)
+	fields := map[string]any{}
+	if handler != "" {
+		fields["handler"] = handler
+	}
+	if projectDir != "" {
+		fields["project"] = projectDir
+	}
+
+	lv := classifyLevel(rest)
+	if lv < currentLevel {
+		return nil
+	}
+	fmt.Println(formatLogLine(lv, rest, fields))
+
 	logPath := filepath.Join(projectDir, "recovery.log")
 
+	mu := appendLogMutex(logPath)
+	mu.Lock()
+	defer mu.Unlock()
+
 	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to open log file %s: %v", logPath, err)
@@ -71,7 +286,6 @@ func AppendLog(projectDir, format string, args ...interface{}) error {
 	defer f.Close()
 
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	line := fmt.Sprintf(format, args...)
-	_, err = fmt.Fprintf(f, "[%s] %s\n", timestamp, line)
+	_, err = fmt.Fprintf(f, "[%s] %s\n", timestamp, msg)
 	return err
 }
@@ -0,0 +1,63 @@
+// Package-level registry mirroring, Glide-style: a project can ship
+// configs/mirrors.yml to redirect where recovery files and network
+// lookups point for specific packages, without hand-editing every
+// generated Gemfile/composer.json. This is for air-gapped installs that
+// run their own Nexus/Artifactory/OSV mirror instead of the public
+// rubygems.org/packagist.org/api.osv.dev.
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MirrorsFileName is the conventional project-root mirrors file.
+const MirrorsFileName = "configs/mirrors.yml"
+
+// MirrorRule redirects lookups for packages matching Original (a name or
+// a glob, e.g. "acme/*") within Ecosystem ("rubygems", "packagist", ...)
+// to Replacement. When VCS is true, Replacement is a git remote and
+// writers should emit a git-backed dependency (Gemfile `git:`, composer's
+// "vcs" repository type) instead of a plain registry source.
+type MirrorRule struct {
+	Ecosystem   string `yaml:"ecosystem"`
+	Original    string `yaml:"original"`
+	Replacement string `yaml:"replacement"`
+	VCS         bool   `yaml:"vcs"`
+}
+
+// LoadMirrorRules reads path (by convention MirrorsFileName) and returns
+// its rules. A missing file is not an error: mirrors are opt-in, and a
+// project with no air-gapped requirements simply won't have one.
+func LoadMirrorRules(path string) ([]MirrorRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read mirrors file: %v", err)
+	}
+	var rules []MirrorRule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse mirrors file: %v", err)
+	}
+	return rules, nil
+}
+
+// MatchMirror returns the first rule in rules whose Ecosystem matches
+// ecosystem and whose Original glob-matches name (via filepath.Match, so
+// "acme/*" matches "acme/widgets"), or ok=false if none do.
+func MatchMirror(rules []MirrorRule, ecosystem, name string) (MirrorRule, bool) {
+	for _, r := range rules {
+		if r.Ecosystem != ecosystem {
+			continue
+		}
+		if matched, _ := filepath.Match(r.Original, name); matched {
+			return r, true
+		}
+	}
+	return MirrorRule{}, false
+}
@@ -0,0 +1,42 @@
+package utils
+
+// ModDiagnosticKind identifies which shape of drift a ModDiagnostic
+// reports between an on-disk go.mod and what `go mod tidy` would write.
+type ModDiagnosticKind string
+
+const (
+	// MissingRequirement: tidy would add Path@Version; ImportedBy lists
+	// the .go files whose imports pulled it in.
+	MissingRequirement ModDiagnosticKind = "missing_requirement"
+	// UnusedRequirement: Path is required on disk but nothing imports it;
+	// tidy would drop it.
+	UnusedRequirement ModDiagnosticKind = "unused_requirement"
+	// WrongDirectness: Path's "// indirect" marker (Was) doesn't match
+	// what tidy would set (Now), e.g. "direct" -> "indirect".
+	WrongDirectness ModDiagnosticKind = "wrong_directness"
+	// WrongVersion: Path is pinned at Was but tidy would pin it at Now.
+	WrongVersion ModDiagnosticKind = "wrong_version"
+)
+
+// ModDiagnostic reports one piece of go.mod drift, as produced by
+// GoHandler.Diagnose. Which of Version/ImportedBy/Was/Now are populated
+// depends on Kind.
+type ModDiagnostic struct {
+	Kind ModDiagnosticKind `json:"kind"`
+	Path string            `json:"path"`
+
+	// Populated for MissingRequirement.
+	Version    string   `json:"version,omitempty"`
+	ImportedBy []string `json:"importedBy,omitempty"`
+
+	// Populated for WrongDirectness ("direct"/"indirect") and WrongVersion.
+	Was string `json:"was,omitempty"`
+	Now string `json:"now,omitempty"`
+
+	// File/Line pinpoint one import site that triggered this diagnostic,
+	// recovered by mapping the AST import position back through
+	// token.FileSet. Empty when no single site applies, e.g.
+	// UnusedRequirement.
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
@@ -0,0 +1,324 @@
+// OSV vulnerability enrichment: queries the public OSV.dev API in batch
+// for every reconciled Name@Version and attaches results onto
+// Dependency.Vulnerabilities. Opt-in via Config.EnableOSV (SetEnableOSV),
+// since it costs a network round trip per scan.
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// osvOfflineMode disables every network call this file makes, mirroring
+// nugetindex's offline mode for deterministic CI runs.
+var osvOfflineMode = os.Getenv("ORT_RECOVERY_OFFLINE") == "1"
+
+// osvCacheDir and osvCacheFile locate the per-project OSV result cache,
+// keyed by "ecosystem:name:version", so repeated scans don't re-hit the
+// API (see nugetindex's namespace cache for the same convention).
+const osvCacheDir = ".ort-recovery"
+const osvCacheFile = "osv-cache.json"
+
+// osvQueryBatchURL is the public OSV batch query endpoint.
+const osvQueryBatchURL = "https://api.osv.dev/v1/querybatch"
+
+// osvMaxAttempts is how many times a batch request is retried on
+// transient failure before its dependencies are left unenriched.
+const osvMaxAttempts = 3
+
+// osvEcosystems maps ort-recovery's internal ecosystem names (as passed
+// to policy.Apply) to the ecosystem strings OSV expects. Ecosystems with
+// no OSV equivalent (e.g. "cpp", "swift") are left out and simply never
+// queried.
+var osvEcosystems = map[string]string{
+	"npm":       "npm",
+	"pypi":      "PyPI",
+	"maven":     "Maven",
+	"nuget":     "NuGet",
+	"packagist": "Packagist",
+	"rubygems":  "RubyGems",
+	"crates":    "crates.io",
+	"go":        "Go",
+}
+
+// OSVVuln is one vulnerability OSV reported against a Name@Version, kept
+// down to the fields ApplyCurations' AutoPatch and recovery reports need.
+type OSVVuln struct {
+	ID           string `json:"id"`                     // e.g. "GHSA-xxxx-xxxx-xxxx" or "CVE-2021-..."
+	Severity     string `json:"severity,omitempty"`     // CVSS vector or OSV's summary severity, as reported
+	FixedVersion string `json:"fixedVersion,omitempty"` // first version OSV lists as fixed, empty if unknown
+}
+
+// EnrichVulnerabilities queries OSV for every dep in deps whose ecosystem
+// (looked up via osvEcosystems) OSV understands, and returns a copy of
+// deps with Vulnerabilities populated. It's a no-op (returns deps
+// unchanged) when Config.EnableOSV is false, ORT_RECOVERY_OFFLINE=1 is
+// set, or none of deps map to a known OSV ecosystem.
+//
+// Results are cached under projectDir/.ort-recovery/osv-cache.json keyed
+// by "ecosystem:name:version"; a cache hit never touches the network.
+// Each batch request is retried up to osvMaxAttempts times; a dependency
+// whose query still fails is left with no Vulnerabilities rather than
+// failing the whole scan.
+func EnrichVulnerabilities(deps []Dependency, ecosystem, projectDir string) ([]Dependency, error) {
+	if !currentConfig.EnableOSV || osvOfflineMode {
+		return deps, nil
+	}
+	osvEco, ok := osvEcosystems[ecosystem]
+	if !ok {
+		return deps, nil
+	}
+
+	mirrors, err := LoadMirrorRules(MirrorsFileName)
+	if err != nil {
+		AppendLog(projectDir, "[osv] WARNING: failed to load mirrors file: %v", err)
+	}
+
+	cache := loadOSVCache(projectDir)
+
+	// toQuery groups dependency indices by the querybatch endpoint to hit:
+	// the public default, or a mirror's Replacement when a non-VCS rule
+	// matches the package name (see utils/mirrors.go). A VCS-mirrored
+	// dependency has no OSV-compatible endpoint to ask, so it's skipped.
+	toQuery := map[string][]int{}
+	for i, d := range deps {
+		name := depPackageName(d)
+		if name == "" || d.Version == "" {
+			continue
+		}
+		endpoint := osvQueryBatchURL
+		if rule, mirrored := MatchMirror(mirrors, ecosystem, name); mirrored {
+			if rule.VCS {
+				continue
+			}
+			endpoint = rule.Replacement
+		}
+		key := osvCacheKey(osvEco, name, d.Version)
+		if vulns, ok := cache.Results[key]; ok {
+			deps[i].Vulnerabilities = vulns
+			continue
+		}
+		toQuery[endpoint] = append(toQuery[endpoint], i)
+	}
+	if len(toQuery) == 0 {
+		return deps, nil
+	}
+
+	dirty := false
+	for endpoint, indices := range toQuery {
+		queries := make([]osvQuery, len(indices))
+		for j, i := range indices {
+			queries[j] = osvQuery{
+				Package: osvPackage{Name: depPackageName(deps[i]), Ecosystem: osvEco},
+				Version: deps[i].Version,
+			}
+		}
+
+		resp, err := osvQueryBatchWithRetry(endpoint, queries)
+		if err != nil {
+			AppendLog(projectDir, "[osv] WARNING: querybatch against %s failed after %d attempts: %v", endpoint, osvMaxAttempts, err)
+			continue
+		}
+
+		for j, i := range indices {
+			if j >= len(resp.Results) {
+				break
+			}
+			vulns := toOSVVulns(resp.Results[j].Vulns)
+			deps[i].Vulnerabilities = vulns
+			cache.Results[osvCacheKey(osvEco, depPackageName(deps[i]), deps[i].Version)] = vulns
+			dirty = true
+		}
+	}
+	if dirty {
+		cache.save(projectDir)
+	}
+
+	return deps, nil
+}
+
+// depPackageName returns the package name to query OSV with: Name for
+// ecosystems that populate it (Go, npm, ...), falling back to ArtifactID
+// for ecosystems that don't (Ruby, PHP; see reconcileKey for the same
+// fallback during reconciliation).
+func depPackageName(d Dependency) string {
+	if d.Name != "" {
+		return d.Name
+	}
+	return d.ArtifactID
+}
+
+// ---------------------------
+// OSV querybatch wire types
+// ---------------------------
+
+type osvPackage struct {
+	Name      string `json:"name"`
+	Ecosystem string `json:"ecosystem"`
+}
+
+type osvQuery struct {
+	Package osvPackage `json:"package"`
+	Version string     `json:"version"`
+}
+
+type osvBatchRequest struct {
+	Queries []osvQuery `json:"queries"`
+}
+
+type osvSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type osvAffectedRange struct {
+	Type   string `json:"type"`
+	Events []struct {
+		Introduced string `json:"introduced,omitempty"`
+		Fixed      string `json:"fixed,omitempty"`
+	} `json:"events"`
+}
+
+type osvAffected struct {
+	Ranges []osvAffectedRange `json:"ranges"`
+}
+
+type osvVulnResult struct {
+	ID       string        `json:"id"`
+	Severity []osvSeverity `json:"severity"`
+	Affected []osvAffected `json:"affected"`
+}
+
+type osvBatchResultEntry struct {
+	Vulns []osvVulnResult `json:"vulns"`
+}
+
+type osvBatchResponse struct {
+	Results []osvBatchResultEntry `json:"results"`
+}
+
+// toOSVVulns flattens OSV's nested severity/affected-ranges shape down to
+// the OSVVuln fields ApplyCurations and recovery reports care about.
+func toOSVVulns(results []osvVulnResult) []OSVVuln {
+	if len(results) == 0 {
+		return nil
+	}
+	vulns := make([]OSVVuln, 0, len(results))
+	for _, r := range results {
+		v := OSVVuln{ID: r.ID}
+		if len(r.Severity) > 0 {
+			v.Severity = r.Severity[0].Score
+		}
+		for _, rng := range r.Affected {
+			for _, ar := range rng.Ranges {
+				for _, ev := range ar.Events {
+					if ev.Fixed != "" {
+						v.FixedVersion = ev.Fixed
+						break
+					}
+				}
+				if v.FixedVersion != "" {
+					break
+				}
+			}
+			if v.FixedVersion != "" {
+				break
+			}
+		}
+		vulns = append(vulns, v)
+	}
+	return vulns
+}
+
+// osvQueryBatchWithRetry POSTs req to endpoint (the public querybatch URL,
+// or a mirror's Replacement), retrying up to osvMaxAttempts times with a
+// short linear backoff on network or 5xx errors.
+func osvQueryBatchWithRetry(endpoint string, queries []osvQuery) (osvBatchResponse, error) {
+	body, err := json.Marshal(osvBatchRequest{Queries: queries})
+	if err != nil {
+		return osvBatchResponse{}, fmt.Errorf("osv: encoding request: %v", err)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= osvMaxAttempts; attempt++ {
+		resp, err := osvPost(endpoint, body)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if attempt < osvMaxAttempts {
+			time.Sleep(time.Duration(attempt) * 500 * time.Millisecond)
+		}
+	}
+	return osvBatchResponse{}, lastErr
+}
+
+func osvPost(endpoint string, body []byte) (osvBatchResponse, error) {
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return osvBatchResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return osvBatchResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return osvBatchResponse{}, fmt.Errorf("osv: querybatch returned %s", resp.Status)
+	}
+
+	var out osvBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return osvBatchResponse{}, fmt.Errorf("osv: decoding response: %v", err)
+	}
+	return out, nil
+}
+
+// osvCacheKey is the cache key for one Name@Version within an ecosystem.
+func osvCacheKey(ecosystem, name, version string) string {
+	return strings.Join([]string{ecosystem, name, version}, ":")
+}
+
+// ---------------------------
+// On-disk OSV result cache
+// ---------------------------
+
+type osvCache struct {
+	Results map[string][]OSVVuln `json:"results"`
+}
+
+func loadOSVCache(projectDir string) *osvCache {
+	c := &osvCache{Results: map[string][]OSVVuln{}}
+	data, err := os.ReadFile(filepath.Join(projectDir, osvCacheDir, osvCacheFile))
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return &osvCache{Results: map[string][]OSVVuln{}}
+	}
+	if c.Results == nil {
+		c.Results = map[string][]OSVVuln{}
+	}
+	return c
+}
+
+func (c *osvCache) save(projectDir string) {
+	dir := filepath.Join(projectDir, osvCacheDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(dir, osvCacheFile), data, 0644)
+}
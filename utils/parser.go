@@ -3,6 +3,7 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 )
 
@@ -53,3 +54,28 @@ func ParseSyftJSON(data []byte, language string) ([]Dependency, error) {
 
 	return deps, nil
 }
+
+// WriteSyftCompatJSON encodes deps in the same {"artifacts":[...]} schema
+// ParseSyftJSON reads, so a SourceAdapter whose tool doesn't speak Syft's
+// format natively (e.g. ingest.TrivyAdapter) can still hand its results off
+// through the "syft.json" file every handler already knows how to read.
+func WriteSyftCompatJSON(deps []Dependency, path string) error {
+	var out SyftOutput
+	for _, d := range deps {
+		purl := ""
+		if d.GroupID != "" && d.GroupID != "unknown.group" {
+			purl = fmt.Sprintf("pkg:maven/%s/%s@%s", d.GroupID, d.ArtifactID, d.Version)
+		}
+		out.Artifacts = append(out.Artifacts, struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+			PURL    string `json:"purl"`
+		}{Name: d.ArtifactID, Version: d.Version, PURL: purl})
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode syft-compatible JSON: %v", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
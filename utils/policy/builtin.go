@@ -0,0 +1,14 @@
+package policy
+
+// BuiltinRuleSets ships a couple of illustrative default rules so the
+// policy format has a working example even on projects with no
+// ".ort-recovery-policy.yaml" of their own. Project policy files are
+// merged on top of these, not instead of them.
+var BuiltinRuleSets = &Rules{
+	Forbidden: map[string][]string{
+		// The "request" package has been deprecated since 2020 in favor
+		// of native fetch/undici; flag it so it doesn't get silently
+		// re-added to a recovered package.json.
+		"npm": {"request"},
+	},
+}
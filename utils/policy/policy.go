@@ -0,0 +1,218 @@
+// Package policy implements a cross-cutting policy engine for dependencies
+// recovered by any language handler: forbidding specific packages, pinning
+// versions, and (eventually) checking licenses against an allowlist.
+//
+// A policy file (by convention ".ort-recovery-policy.yaml" in a project's
+// root) is optional. When absent, only the built-in rule sets apply.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"ort-recovery/utils"
+)
+
+// Mode controls what happens when a violation is found.
+type Mode string
+
+const (
+	// ModeWarn logs violations but lets the scan proceed.
+	ModeWarn Mode = "warn"
+	// ModeError fails the handler's Scan when any violation is found.
+	ModeError Mode = "error"
+)
+
+// currentMode is the process-wide policy mode, set from main via SetMode
+// (mirrors utils.SetLevel for the leveled logger).
+var currentMode = ModeWarn
+
+// SetMode overrides the process-wide policy mode. Unrecognized values
+// leave the mode unchanged.
+func SetMode(m Mode) {
+	if m == ModeWarn || m == ModeError {
+		currentMode = m
+	}
+}
+
+// PolicyFileName is the conventional per-project policy file name.
+const PolicyFileName = ".ort-recovery-policy.yaml"
+
+// Rules declares forbidden/allowed packages, version pins, and a license
+// allowlist, each keyed by ecosystem (e.g. "npm", "crates", "pypi"). When
+// Allowed lists any packages for an ecosystem, every dependency of that
+// ecosystem not named in the list is a violation -- an ecosystem with no
+// Allowed entries is unrestricted.
+type Rules struct {
+	Forbidden        map[string][]string          `yaml:"forbidden,omitempty"`
+	Allowed          map[string][]string          `yaml:"allowed,omitempty"`
+	VersionPins      map[string]map[string]string `yaml:"version_pins,omitempty"`
+	LicenseAllowlist map[string][]string          `yaml:"license_allowlist,omitempty"`
+}
+
+// Violation describes a single dependency that broke a rule.
+type Violation struct {
+	Ecosystem  string
+	Dependency utils.Dependency
+	Rule       string // e.g. "forbidden", "version_pin"
+	Message    string
+}
+
+// Load reads a policy file from disk.
+func Load(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %v", err)
+	}
+	var r Rules
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %v", path, err)
+	}
+	return &r, nil
+}
+
+// LoadForProject looks for PolicyFileName at the root of projectDir and
+// loads it if present. A missing file is not an error - it just means no
+// project-specific rules apply.
+func LoadForProject(projectDir string) (*Rules, error) {
+	path := filepath.Join(projectDir, PolicyFileName)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return Load(path)
+}
+
+// packageKey returns the name a Rules ecosystem list should match against
+// for a given dependency: ArtifactID for most ecosystems, falling back to
+// Name for the general-purpose fields Go/JS/Ruby handlers populate.
+func packageKey(d utils.Dependency) string {
+	if d.ArtifactID != "" {
+		return d.ArtifactID
+	}
+	return d.Name
+}
+
+// Evaluate checks deps against the rule set for the given ecosystem and
+// returns every violation found (forbidden packages, version-pin
+// mismatches, and -- when an allowlist is declared for the ecosystem --
+// packages missing from it). An empty Rules or unmatched ecosystem yields
+// no violations.
+func (r *Rules) Evaluate(ecosystem string, deps []utils.Dependency) []Violation {
+	if r == nil {
+		return nil
+	}
+
+	forbidden := make(map[string]bool)
+	for _, name := range r.Forbidden[ecosystem] {
+		forbidden[name] = true
+	}
+	pins := r.VersionPins[ecosystem]
+
+	var allowed map[string]bool
+	if names := r.Allowed[ecosystem]; len(names) > 0 {
+		allowed = make(map[string]bool, len(names))
+		for _, name := range names {
+			allowed[name] = true
+		}
+	}
+
+	var violations []Violation
+	for _, d := range deps {
+		key := packageKey(d)
+		if key == "" {
+			continue
+		}
+		if forbidden[key] {
+			violations = append(violations, Violation{
+				Ecosystem:  ecosystem,
+				Dependency: d,
+				Rule:       "forbidden",
+				Message:    fmt.Sprintf("%s is forbidden by policy", key),
+			})
+		}
+		if allowed != nil && !allowed[key] {
+			violations = append(violations, Violation{
+				Ecosystem:  ecosystem,
+				Dependency: d,
+				Rule:       "not_allowed",
+				Message:    fmt.Sprintf("%s is not in the %s allowlist", key, ecosystem),
+			})
+		}
+		if pin, ok := pins[key]; ok && d.Version != "" && d.Version != pin {
+			violations = append(violations, Violation{
+				Ecosystem:  ecosystem,
+				Dependency: d,
+				Rule:       "version_pin",
+				Message:    fmt.Sprintf("%s is pinned to %s by policy, found %s", key, pin, d.Version),
+			})
+		}
+	}
+	return violations
+}
+
+// merge combines the built-in rule set for an ecosystem with an optional
+// project-specific override, project rules taking precedence on conflicts
+// (handled naturally since both are evaluated and results de-duplicate by
+// dependency+rule at the caller if needed).
+func merge(sets ...*Rules) *Rules {
+	merged := &Rules{
+		Forbidden:        map[string][]string{},
+		Allowed:          map[string][]string{},
+		VersionPins:      map[string]map[string]string{},
+		LicenseAllowlist: map[string][]string{},
+	}
+	for _, s := range sets {
+		if s == nil {
+			continue
+		}
+		for eco, names := range s.Forbidden {
+			merged.Forbidden[eco] = append(merged.Forbidden[eco], names...)
+		}
+		for eco, names := range s.Allowed {
+			merged.Allowed[eco] = append(merged.Allowed[eco], names...)
+		}
+		for eco, pins := range s.VersionPins {
+			if merged.VersionPins[eco] == nil {
+				merged.VersionPins[eco] = map[string]string{}
+			}
+			for k, v := range pins {
+				merged.VersionPins[eco][k] = v
+			}
+		}
+		for eco, licenses := range s.LicenseAllowlist {
+			merged.LicenseAllowlist[eco] = append(merged.LicenseAllowlist[eco], licenses...)
+		}
+	}
+	return merged
+}
+
+// Apply is the hook handlers call at the end of Scan: it loads any
+// project-specific policy file, merges it with the built-in rule sets,
+// evaluates deps, and logs every violation found via utils.AppendLog. In
+// ModeError, a non-empty violation set is returned as an error so the
+// handler's Scan fails; in ModeWarn (the default) violations are only
+// logged and Scan proceeds normally.
+func Apply(handlerName, ecosystem, projectDir string, deps []utils.Dependency) error {
+	projectRules, err := LoadForProject(projectDir)
+	if err != nil {
+		utils.AppendLog(projectDir, "[%s] WARNING: failed to load policy file: %v", handlerName, err)
+	}
+
+	rules := merge(BuiltinRuleSets, projectRules)
+	violations := rules.Evaluate(ecosystem, deps)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	for _, v := range violations {
+		utils.AppendLog(projectDir, "[%s] POLICY VIOLATION (%s): %s", handlerName, v.Rule, v.Message)
+	}
+
+	if currentMode == ModeError {
+		return fmt.Errorf("policy violations found for %s (%d): first is %s", handlerName, len(violations), violations[0].Message)
+	}
+	return nil
+}
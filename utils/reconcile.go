@@ -1,11 +1,230 @@
 package utils
 
-import "fmt"
-
-// ReconcileDependencies is a stub used by Ruby, Rust, Swift handlers.
-// Right now it just logs and returns the input dependencies unchanged.
-func ReconcileDependencies(deps []Dependency) ([]Dependency, error) {
-	// TODO: implement real reconciliation logic later
-	fmt.Printf("[INFO] ReconcileDependencies called with %d dependencies\n", len(deps))
-	return deps, nil
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// originPriority ranks where a dependency entry came from, highest first:
+// a lockfile pins an exact resolved version, syft/trivy scan the actual
+// installed artifact, a manifest declaration often carries just a range,
+// and a source-scanned import or a binary's soname rarely carry a version
+// at all. Unknown/blank origins rank lowest of all.
+var originPriority = map[string]int{
+	"lockfile": 4,
+	"syft":     3,
+	"declared": 2,
+	"scanned":  1,
+	"binary":   1,
+}
+
+// TagOrigin sets Origin on every dependency in deps (in place) to origin,
+// so ReconcileDependencies can later pick a winning version by priority
+// when the same Key is reported by more than one source. Returns deps for
+// convenient chaining at a handler's merge site.
+func TagOrigin(deps []Dependency, origin string) []Dependency {
+	for i := range deps {
+		deps[i].Origin = origin
+	}
+	return deps
+}
+
+// ReconcileConflict records a Key reported with more than one distinct
+// non-empty version across sources, and which version ReconcileDependencies
+// picked as the winner.
+type ReconcileConflict struct {
+	Key      string   `json:"key"`
+	Versions []string `json:"versions"` // every distinct version seen, source order
+	Winner   string   `json:"winner"`
+}
+
+// ReconcileReport describes how ReconcileDependencies resolved a batch:
+// which origin won each Key, and any version conflicts it had to drop.
+type ReconcileReport struct {
+	Winners   map[string]string   `json:"winners"` // Key -> winning origin
+	Conflicts []ReconcileConflict `json:"conflicts,omitempty"`
+}
+
+type reconcileJob struct {
+	key  string
+	deps []Dependency
+}
+
+type reconcileResult struct {
+	dep      Dependency
+	conflict *ReconcileConflict
+}
+
+// ReconcileDependencies groups deps by Key (falling back to ArtifactID),
+// picks a winning version per group by origin priority (see
+// originPriority/TagOrigin), and resolves groups concurrently through a
+// worker pool sized by Config.ReconcileWorkers (see SetReconcileWorkers) —
+// jobs submitted on a channel, winners collected from a results channel,
+// the first hard error reported on an errors channel. A winner's merged
+// Requires are re-queued as stub groups of their own (if not already part
+// of the batch) so transitively discovered dependencies still make it into
+// the final, deduped list; the workqueue drains once nothing new surfaces.
+func ReconcileDependencies(deps []Dependency) ([]Dependency, ReconcileReport, error) {
+	workers := currentConfig.ReconcileWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	groups := make(map[string][]Dependency)
+	var order []string
+	for _, d := range deps {
+		key := reconcileKey(d)
+		if key == "" {
+			continue
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], d)
+	}
+
+	jobs := make(chan reconcileJob, len(order)+workers)
+	results := make(chan reconcileResult)
+	errs := make(chan error, 1)
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]bool)
+		wg   sync.WaitGroup // outstanding (submitted but not yet processed) jobs
+	)
+
+	for _, key := range order {
+		seen[key] = true
+		wg.Add(1)
+		jobs <- reconcileJob{key: key, deps: groups[key]}
+	}
+
+	var workerWG sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		workerWG.Add(1)
+		go func() {
+			defer workerWG.Done()
+			for job := range jobs {
+				dep, conflict, err := resolveGroup(job.key, job.deps)
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					wg.Done()
+					continue
+				}
+				results <- reconcileResult{dep: dep, conflict: conflict}
+
+				for _, req := range dep.Requires {
+					if req == "" {
+						continue
+					}
+					mu.Lock()
+					already := seen[req]
+					if !already {
+						seen[req] = true
+					}
+					mu.Unlock()
+					if already {
+						continue
+					}
+					wg.Add(1)
+					go func(name string) {
+						jobs <- reconcileJob{key: name, deps: []Dependency{{Key: name, ArtifactID: name, Origin: "transitive"}}}
+					}(req)
+				}
+				wg.Done()
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(jobs)
+	}()
+	go func() {
+		workerWG.Wait()
+		close(results)
+	}()
+
+	report := ReconcileReport{Winners: make(map[string]string)}
+	var finalDeps []Dependency
+	for res := range results {
+		report.Winners[reconcileKey(res.dep)] = res.dep.Origin
+		if res.conflict != nil {
+			report.Conflicts = append(report.Conflicts, *res.conflict)
+		}
+		finalDeps = append(finalDeps, res.dep)
+	}
+
+	select {
+	case err := <-errs:
+		return nil, ReconcileReport{}, err
+	default:
+	}
+
+	return finalDeps, report, nil
+}
+
+// resolveGroup picks the winning entry for one Key's group of same-
+// dependency entries by origin priority, falling back to any group-mate's
+// non-empty version if the winning origin didn't record one, and unions
+// Requires across the whole group so transitive discovery isn't limited to
+// whichever entry happened to win.
+func resolveGroup(key string, group []Dependency) (Dependency, *ReconcileConflict, error) {
+	if len(group) == 0 {
+		return Dependency{}, nil, fmt.Errorf("reconcile: empty group for key %q", key)
+	}
+
+	sort.SliceStable(group, func(i, j int) bool {
+		return originPriority[group[i].Origin] > originPriority[group[j].Origin]
+	})
+	winner := group[0]
+
+	seenVersions := make(map[string]bool)
+	var versions []string
+	for _, d := range group {
+		if d.Version != "" && !seenVersions[d.Version] {
+			seenVersions[d.Version] = true
+			versions = append(versions, d.Version)
+		}
+	}
+	if winner.Version == "" {
+		for _, d := range group[1:] {
+			if d.Version != "" {
+				winner.Version = d.Version
+				break
+			}
+		}
+	}
+
+	seenReqs := make(map[string]bool)
+	var requires []string
+	for _, d := range group {
+		for _, r := range d.Requires {
+			if r != "" && !seenReqs[r] {
+				seenReqs[r] = true
+				requires = append(requires, r)
+			}
+		}
+	}
+	winner.Requires = requires
+
+	var conflict *ReconcileConflict
+	if len(versions) > 1 {
+		conflict = &ReconcileConflict{Key: key, Versions: versions, Winner: winner.Version}
+	}
+
+	return winner, conflict, nil
+}
+
+// reconcileKey returns the grouping key for a dependency: its Key, or
+// ArtifactID if Key is blank.
+func reconcileKey(d Dependency) string {
+	if d.Key != "" {
+		return d.Key
+	}
+	return d.ArtifactID
 }
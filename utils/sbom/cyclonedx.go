@@ -0,0 +1,103 @@
+package sbom
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+
+	"ort-recovery/utils"
+)
+
+// CycloneDXSource reads a CycloneDX 1.4/1.5 SBOM, either JSON ("bom.json")
+// or XML ("bom.xml"), from a project's root.
+type CycloneDXSource struct{}
+
+func (s *CycloneDXSource) Name() string         { return "CycloneDX" }
+func (s *CycloneDXSource) Ecosystems() []string { return nil }
+
+type cyclonedxComponent struct {
+	Type    string `json:"type" xml:"type,attr"`
+	Group   string `json:"group" xml:"group"`
+	Name    string `json:"name" xml:"name"`
+	Version string `json:"version" xml:"version"`
+	PURL    string `json:"purl" xml:"purl"`
+	Scope   string `json:"scope" xml:"scope"`
+}
+
+type cyclonedxJSON struct {
+	BomFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Components  []cyclonedxComponent `json:"components"`
+}
+
+type cyclonedxXML struct {
+	XMLName    xml.Name `xml:"bom"`
+	Components struct {
+		Component []cyclonedxComponent `xml:"component"`
+	} `xml:"components"`
+}
+
+func (s *CycloneDXSource) Load(projectDir string) ([]utils.Dependency, error) {
+	if path := joinPath(projectDir, "bom.json"); fileExists(path) {
+		var doc cyclonedxJSON
+		if err := readJSONFile(path, &doc); err != nil {
+			return nil, fmt.Errorf("invalid CycloneDX JSON: %v", err)
+		}
+		return cyclonedxComponentsToDeps(doc.Components), nil
+	}
+	if path := joinPath(projectDir, "bom.xml"); fileExists(path) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var doc cyclonedxXML
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid CycloneDX XML: %v", err)
+		}
+		return cyclonedxComponentsToDeps(doc.Components.Component), nil
+	}
+	return nil, nil
+}
+
+func cyclonedxComponentsToDeps(components []cyclonedxComponent) []utils.Dependency {
+	var deps []utils.Dependency
+	for _, c := range components {
+		if c.Type != "" && c.Type != "library" && c.Type != "framework" && c.Type != "application" {
+			continue
+		}
+		groupID, artifactID := groupAndArtifactFromPURL(c.PURL, c.Group, c.Name)
+		scope := c.Scope
+		if scope == "" {
+			scope = "compile"
+		}
+		deps = append(deps, utils.Dependency{
+			GroupID:    groupID,
+			ArtifactID: artifactID,
+			Version:    c.Version,
+			Scope:      scope,
+			Key:        fmt.Sprintf("%s:%s", groupID, artifactID),
+		})
+	}
+	return deps
+}
+
+// groupAndArtifactFromPURL extracts a Maven-style group/artifact split from
+// a Package URL (e.g. "pkg:maven/org.apache.commons/commons-lang3@3.12.0"),
+// falling back to the component's own group/name fields for ecosystems
+// that don't have a group segment (npm, pypi, ...).
+func groupAndArtifactFromPURL(purl, fallbackGroup, fallbackName string) (string, string) {
+	if strings.HasPrefix(purl, "pkg:maven/") {
+		rest := strings.TrimPrefix(purl, "pkg:maven/")
+		rest = strings.SplitN(rest, "@", 2)[0]
+		parts := strings.SplitN(rest, "/", 2)
+		if len(parts) == 2 {
+			return parts[0], parts[1]
+		}
+	}
+	group := fallbackGroup
+	if group == "" {
+		group = "unknown.group"
+	}
+	return group, fallbackName
+}
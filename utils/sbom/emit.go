@@ -0,0 +1,36 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"ort-recovery/utils"
+)
+
+// Emit writes deps to w as an SBOM, in either CycloneDX 1.5 or SPDX 2.3
+// JSON depending on format ("cyclonedx" or "spdx"; cyclonedx is the
+// default when format is empty). This is what Handler.EmitSBOM delegates
+// to for every handler that has no ecosystem-specific enrichment to add
+// beyond what's already on the Dependency values themselves (PURL,
+// Checksum, Requires); see GoHandler.EmitSBOM for an example of a handler
+// that populates those first.
+func Emit(deps []utils.Dependency, w io.Writer, format string) error {
+	return emit(deps, w, format, "")
+}
+
+func emit(deps []utils.Dependency, w io.Writer, format, sourceTreeHash string) error {
+	var doc interface{}
+	switch format {
+	case "", "cyclonedx":
+		doc = buildCycloneDXDocument(deps, sourceTreeHash)
+	case "spdx":
+		doc = buildSPDXDocument(deps, sourceTreeHash)
+	default:
+		return fmt.Errorf("sbom: unknown format %q", format)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,73 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"ort-recovery/utils"
+)
+
+// ExportToFile writes deps to outPath as an SBOM in the given format
+// ("cyclonedx" or "spdx"), creating outPath's parent directory if needed.
+// This is what every handler's ExportSBOM delegates to.
+//
+// The document is stamped with a SHA-256 fingerprint of projectDir --
+// the scanned project's own source tree, not outPath's backup folder --
+// as a "source tree" digest.
+func ExportToFile(deps []utils.Dependency, format, outPath, projectDir string) error {
+	dir := filepath.Dir(outPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create SBOM output directory %s: %v", dir, err)
+	}
+
+	treeHash, err := hashTree(projectDir)
+	if err != nil {
+		utils.AppendLog(projectDir, "[sbom] WARNING: failed to fingerprint %s for SBOM metadata: %v", projectDir, err)
+	}
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("failed to create SBOM file %s: %v", outPath, err)
+	}
+	defer f.Close()
+
+	return emit(deps, f, format, treeHash)
+}
+
+// hashTree computes a deterministic SHA-256 over every regular file under
+// dir, in sorted relative-path order, hashing both the path and the
+// content so a rename is distinguishable from a content-only edit.
+func hashTree(dir string) (string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, p := range paths {
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			rel = p
+		}
+		fmt.Fprintf(h, "%s\n", rel)
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return "", err
+		}
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,88 @@
+// Package sbom lets handlers pull dependency data from whatever SBOM a
+// project already ships, instead of hardcoding a read of "syft.json".
+// Sources are auto-detected by filename (and, where formats overlap,
+// content sniffing) and consulted in priority order: a real, user-produced
+// SBOM (CycloneDX or SPDX) is preferred over ad hoc Syft output, since a
+// project that already runs its own SBOM tooling shouldn't have to have
+// Syft re-run over it.
+package sbom
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"ort-recovery/utils"
+)
+
+// Source is one SBOM format ort-recovery knows how to read.
+type Source interface {
+	// Name identifies the source for logging, e.g. "CycloneDX".
+	Name() string
+	// Ecosystems lists the package ecosystems this source can describe
+	// (informational; sources don't filter on it themselves).
+	Ecosystems() []string
+	// Load reads and parses this source's file(s) from projectDir. It
+	// returns (nil, nil) when the source's file isn't present, and a
+	// non-nil error only when the file exists but fails to parse.
+	Load(projectDir string) ([]utils.Dependency, error)
+}
+
+// DefaultSources returns the built-in sources in priority order: CycloneDX
+// and SPDX (real SBOMs a project already produces) ahead of Syft (our own
+// fallback scan).
+func DefaultSources() []Source {
+	return []Source{
+		&CycloneDXSource{},
+		&SPDXSource{},
+		&SyftSource{},
+	}
+}
+
+// Load runs every default source against projectDir in priority order and
+// merges what each finds via utils.ReconcileDependencies. Sources whose
+// file isn't present are skipped silently; a source whose file exists but
+// fails to parse logs a warning and is skipped rather than failing the
+// whole load.
+func Load(projectDir string) ([]utils.Dependency, error) {
+	var all []utils.Dependency
+	for _, src := range DefaultSources() {
+		deps, err := src.Load(projectDir)
+		if err != nil {
+			utils.AppendLog(projectDir, "[sbom] WARNING: %s source failed to parse: %v", src.Name(), err)
+			continue
+		}
+		if len(deps) == 0 {
+			continue
+		}
+		utils.AppendLog(projectDir, "[sbom] Loaded %d dependencies from %s", len(deps), src.Name())
+		all = append(all, deps...)
+	}
+	finalDeps, report, err := utils.ReconcileDependencies(all)
+	if err != nil {
+		return nil, err
+	}
+	if len(report.Conflicts) > 0 {
+		utils.AppendLog(projectDir, "[sbom] Reconcile resolved %d version conflicts", len(report.Conflicts))
+	}
+	return finalDeps, nil
+}
+
+// fileExists reports whether path exists and is a regular file.
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// readJSONFile is a small helper shared by the JSON-based sources.
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func joinPath(projectDir, name string) string {
+	return filepath.Join(projectDir, name)
+}
@@ -0,0 +1,139 @@
+package sbom
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"ort-recovery/utils"
+)
+
+// SPDXSource reads an SPDX 2.3 SBOM, either JSON ("sbom.spdx.json") or the
+// tag-value format (".spdx"), from a project's root.
+type SPDXSource struct{}
+
+func (s *SPDXSource) Name() string         { return "SPDX" }
+func (s *SPDXSource) Ecosystems() []string { return nil }
+
+type spdxPackageJSON struct {
+	Name             string `json:"name"`
+	VersionInfo      string `json:"versionInfo"`
+	ExternalRefs     []struct {
+		ReferenceCategory string `json:"referenceCategory"`
+		ReferenceType     string `json:"referenceType"`
+		ReferenceLocator  string `json:"referenceLocator"`
+	} `json:"externalRefs"`
+}
+
+type spdxDocumentJSON struct {
+	Packages []spdxPackageJSON `json:"packages"`
+}
+
+func (s *SPDXSource) Load(projectDir string) ([]utils.Dependency, error) {
+	if path := joinPath(projectDir, "sbom.spdx.json"); fileExists(path) {
+		var doc spdxDocumentJSON
+		if err := readJSONFile(path, &doc); err != nil {
+			return nil, fmt.Errorf("invalid SPDX JSON: %v", err)
+		}
+		var deps []utils.Dependency
+		for _, p := range doc.Packages {
+			purl := ""
+			for _, ref := range p.ExternalRefs {
+				if ref.ReferenceType == "purl" {
+					purl = ref.ReferenceLocator
+					break
+				}
+			}
+			groupID, artifactID := groupAndArtifactFromPURL(purl, "", p.Name)
+			deps = append(deps, utils.Dependency{
+				GroupID:    groupID,
+				ArtifactID: artifactID,
+				Version:    p.VersionInfo,
+				Scope:      "compile",
+				Key:        fmt.Sprintf("%s:%s", groupID, artifactID),
+			})
+		}
+		return deps, nil
+	}
+
+	if path := findSpdxTagValueFile(projectDir); path != "" {
+		return parseSpdxTagValue(path)
+	}
+
+	return nil, nil
+}
+
+// findSpdxTagValueFile looks for the conventional ".spdx" tag-value file
+// name at the project root.
+func findSpdxTagValueFile(projectDir string) string {
+	path := joinPath(projectDir, "sbom.spdx")
+	if fileExists(path) {
+		return path
+	}
+	return ""
+}
+
+// parseSpdxTagValue reads the line-oriented "Tag: Value" SPDX format,
+// starting a new package on each "PackageName:" tag.
+func parseSpdxTagValue(path string) ([]utils.Dependency, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var deps []utils.Dependency
+	var current *utils.Dependency
+
+	flush := func() {
+		if current != nil && current.ArtifactID != "" {
+			deps = append(deps, *current)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tag, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		tag = strings.TrimSpace(tag)
+		value = strings.TrimSpace(value)
+
+		switch tag {
+		case "PackageName":
+			flush()
+			current = &utils.Dependency{GroupID: "unknown.group", ArtifactID: value, Scope: "compile"}
+		case "PackageVersion":
+			if current != nil {
+				current.Version = value
+			}
+		case "ExternalRef":
+			if current != nil && strings.Contains(value, "purl") {
+				fields := strings.Fields(value)
+				if len(fields) > 0 {
+					purl := fields[len(fields)-1]
+					groupID, artifactID := groupAndArtifactFromPURL(purl, current.GroupID, current.ArtifactID)
+					current.GroupID = groupID
+					current.ArtifactID = artifactID
+				}
+			}
+		}
+	}
+	flush()
+
+	for i := range deps {
+		deps[i].Key = fmt.Sprintf("%s:%s", deps[i].GroupID, deps[i].ArtifactID)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
@@ -0,0 +1,150 @@
+package sbom
+
+import (
+	"regexp"
+	"strings"
+
+	"ort-recovery/utils"
+)
+
+// spdxOutputDocument is the subset of the SPDX 2.3 JSON schema this tool
+// emits. Field names/casing follow the spec's camelCase JSON serialization,
+// not Go convention.
+type spdxOutputDocument struct {
+	SPDXVersion       string                   `json:"spdxVersion"`
+	DataLicense       string                   `json:"dataLicense"`
+	SPDXID            string                   `json:"SPDXID"`
+	Name              string                   `json:"name"`
+	DocumentNamespace string                   `json:"documentNamespace"`
+	Comment           string                   `json:"comment,omitempty"`
+	Packages          []spdxOutputPackage      `json:"packages"`
+	Relationships     []spdxOutputRelationship `json:"relationships,omitempty"`
+}
+
+type spdxOutputPackage struct {
+	SPDXID           string                  `json:"SPDXID"`
+	Name             string                  `json:"name"`
+	VersionInfo      string                  `json:"versionInfo,omitempty"`
+	DownloadLocation string                  `json:"downloadLocation"`
+	ExternalRefs     []spdxOutputExternalRef `json:"externalRefs,omitempty"`
+	Checksums        []spdxOutputChecksum    `json:"checksums,omitempty"`
+}
+
+type spdxOutputExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxOutputChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+// spdxOutputRelationship is one entry of the document's "relationships"
+// array, e.g. "SPDXRef-Package-foo DEPENDS_ON SPDXRef-Package-bar".
+type spdxOutputRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdxIDSafe strips everything but letters, digits, "." and "-" from s, the
+// character set SPDXID allows after its "SPDXRef-" prefix.
+var spdxIDSafe = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// spdxPackageID deterministically derives a package's SPDXID from its name
+// and version, so the same dependency gets the same ID across runs.
+func spdxPackageID(name, version string) string {
+	id := spdxIDSafe.ReplaceAllString(name+"-"+version, "-")
+	id = strings.Trim(id, "-")
+	if id == "" {
+		id = "unknown"
+	}
+	return "SPDXRef-Package-" + id
+}
+
+// buildSPDXDocument turns deps into an SPDX 2.3 document. Relationships are
+// derived from Dependency.Requires the same way buildCycloneDXDocument
+// derives CycloneDX "dependencies" edges. sourceTreeHash, when non-empty,
+// is recorded in the document's "comment" field (see ExportSBOM), since
+// SPDX 2.3 has no dedicated document-level-hash property.
+func buildSPDXDocument(deps []utils.Dependency, sourceTreeHash string) spdxOutputDocument {
+	doc := spdxOutputDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              "ort-recovery-sbom",
+		DocumentNamespace: "https://ort-recovery.invalid/spdxdocs/ort-recovery-sbom",
+	}
+	if sourceTreeHash != "" {
+		doc.Comment = sourceTreeHashProperty + ": " + sourceTreeHash
+	}
+
+	idByArtifact := make(map[string]string, len(deps))
+	for _, d := range deps {
+		name := d.ArtifactID
+		if name == "" {
+			name = d.Name
+		}
+		if name == "" {
+			continue
+		}
+		id := d.SPDXID
+		if id == "" {
+			id = spdxPackageID(name, d.Version)
+		}
+		idByArtifact[d.ArtifactID] = id
+	}
+
+	for _, d := range deps {
+		name := d.ArtifactID
+		if name == "" {
+			name = d.Name
+		}
+		if name == "" {
+			continue
+		}
+		id := d.SPDXID
+		if id == "" {
+			id = spdxPackageID(name, d.Version)
+		}
+		pkg := spdxOutputPackage{
+			SPDXID:           id,
+			Name:             name,
+			VersionInfo:      d.Version,
+			DownloadLocation: "NOASSERTION",
+			ExternalRefs: []spdxOutputExternalRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  purlForDependency(d, name),
+			}},
+			Checksums: spdxChecksumsFor(d),
+		}
+		doc.Packages = append(doc.Packages, pkg)
+
+		for _, req := range d.Requires {
+			reqID, ok := idByArtifact[req]
+			if !ok {
+				continue
+			}
+			doc.Relationships = append(doc.Relationships, spdxOutputRelationship{
+				SPDXElementID:      id,
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSPDXElement: reqID,
+			})
+		}
+	}
+
+	return doc
+}
+
+// spdxChecksumsFor maps Dependency.Checksum to an SPDX checksum entry,
+// under the same "h1: hashes with SHA-256" reasoning as cyclonedxHashesFor.
+func spdxChecksumsFor(d utils.Dependency) []spdxOutputChecksum {
+	if d.Checksum == "" {
+		return nil
+	}
+	content := strings.TrimPrefix(d.Checksum, "h1:")
+	return []spdxOutputChecksum{{Algorithm: "SHA256", ChecksumValue: content}}
+}
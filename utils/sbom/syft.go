@@ -0,0 +1,26 @@
+package sbom
+
+import (
+	"os"
+
+	"ort-recovery/utils"
+)
+
+// SyftSource reads the "syft.json" this tool generates itself by shelling
+// out to Syft. It's the fallback of last resort, kept for backward
+// compatibility with handlers that don't have a real SBOM to read.
+type SyftSource struct{}
+
+func (s *SyftSource) Name() string          { return "Syft" }
+func (s *SyftSource) Ecosystems() []string  { return nil } // format covers all ecosystems
+func (s *SyftSource) Load(projectDir string) ([]utils.Dependency, error) {
+	path := joinPath(projectDir, "syft.json")
+	if !fileExists(path) {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return utils.ParseSyftJSON(data, "")
+}
@@ -0,0 +1,200 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"ort-recovery/utils"
+)
+
+// cyclonedxOutputComponent is the subset of the CycloneDX 1.5 component
+// schema this tool emits when writing a recovered manifest back out as an
+// SBOM for downstream ORT/OSS-Review-Toolkit consumption.
+type cyclonedxOutputComponent struct {
+	BomRef  string                `json:"bom-ref,omitempty"`
+	Type    string                `json:"type"`
+	Group   string                `json:"group,omitempty"`
+	Name    string                `json:"name"`
+	Version string                `json:"version,omitempty"`
+	PURL    string                `json:"purl,omitempty"`
+	Scope   string                `json:"scope,omitempty"`
+	Hashes  []cyclonedxOutputHash `json:"hashes,omitempty"`
+}
+
+// cyclonedxOutputHash is one entry of a component's "hashes" array, e.g.
+// the module content hash ("h1:...") Go records in go.sum.
+type cyclonedxOutputHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+// cyclonedxOutputDependency is one entry of the document's top-level
+// "dependencies" array: ref depends on every module path in DependsOn.
+type cyclonedxOutputDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+type cyclonedxOutputDocument struct {
+	BomFormat    string                      `json:"bomFormat"`
+	SpecVersion  string                      `json:"specVersion"`
+	Version      int                         `json:"version"`
+	Metadata     *cyclonedxOutputMetadata    `json:"metadata,omitempty"`
+	Components   []cyclonedxOutputComponent  `json:"components"`
+	Dependencies []cyclonedxOutputDependency `json:"dependencies,omitempty"`
+}
+
+// cyclonedxOutputMetadata carries document-level properties, currently just
+// the source-tree fingerprint ExportSBOM computes (see sourceTreeProperty).
+type cyclonedxOutputMetadata struct {
+	Properties []cyclonedxOutputProperty `json:"properties,omitempty"`
+}
+
+type cyclonedxOutputProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// sourceTreeHashProperty is the property name ExportSBOM's source-tree
+// SHA-256 is recorded under, in both CycloneDX metadata.properties and (as
+// a "name: value" line) the SPDX document comment.
+const sourceTreeHashProperty = "ort-recovery:sourceTreeSha256"
+
+// buildCycloneDXDocument turns deps into a CycloneDX 1.5 document. The
+// bom-ref for each component is its PURL, which also doubles as the stable
+// identifier Dependencies edges (derived from Dependency.Requires) refer
+// to; a Requires entry that isn't itself a component in deps is dropped
+// rather than left dangling. sourceTreeHash, when non-empty, is recorded as
+// a document-level metadata property (see ExportSBOM).
+func buildCycloneDXDocument(deps []utils.Dependency, sourceTreeHash string) cyclonedxOutputDocument {
+	doc := cyclonedxOutputDocument{
+		BomFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+	}
+	if sourceTreeHash != "" {
+		doc.Metadata = &cyclonedxOutputMetadata{
+			Properties: []cyclonedxOutputProperty{{Name: sourceTreeHashProperty, Value: sourceTreeHash}},
+		}
+	}
+
+	refByArtifact := make(map[string]string, len(deps))
+	for _, d := range deps {
+		name := d.ArtifactID
+		if name == "" {
+			name = d.Name
+		}
+		if name == "" {
+			continue
+		}
+		refByArtifact[d.ArtifactID] = purlForDependency(d, name)
+	}
+
+	for _, d := range deps {
+		name := d.ArtifactID
+		if name == "" {
+			name = d.Name
+		}
+		if name == "" {
+			continue
+		}
+		ref := purlForDependency(d, name)
+		doc.Components = append(doc.Components, cyclonedxOutputComponent{
+			BomRef:  ref,
+			Type:    "library",
+			Group:   nonUnknownGroup(d.GroupID),
+			Name:    name,
+			Version: d.Version,
+			PURL:    ref,
+			Scope:   d.Scope,
+			Hashes:  cyclonedxHashesFor(d),
+		})
+
+		if len(d.Requires) == 0 {
+			continue
+		}
+		var dependsOn []string
+		for _, req := range d.Requires {
+			if reqRef, ok := refByArtifact[req]; ok {
+				dependsOn = append(dependsOn, reqRef)
+			}
+		}
+		if len(dependsOn) > 0 {
+			doc.Dependencies = append(doc.Dependencies, cyclonedxOutputDependency{Ref: ref, DependsOn: dependsOn})
+		}
+	}
+
+	return doc
+}
+
+// cyclonedxHashesFor maps Dependency.Checksum to a CycloneDX hash entry. Go's
+// go.sum "h1:" hashes aren't one of CycloneDX's named algorithms, but
+// recording them as SHA-256 (the algorithm h1 itself hashes with) still
+// lets a consumer compare two recovered SBOMs for the same module.
+func cyclonedxHashesFor(d utils.Dependency) []cyclonedxOutputHash {
+	if d.Checksum == "" {
+		return nil
+	}
+	if content := strings.TrimPrefix(d.Checksum, "h1:"); content != d.Checksum {
+		return []cyclonedxOutputHash{{Alg: "SHA-256", Content: content}}
+	}
+	return []cyclonedxOutputHash{{Alg: "SHA-256", Content: d.Checksum}}
+}
+
+// WriteCycloneDX writes deps out as a minimal CycloneDX 1.5 JSON SBOM,
+// suitable for tools that consume CycloneDX rather than reading a
+// language-specific manifest directly.
+func WriteCycloneDX(deps []utils.Dependency, path string) error {
+	doc := buildCycloneDXDocument(deps, "")
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal CycloneDX SBOM: %v", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func nonUnknownGroup(group string) string {
+	if group == "unknown.group" {
+		return ""
+	}
+	return group
+}
+
+// purlTypeByGroupID maps a handler's GroupID convention to the PackageURL
+// "type" component for ecosystems that aren't Maven-style group:artifact
+// coordinates. Handlers not listed here (Java, and C/C++'s many build
+// systems) fall back to purlForDependency's generic/Maven handling below.
+var purlTypeByGroupID = map[string]string{
+	"crates":    "cargo",
+	"rubygems":  "gem",
+	"npm":       "npm",
+	"pypi":      "pypi",
+	"packagist": "composer",
+	"nuget":     "nuget",
+	"cocoapods": "cocoapods",
+	"swift":     "swift",
+	"swiftpm":   "swift",
+	"conan":     "conan",
+}
+
+// purlForDependency returns a recovered dependency's Package URL, preferring
+// whatever the handler already computed on d.PURL (e.g. CppHandler's
+// registry-aware cpp.BuildCppPURL) over guessing one from GroupID/Language.
+func purlForDependency(d utils.Dependency, name string) string {
+	if d.PURL != "" {
+		return d.PURL
+	}
+	switch {
+	case d.Language == "go":
+		return fmt.Sprintf("pkg:golang/%s@%s", name, d.Version)
+	case purlTypeByGroupID[d.GroupID] != "":
+		return fmt.Sprintf("pkg:%s/%s@%s", purlTypeByGroupID[d.GroupID], name, d.Version)
+	case nonUnknownGroup(d.GroupID) != "":
+		return fmt.Sprintf("pkg:maven/%s/%s@%s", d.GroupID, name, d.Version)
+	default:
+		return fmt.Sprintf("pkg:generic/%s@%s", name, d.Version)
+	}
+}
@@ -0,0 +1,49 @@
+// Structured per-project scan logging. AppendLog (see logger.go) writes
+// free-form strings to recovery.log, which is fine for a human tailing a
+// run but can't be machine-consumed to answer "which packages did the
+// Ruby handler touch in the curation phase?". NewScanLogger gives
+// handlers a slog.Logger that writes one JSON object per line to
+// <projectDir>/scan.log.jsonl instead, so a scan report can be derived
+// from it mechanically.
+package utils
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// ScanLogFileName is the per-project structured log written alongside
+// recovery.log; unlike recovery.log it's JSON-lines, one event per call.
+const ScanLogFileName = "scan.log.jsonl"
+
+// Recommended context keys for NewScanLogger's .With()/attrs, shared
+// across handlers so scan.log.jsonl stays machine-consumable regardless
+// of which handler wrote a given line.
+const (
+	LogKeyHandler = "handler"
+	LogKeyFile    = "file"
+	LogKeyPhase   = "phase"
+	LogKeyPkg     = "pkg"
+	LogKeyVersion = "version"
+	LogKeySource  = "source"
+)
+
+// NewScanLogger returns a *slog.Logger that appends JSON-encoded records
+// to <projectDir>/scan.log.jsonl, creating the file if needed. Callers
+// typically chain .With(utils.LogKeyHandler, h.Name()) once and pass the
+// result down through Scan so every line is already tagged with its
+// handler.
+//
+// If the file can't be opened (e.g. projectDir doesn't exist yet), the
+// returned logger discards everything rather than failing the scan: scan
+// logging is diagnostic, not load-bearing.
+func NewScanLogger(projectDir string) *slog.Logger {
+	path := filepath.Join(projectDir, ScanLogFileName)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return slog.New(slog.NewJSONHandler(io.Discard, nil))
+	}
+	return slog.New(slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
@@ -0,0 +1,67 @@
+package utils
+
+import "fmt"
+
+// ScanReport aggregates the counters a single Handler.Scan call produces,
+// so main can render a machine- or human-readable summary without
+// re-parsing scan.log.jsonl. Handlers build one alongside their
+// []Dependency return value; zero value is an empty, ready-to-use report.
+type ScanReport struct {
+	PhaseCounts      map[string]int `json:"phaseCounts,omitempty"`
+	EcosystemCounts  map[string]int `json:"ecosystemCounts,omitempty"`
+	Unknowns         int            `json:"unknowns"`
+	CurationsApplied int            `json:"curationsApplied"`
+	OSVHits          int            `json:"osvHits"`
+}
+
+// NewScanReport returns an empty ScanReport with its maps allocated.
+func NewScanReport() ScanReport {
+	return ScanReport{
+		PhaseCounts:     map[string]int{},
+		EcosystemCounts: map[string]int{},
+	}
+}
+
+// RecordPhase adds n to the running count for phase (e.g. "declared",
+// "scanned", "lockfile"), creating the entry if this is its first hit.
+func (r *ScanReport) RecordPhase(phase string, n int) {
+	if r.PhaseCounts == nil {
+		r.PhaseCounts = map[string]int{}
+	}
+	r.PhaseCounts[phase] += n
+}
+
+// RecordEcosystem adds n to the running dependency count for ecosystem
+// (the same string handlers pass to policy.Apply, e.g. "npm", "rubygems").
+func (r *ScanReport) RecordEcosystem(ecosystem string, n int) {
+	if r.EcosystemCounts == nil {
+		r.EcosystemCounts = map[string]int{}
+	}
+	r.EcosystemCounts[ecosystem] += n
+}
+
+// CountOSVHits returns how many deps carry at least one Vulnerabilities
+// entry; handlers call this after EnrichVulnerabilities to fill OSVHits,
+// since EnrichVulnerabilities itself only returns an error.
+func CountOSVHits(deps []Dependency) int {
+	hits := 0
+	for _, d := range deps {
+		if len(d.Vulnerabilities) > 0 {
+			hits++
+		}
+	}
+	return hits
+}
+
+// Render formats the report for the "-langReport"-style human-readable
+// output; JSON consumers should marshal the struct directly instead.
+func (r ScanReport) Render() string {
+	out := fmt.Sprintf("unknowns=%d curationsApplied=%d osvHits=%d", r.Unknowns, r.CurationsApplied, r.OSVHits)
+	for phase, n := range r.PhaseCounts {
+		out += fmt.Sprintf(" phase[%s]=%d", phase, n)
+	}
+	for eco, n := range r.EcosystemCounts {
+		out += fmt.Sprintf(" ecosystem[%s]=%d", eco, n)
+	}
+	return out
+}
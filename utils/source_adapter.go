@@ -0,0 +1,20 @@
+package utils
+
+// SourceAdapter ingests a project's third-party dependency data from some
+// external tool or heuristic, producing the Dependency records handlers
+// reconcile against their own manifest/lockfile parsing. It replaces the
+// single hard-coded "shell out to Syft, read syft.json" pipeline main.go
+// used to own directly, so an alternative tool (or no tool at all, for
+// air-gapped CI) can be swapped in via the "-source" flag; see
+// utils/ingest for the built-in syft/trivy/native implementations.
+type SourceAdapter interface {
+	// Name identifies the adapter for logging and the "-source" flag, e.g.
+	// "syft", "trivy", or "native".
+	Name() string
+	// Ingest scans projectDir and returns whatever dependencies it can
+	// recover. An adapter backed by a missing external binary should
+	// return a non-nil error so callers can fall back to another adapter
+	// instead of failing the whole run; returning (nil, nil) is valid and
+	// means "nothing to add beyond what the handler already parses itself".
+	Ingest(projectDir string) ([]Dependency, error)
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Unknown records something a handler's parser found but couldn't resolve
+// into a Dependency: an unmatched `require`/`use` statement, a lockfile
+// entry missing a version, a line a regex couldn't parse, a syft entry
+// whose ecosystem didn't match, and so on. Handlers collect these across
+// every parsing stage of a Scan and write them out via WriteUnknowns,
+// instead of silently dropping them with `_ = err` as before.
+type Unknown struct {
+	File    string `json:"file"`              // path the unknown was found in, relative to the project root
+	Line    int    `json:"line,omitempty"`    // 1-based line number, 0 if not line-oriented
+	Reason  string `json:"reason"`            // why it couldn't be resolved, e.g. "unmatched require statement"
+	RawText string `json:"rawText,omitempty"` // the offending line or entry, verbatim
+}
+
+// WriteUnknowns writes unknowns as JSON to <projectDir>/unknowns.json,
+// next to the recovery file. It writes an empty array rather than
+// skipping the file when unknowns is empty, so a clean scan is visibly
+// distinguishable from one whose handler never wrote the file at all.
+func WriteUnknowns(projectDir string, unknowns []Unknown) error {
+	if unknowns == nil {
+		unknowns = []Unknown{}
+	}
+	data, err := json.MarshalIndent(unknowns, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(projectDir, "unknowns.json"), data, 0644)
+}
@@ -0,0 +1,106 @@
+// Package vanity resolves a Go import path down to the module root that
+// actually owns it, per the remote import path discovery protocol
+// (https://pkg.go.dev/cmd/go#hdr-Remote_import_paths): well-known code
+// hosts are resolved from path shape alone, and anything else is
+// discovered via a "?go-get=1" request for a <meta name="go-import"> tag.
+package vanity
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// wellKnownHosts are hosts whose module root is always <host>/<owner>/<repo>
+// (plus a "/vN" major-version suffix, for v2+), so resolving them never
+// needs a network round-trip.
+var wellKnownHosts = map[string]struct{}{
+	"github.com":    {},
+	"gitlab.com":    {},
+	"bitbucket.org": {},
+}
+
+// majorVersionSuffix matches a semantic-import-versioning suffix, e.g.
+// the "v2" in "github.com/pelletier/go-toml/v2", which is part of the
+// module path itself rather than a subdirectory.
+var majorVersionSuffix = regexp.MustCompile(`^v[2-9][0-9]*$`)
+
+// metaGoImportRe matches a Go remote-import meta tag, e.g.
+// <meta name="go-import" content="example.com/pkg git https://example.com/pkg.git">
+var metaGoImportRe = regexp.MustCompile(`(?i)<meta\s+name=["']go-import["']\s+content=["']([^"']+)["']\s*/?>`)
+
+// HTTPClient is used for "?go-get=1" discovery requests; overridable so
+// callers can point it at a test server.
+var HTTPClient = http.DefaultClient
+
+// ResolveModuleRoot reduces importPath down to the module root that
+// actually owns it, and the VCS repository root serving it. Well-known
+// hosts (github.com, gitlab.com, bitbucket.org) are resolved from path
+// shape alone; any other host is queried via
+// "https://<importPath>?go-get=1" for a <meta name="go-import"> tag.
+func ResolveModuleRoot(importPath string) (moduleRoot, vcsRoot string, err error) {
+	segments := strings.Split(importPath, "/")
+	if len(segments) == 0 || segments[0] == "" {
+		return "", "", fmt.Errorf("empty import path")
+	}
+
+	if _, ok := wellKnownHosts[segments[0]]; ok {
+		if len(segments) < 3 {
+			return "", "", fmt.Errorf("%s: import path under %s needs at least <owner>/<repo>", importPath, segments[0])
+		}
+		repoEnd := 3
+		moduleEnd := repoEnd
+		if len(segments) > repoEnd && majorVersionSuffix.MatchString(segments[repoEnd]) {
+			moduleEnd = repoEnd + 1
+		}
+		repo := strings.Join(segments[:repoEnd], "/")
+		root := strings.Join(segments[:moduleEnd], "/")
+		return root, "https://" + repo, nil
+	}
+
+	return resolveViaMetaTag(importPath)
+}
+
+// resolveViaMetaTag issues the "?go-get=1" request and picks the longest
+// matching go-import prefix, per the spec's "longest match wins" rule for
+// when a host serves meta tags for more than one path prefix.
+func resolveViaMetaTag(importPath string) (moduleRoot, vcsRoot string, err error) {
+	url := "https://" + importPath + "?go-get=1"
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", "", err
+	}
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("request to %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read response from %s: %v", url, err)
+	}
+
+	var bestPrefix, bestRepo string
+	for _, m := range metaGoImportRe.FindAllStringSubmatch(string(body), -1) {
+		fields := strings.Fields(m[1])
+		if len(fields) != 3 {
+			continue
+		}
+		prefix, repo := fields[0], fields[2]
+		if prefix != importPath && !strings.HasPrefix(importPath, prefix+"/") {
+			continue
+		}
+		if len(prefix) > len(bestPrefix) {
+			bestPrefix, bestRepo = prefix, repo
+		}
+	}
+	if bestPrefix == "" {
+		return "", "", fmt.Errorf("no go-import meta tag for %s found at %s", importPath, url)
+	}
+	return bestPrefix, bestRepo, nil
+}